@@ -8,8 +8,10 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/nullswan/nomi/internal/config"
 	"github.com/nullswan/nomi/internal/setup"
+	"github.com/nullswan/nomi/internal/term"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +29,13 @@ var configCmd = &cobra.Command{
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current configuration",
+	Long:  `Show the current configuration, the user one merged with the one of the project.`,
 	Run: func(_ *cobra.Command, _ []string) {
+		if configShowSources {
+			showConfigSources()
+			return
+		}
+
 		data, err := yaml.Marshal(cfg)
 		if err != nil {
 			log.Fatalf("Error marshalling config: %v", err)
@@ -37,6 +45,53 @@ var configShowCmd = &cobra.Command{
 	},
 }
 
+var configShowSources bool
+
+// showConfigSources prints each setting with the file which set it, and
+// the prompt directories.
+func showConfigSources() {
+	sources, err := config.GetConfigSources()
+	if err != nil {
+		log.Fatalf("Error loading config sources: %v", err)
+	}
+
+	settings, err := config.GetSettings(cfg, sources)
+	if err != nil {
+		log.Fatalf("Error listing settings: %v", err)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleLight)
+	t.Style().Options.DrawBorder = false
+
+	t.AppendHeader(table.Row{"Key", "Value", "Source"})
+	for _, s := range settings {
+		source := s.Source
+		if source == "" {
+			source = "default"
+		}
+		t.AppendRow(table.Row{s.Key, s.Value, source})
+	}
+	t.Render()
+
+	for _, source := range sources {
+		for _, key := range source.Ignored {
+			fmt.Printf(
+				"\n%s: %s is ignored, it can only be set by the user configuration\n",
+				source.Path,
+				key,
+			)
+		}
+	}
+
+	fmt.Println("\nPrompt directories, by precedence:")
+	if dir := config.GetProjectPromptDirectory(); dir != "" {
+		fmt.Println("  " + dir)
+	}
+	fmt.Println("  " + config.GetPromptDirectory())
+}
+
 // TODO(nullswan): Replace with editor, just like with the prompt edit
 var configEditCmd = &cobra.Command{
 	Use:   "edit",
@@ -49,7 +104,13 @@ var configEditCmd = &cobra.Command{
 		}
 		defer os.Remove(tempFile.Name())
 
-		configYaml, err := yaml.Marshal(cfg)
+		// Edit the configuration of the user, without the project one
+		userCfg, err := config.LoadUserConfig()
+		if err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+
+		configYaml, err := yaml.Marshal(userCfg)
 		if err != nil {
 			log.Fatalf("Error marshalling config to YAML: %v", err)
 		}
@@ -64,29 +125,112 @@ var configEditCmd = &cobra.Command{
 			editor = "vim"
 		}
 
-		process := exec.Command(editor, tempFile.Name())
-		process.Stdin = os.Stdin
-		process.Stdout = os.Stdout
-		process.Stderr = os.Stderr
+		// Edit until the configuration is valid, or the user gives up
+		for {
+			process := exec.Command(editor, tempFile.Name())
+			process.Stdin = os.Stdin
+			process.Stdout = os.Stdout
+			process.Stderr = os.Stderr
+
+			if err := process.Run(); err != nil {
+				log.Fatalf("Error opening editor: %v", err)
+			}
+
+			err := readEditedConfig(tempFile.Name(), userCfg)
+			if err == nil {
+				break
+			}
+
+			fmt.Println(err)
+			if !term.PromptForBool("Edit the configuration again?", true) {
+				fmt.Println("Configuration not saved")
+				return
+			}
+		}
 
-		if err := process.Run(); err != nil {
-			log.Fatalf("Error opening editor: %v", err)
+		if err := config.SaveConfig(userCfg); err != nil {
+			log.Fatalf("Error saving updated configuration: %v", err)
 		}
 
-		updatedData, err := os.ReadFile(tempFile.Name())
+		fmt.Println("Configuration updated successfully")
+	},
+}
+
+// readEditedConfig unmarshals the edited file into the configuration and
+// validates it, unknown keys are errors.
+func readEditedConfig(fp string, cfg *config.Config) error {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return fmt.Errorf("Error reading updated file: %w", err)
+	}
+
+	// The settings removed from the file take their default
+	updated := config.DefaultConfig()
+	if err := yaml.UnmarshalStrict(data, &updated); err != nil {
+		return fmt.Errorf("Error unmarshalling updated YAML: %w", err)
+	}
+
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
+	*cfg = updated
+	return nil
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Get a configuration value",
+	Long: `Get the value of a dotted key, e.g. input.voice.language, with the
+project configuration and the NOMI_* environment variables applied.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		value, err := config.GetValue(cfg, args[0])
+		if err != nil {
+			fmt.Printf("Error getting config value: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Set a configuration value",
+	Long: `Set the value of a dotted key, e.g. input.voice.language, in the user
+configuration. Lists of strings are comma separated, the other lists and
+the maps are YAML.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		key, value := args[0], args[1]
+
+		userCfg, err := config.LoadUserConfig()
 		if err != nil {
-			log.Fatalf("Error reading updated file: %v", err)
+			log.Fatalf("Error loading config: %v", err)
 		}
 
-		if err := yaml.Unmarshal(updatedData, cfg); err != nil {
-			log.Fatalf("Error unmarshalling updated YAML: %v", err)
+		if err := config.SetValue(userCfg, key, value); err != nil {
+			fmt.Printf("Error setting config value: %v\n", err)
+			os.Exit(1)
 		}
 
-		if err := config.SaveConfig(cfg); err != nil {
-			log.Fatalf("Error saving updated configuration: %v", err)
+		if err := userCfg.Validate(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
 
-		fmt.Println("Configuration updated successfully")
+		if err := config.SaveConfig(userCfg); err != nil {
+			log.Fatalf("Error saving configuration: %v", err)
+		}
+
+		fmt.Printf("%s set to %s\n", key, value)
+		if _, ok := os.LookupEnv(config.EnvName(key)); ok {
+			fmt.Printf(
+				"Note: %s overrides it in this environment\n",
+				config.EnvName(key),
+			)
+		}
 	},
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/nullswan/nomi/internal/chat"
 	"github.com/nullswan/nomi/internal/term"
+	"github.com/nullswan/nomi/usecases/interpreter"
 	"github.com/spf13/cobra"
 )
 
@@ -39,7 +40,7 @@ var conversationListCmd = &cobra.Command{
 		t.Style().Options.SeparateColumns = false
 
 		t.AppendHeader(
-			table.Row{"Id", "Created At", "Since", "Messages"},
+			table.Row{"Id", "Created At", "Since", "Messages", "Prompt"},
 		)
 
 		repo, err := chat.NewSQLiteRepository(cfg.Output.Sqlite.Path)
@@ -56,12 +57,18 @@ var conversationListCmd = &cobra.Command{
 		}
 
 		for _, convo := range allConversations {
+			prompt, version := convo.GetPrompt()
+			if version != "" {
+				prompt += "@" + version
+			}
+
 			t.AppendRow(
 				[]interface{}{
 					convo.GetID(),
 					convo.GetCreatedAt().Format(time.RFC3339),
 					time.Since(convo.GetCreatedAt()).Round(time.Second),
 					len(convo.GetMessages()),
+					prompt,
 				},
 			)
 		}
@@ -140,3 +147,56 @@ var conversationShowCmd = &cobra.Command{
 		}
 	},
 }
+
+var (
+	conversationExportFormat string
+	conversationExportOutput string
+)
+
+var conversationExportCmd = &cobra.Command{
+	Use:   "export [id]",
+	Short: "Export a conversation",
+	Long: `Export a conversation by its ID. The ipynb format turns an interpreter
+session into a Jupyter notebook, with the requests as markdown cells and the
+code as code cells with their outputs.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the conversation to export.")
+			return
+		}
+		id := args[0]
+
+		if conversationExportFormat != "ipynb" {
+			fmt.Printf(
+				"Unsupported format %q, expected ipynb.\n",
+				conversationExportFormat,
+			)
+			return
+		}
+
+		repo, err := chat.NewSQLiteRepository(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		convo, err := repo.LoadConversation(id)
+		if err != nil {
+			fmt.Println("Error loading conversation:", err)
+			return
+		}
+
+		output := conversationExportOutput
+		if output == "" {
+			output = id + "." + conversationExportFormat
+		}
+
+		if err := interpreter.Notebook(convo).WriteFile(output); err != nil {
+			fmt.Println("Error exporting conversation:", err)
+			return
+		}
+
+		fmt.Println("Conversation exported to " + output)
+	},
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/logger"
+	"github.com/nullswan/nomi/internal/schedule"
+	"github.com/nullswan/nomi/internal/tools"
+	"github.com/nullswan/nomi/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the scheduled tasks in the foreground",
+	Long: `Run the scheduled tasks in the foreground until interrupted.
+Each run is stored in a new conversation, see 'nomi schedule history'.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			fmt.Println("Sig received, quitting...")
+			cancel()
+		}()
+
+		if err := cli.InitInterpreter(cfg.Interpreter); err != nil {
+			fmt.Printf("Error initializing interpreter: %v\n", err)
+			return
+		}
+
+		logger := logger.Init()
+		toolsLogger := tools.NewLogger(cfg.DevMode)
+
+		repo, err := schedule.NewSQLiteRepository(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		chatRepo, err := cli.InitChatDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating chat repository:", err)
+			return
+		}
+		defer chatRepo.Close()
+
+		textToJSONBackend, err := cli.InitJSONProviders(
+			logger,
+			targetModel,
+		)
+		if err != nil {
+			fmt.Printf("Error initializing providers: %v\n", err)
+			return
+		}
+		defer textToJSONBackend.Close()
+
+		textToTextBackend, err := cli.InitTextProviders(
+			logger,
+			targetModel,
+			false,
+		)
+		if err != nil {
+			fmt.Printf("Error initializing providers: %v\n", err)
+			return
+		}
+		defer textToTextBackend.Close()
+
+		ttjBackend := tools.NewTextToJSONBackend(textToJSONBackend, logger)
+		tttBackend := tools.NewTextToTextBackend(textToTextBackend, logger)
+
+		daemon := &schedule.Daemon{
+			Repo:       repo,
+			ChatRepo:   chatRepo,
+			TextToText: &tttBackend,
+			Engine: &workflow.Engine{
+				TextToText: &tttBackend,
+				TextToJSON: &ttjBackend,
+				Console:    tools.NewBashConsole(),
+				Logger:     toolsLogger,
+			},
+			Logger: toolsLogger,
+		}
+
+		toolsLogger.Info("Daemon started, waiting for scheduled tasks...")
+		if err := daemon.Run(ctx); err != nil {
+			fmt.Printf("Error running daemon: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/environment"
+	"github.com/spf13/cobra"
+)
+
+var envRefresh bool
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Show the environment given to the models",
+	Long: `Show the interpreters, tools and packages found on this machine, as given
+to the interpreter and to 'nomi suggest'. The probe is cached for a day or
+until the PATH changes, use --refresh after installing new tools.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		ctx := context.Background()
+		path := config.GetEnvironmentCachePath()
+
+		if envRefresh {
+			if _, err := environment.Refresh(ctx, path); err != nil {
+				fmt.Println("Error probing environment:", err)
+				return
+			}
+		}
+
+		env, err := environment.Load(ctx, path)
+		if err != nil {
+			fmt.Println("Error probing environment:", err)
+			return
+		}
+
+		fmt.Print(env.Summary())
+	},
+}
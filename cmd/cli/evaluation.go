@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/completion"
+	"github.com/nullswan/nomi/internal/evaluation"
+	"github.com/nullswan/nomi/internal/logger"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+	"github.com/nullswan/nomi/internal/providers"
+	baseprovider "github.com/nullswan/nomi/internal/providers/base"
+	"github.com/nullswan/nomi/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptTestModels []string
+	promptTestGrader string
+	promptTestJSON   bool
+)
+
+var promptTestCmd = &cobra.Command{
+	Use:   "test [id]",
+	Short: "Run the test cases of a prompt",
+	Long: `Run the test cases of a prompt against one or more models, given as
+--model [provider:]model, and print a pass/fail matrix. The rubric
+assertions are graded by the --grader model, the first model by default.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the prompt to test.")
+			return
+		}
+
+		prompt, err := prompts.LoadPrompt(args[0])
+		if err != nil {
+			fmt.Printf("Error fetching prompt: %v\n", err)
+			return
+		}
+
+		if len(prompt.Tests) == 0 {
+			fmt.Printf("Prompt %s has no tests.\n", prompt.ID)
+			return
+		}
+
+		specs := promptTestModels
+		if len(specs) == 0 {
+			provider, model := providers.SelectModel(prompt.Preferences.Models)
+			specs = []string{provider.String() + ":" + model}
+		}
+
+		logger := logger.Init()
+		opts := prompt.Preferences.RequestOptions()
+
+		runner := &evaluation.Runner{}
+		for _, spec := range specs {
+			backend, name, err := initTestBackend(
+				logger,
+				spec,
+				prompt.Preferences.Reasoning,
+			)
+			if err != nil {
+				fmt.Printf("Error initializing providers: %v\n", err)
+				return
+			}
+			defer backend.Close()
+
+			completer := tools.NewTextToTextBackend(backend, logger).
+				WithOptions(opts)
+			runner.Targets = append(runner.Targets, evaluation.Target{
+				Name:      name,
+				Completer: completer,
+			})
+		}
+
+		if hasRubric(prompt) {
+			spec := promptTestGrader
+			if spec == "" {
+				spec = specs[0]
+			}
+
+			backend, _, err := initTestBackend(logger, spec, false)
+			if err != nil {
+				fmt.Printf("Error initializing grader: %v\n", err)
+				return
+			}
+			defer backend.Close()
+
+			temperature := float32(0)
+			grader := tools.NewTextToTextBackend(backend, logger).
+				WithOptions(completion.RequestOptions{
+					Temperature: &temperature,
+					JSON:        true,
+				})
+			runner.Grader = grader
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			fmt.Println("Sig received, quitting...")
+			cancel()
+		}()
+
+		report, err := runner.Run(ctx, prompt)
+		if err != nil {
+			fmt.Printf("Error running tests: %v\n", err)
+			if report == nil {
+				return
+			}
+		}
+
+		if promptTestJSON {
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding report: %v\n", err)
+				return
+			}
+			fmt.Println(string(out))
+		} else {
+			printReport(report)
+		}
+
+		if !report.Passed() {
+			os.Exit(1)
+		}
+	},
+}
+
+// initTestBackend initializes the provider of a [provider:]model spec and
+// returns it with the name of the target.
+func initTestBackend(
+	logger *logger.Logger,
+	spec string,
+	reasoning bool,
+) (baseprovider.TextToTextProvider, string, error) {
+	provider := providers.CheckProvider()
+	model := spec
+
+	// Models can contain colons, e.g. llama3:8b with ollama
+	if name, rest, ok := strings.Cut(spec, ":"); ok {
+		if p, err := providers.ParseProvider(name); err == nil {
+			provider, model = p, rest
+		}
+	}
+
+	backend, err := cli.InitProviderTextProviders(
+		logger,
+		provider,
+		model,
+		reasoning,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if model == "" {
+		model = "default"
+	}
+
+	return backend, provider.String() + ":" + model, nil
+}
+
+func hasRubric(prompt *prompts.Prompt) bool {
+	for _, tc := range prompt.Tests {
+		for _, a := range tc.Assert {
+			if a.Type == prompts.AssertRubric {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printReport prints the results as a matrix of the tests by the targets,
+// followed by the failures.
+func printReport(report *evaluation.Report) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleLight)
+	t.Style().Options.DrawBorder = false
+
+	header := table.Row{"Test"}
+	for _, target := range report.Targets {
+		header = append(header, target)
+	}
+	t.AppendHeader(header)
+
+	for _, test := range report.Tests {
+		row := table.Row{test}
+		for _, target := range report.Targets {
+			result, ok := report.Result(test, target)
+			switch {
+			case !ok:
+				row = append(row, "")
+			case result.Passed:
+				row = append(row, "pass")
+			case result.Error != "":
+				row = append(row, "error")
+			default:
+				row = append(row, "fail")
+			}
+		}
+		t.AppendRow(row)
+	}
+
+	t.Render()
+
+	passed := 0
+	for _, result := range report.Results {
+		if result.Passed {
+			passed++
+			continue
+		}
+
+		fmt.Printf("\n%s on %s:\n", result.Test, result.Target)
+		if result.Error != "" {
+			fmt.Printf("  error: %s\n", result.Error)
+		}
+		for _, failure := range result.Failures {
+			fmt.Printf("  %s\n", failure)
+		}
+	}
+
+	fmt.Printf(
+		"\n%d/%d passed, %s@%s\n",
+		passed,
+		len(report.Results),
+		report.PromptID,
+		report.Version,
+	)
+}
@@ -11,10 +11,10 @@ import (
 	"github.com/nullswan/nomi/internal/audio"
 	"github.com/nullswan/nomi/internal/chat"
 	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/completion"
 	"github.com/nullswan/nomi/internal/config"
 	"github.com/nullswan/nomi/internal/logger"
 	prompts "github.com/nullswan/nomi/internal/prompt"
-	"github.com/nullswan/nomi/internal/providers"
 	baseprovider "github.com/nullswan/nomi/internal/providers/base"
 	"github.com/nullswan/nomi/internal/term"
 
@@ -27,6 +27,18 @@ var (
 	interactiveMode     bool
 	startConversationID string
 	targetModel         string
+	targetProvider      string
+	promptVars          []string
+
+	temperature   float32
+	topP          float32
+	maxTokens     int
+	stopSequences []string
+	seed          int
+	jsonMode      bool
+
+	// requestOptions of the completions of the session
+	requestOptions completion.RequestOptions
 )
 
 var rootCmd = &cobra.Command{
@@ -38,7 +50,7 @@ var rootCmd = &cobra.Command{
 	},
 }
 
-func runApp(_ *cobra.Command, _ []string) {
+func runApp(cmd *cobra.Command, _ []string) {
 	// Setup context and signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -64,10 +76,31 @@ func runApp(_ *cobra.Command, _ []string) {
 		}
 	}
 
+	vars, err := parseVars(promptVars)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	selectedPrompt, err = selectedPrompt.Render(promptRenderOptions(vars))
+	if err != nil {
+		fmt.Printf("Error rendering prompt: %v\n", err)
+		return
+	}
+
+	requestOptions = promptRequestOptions(cmd, selectedPrompt.Preferences)
+
+	provider, model, err := promptModel(selectedPrompt.Preferences)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
 	// Initialize Providers
-	textToTextBackend, err := cli.InitTextProviders(
+	textToTextBackend, err := cli.InitProviderTextProviders(
 		logger,
-		targetModel,
+		provider,
+		model,
 		selectedPrompt.Preferences.Reasoning,
 	)
 	if err != nil {
@@ -105,7 +138,7 @@ func runApp(_ *cobra.Command, _ []string) {
 		cli.WithBuildVersion(buildVersion),
 		cli.WithStartPrompt(startPrompt),
 		cli.WithModelProvider(textToTextBackend),
-		cli.WithProvider(providers.CheckProvider()),
+		cli.WithProvider(provider),
 	)
 
 	// Initialize Renderer
@@ -195,6 +228,7 @@ func processInput(
 		conversation,
 		renderer,
 		textToTextBackend,
+		requestOptions,
 	)
 	if err != nil {
 		if strings.Contains(err.Error(), "context canceled") {
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol integration",
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			fmt.Println("Error displaying help:", err)
+		}
+	},
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose nomi data as an MCP server over stdio",
+	Long: `Start a Model Context Protocol server speaking JSON-RPC over stdio.
+
+Conversations, prompts, code snippets and the knowledge base are exposed
+as tools and resources to the connected agent.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		// Stdout is reserved to the protocol, report errors on stderr
+		chatRepo, err := cli.InitChatDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating chat repository: %v\n", err)
+			os.Exit(1)
+		}
+		defer chatRepo.Close()
+
+		codeRepo, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating code repository: %v\n", err)
+			os.Exit(1)
+		}
+		defer codeRepo.Close()
+
+		server := mcp.NewServer(binaryName, buildVersion)
+		mcp.RegisterNomi(server, mcp.NomiSources{
+			ChatRepo:     chatRepo,
+			CodeRepo:     codeRepo,
+			KnowledgeDir: config.GetKnowledgeDirectory(),
+		})
+
+		if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving MCP: %v\n", err)
+		}
+	},
+}
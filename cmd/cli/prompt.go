@@ -1,17 +1,40 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/user"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"gopkg.in/yaml.v2"
 
+	"github.com/nullswan/nomi/internal/completion"
+	"github.com/nullswan/nomi/internal/diff"
 	prompts "github.com/nullswan/nomi/internal/prompt"
+	"github.com/nullswan/nomi/internal/providers"
+	"github.com/nullswan/nomi/internal/term"
 	"github.com/spf13/cobra"
 )
 
+// promptDiffContext is the number of unchanged lines around the changes.
+const promptDiffContext = 3
+
+var (
+	promptNewName        string
+	promptNewDescription string
+	promptNewAuthor      string
+	promptNewSystem      string
+	promptImportForce    bool
+	promptDeleteYes      bool
+	promptShowResolved   bool
+	promptAddYes         bool
+)
+
 var promptCmd = &cobra.Command{
 	Use:   "prompt",
 	Short: "Manage prompts",
@@ -24,30 +47,53 @@ var promptCmd = &cobra.Command{
 }
 
 var promptAddCmd = &cobra.Command{
-	Use:   "add",
+	Use:   "add [url]",
 	Short: "Add a new prompt",
-	Long:  `Add a new prompt to the system.`,
+	Long: `Add a new prompt from a URL. Pin its content with url#sha256=<hex>, its
+minisign signature is verified at <url>.minisig against the trusted keys
+of the configuration.`,
 	Run: func(_ *cobra.Command, args []string) {
 		if len(args) == 0 {
 			fmt.Println("Please provide the URL of the YAML file.")
 			return
 		}
-		url := args[0]
 
-		prompt, err := prompts.AddPromptFromURL(url)
-		if err != nil {
-			fmt.Printf("Error adding prompt: %v\n", err)
-			return
+		opts := prompts.AddOptions{
+			Trust: prompts.NewTrust(cfg.Prompts),
+			Confirm: func(installed, prompt *prompts.Prompt) bool {
+				if promptAddYes {
+					return true
+				}
+
+				if err := printPromptDiff(installed, prompt); err != nil {
+					fmt.Println(err)
+					return false
+				}
+				return term.PromptForBool(
+					fmt.Sprintf("Replace the installed prompt %s?", prompt.ID),
+					false,
+				)
+			},
 		}
 
-		fmt.Println("Prompt added successfully.")
-		promptYaml, err := yaml.Marshal(prompt)
+		prompt, verification, err := prompts.AddPromptFromURL(
+			context.Background(),
+			args[0],
+			opts,
+		)
 		if err != nil {
-			fmt.Printf("Error marshalling prompt to YAML: %v\n", err)
+			fmt.Printf("Error adding prompt: %v\n", err)
 			return
 		}
 
-		fmt.Println("---\n" + string(promptYaml))
+		if verification.Checksum != "" {
+			fmt.Println("Checksum verified.")
+		}
+		if verification.Signer != "" {
+			fmt.Printf("Signature of %s verified.\n", verification.Signer)
+		}
+		fmt.Println("Prompt added successfully.")
+		printPrompt(prompt)
 	},
 }
 
@@ -105,86 +151,502 @@ var promptEditCmd = &cobra.Command{
 		id := args[0]
 
 		// Fetch the current prompt by ID
-		existingPrompt, err := prompts.LoadPrompt(id)
+		existingPrompt, err := prompts.LoadRawPrompt(id)
 		if err != nil {
 			fmt.Printf("Error fetching prompt: %v\n", err)
 			return
 		}
 
-		// Write the current prompt to a temporary YAML file
-		tempFile, err := os.CreateTemp("/tmp", "*.yaml")
+		updatedPrompt, err := editPrompt(existingPrompt)
 		if err != nil {
-			fmt.Printf("Error creating temporary file: %v\n", err)
+			fmt.Println(err)
+			return
+		}
+
+		// Save the updated prompt
+		if err := updatedPrompt.Save(); err != nil {
+			fmt.Printf("Error saving updated prompt: %v\n", err)
 			return
 		}
-		defer os.Remove(tempFile.Name()) // Clean up the file afterwards
 
-		promptYaml, err := yaml.Marshal(existingPrompt)
+		fmt.Printf(
+			"Prompt edited successfully, version %s.\n",
+			updatedPrompt.Metadata.Version,
+		)
+		printPrompt(updatedPrompt)
+	},
+}
+
+var promptNewCmd = &cobra.Command{
+	Use:   "new [id]",
+	Short: "Create a new prompt",
+	Long:  `Create a new prompt from the flags, or from a scaffold opened in $EDITOR when no system prompt is given.`,
+	Run: func(_ *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		} else {
+			id = term.PromptForString("Prompt ID", "", func(s string) error {
+				if s == "" {
+					return errors.New("the ID is required")
+				}
+				return nil
+			})
+		}
+
+		if _, err := prompts.LoadRawPrompt(id); err == nil {
+			fmt.Printf("Prompt %s already exists, edit it instead.\n", id)
+			return
+		}
+
+		name := promptNewName
+		if name == "" {
+			name = id
+		}
+
+		prompt := prompts.NewPrompt(id, name)
+		prompt.Description = promptNewDescription
+		prompt.Metadata.Author = promptNewAuthor
+		if prompt.Metadata.Author == "" {
+			if u, err := user.Current(); err == nil {
+				prompt.Metadata.Author = u.Username
+			}
+		}
+		prompt.Settings.SystemPrompt = promptNewSystem
+
+		newPrompt := &prompt
+		if promptNewSystem == "" {
+			prompt.Settings.SystemPrompt = "Describe the role and the rules of the assistant."
+
+			var err error
+			newPrompt, err = editPrompt(&prompt)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			if newPrompt.ID != id {
+				if _, err := prompts.LoadRawPrompt(newPrompt.ID); err == nil {
+					fmt.Printf("Prompt %s already exists, edit it instead.\n", newPrompt.ID)
+					return
+				}
+			}
+		} else if err := newPrompt.Validate(); err != nil {
+			fmt.Printf("Validation error: %v\n", err)
+			return
+		}
+
+		if err := newPrompt.Save(); err != nil {
+			fmt.Printf("Error saving prompt: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Prompt %s created, use it with nomi -p %s\n", newPrompt.ID, newPrompt.ID)
+	},
+}
+
+var promptImportCmd = &cobra.Command{
+	Use:   "import [path|-]",
+	Short: "Import a prompt from a YAML file",
+	Long:  `Import a prompt from a local YAML file, or from the standard input with -.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the path of the YAML file, or - for the standard input.")
+			return
+		}
+
+		var (
+			data []byte
+			err  error
+		)
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			fmt.Printf("Error reading prompt: %v\n", err)
+			return
+		}
+
+		prompt, err := prompts.ImportPrompt(data, promptImportForce)
 		if err != nil {
-			fmt.Printf("Error marshalling prompt to YAML: %v\n", err)
+			if errors.Is(err, prompts.ErrPromptExists) {
+				fmt.Printf("%v, use --force to replace it.\n", err)
+				return
+			}
+			fmt.Printf("Error importing prompt: %v\n", err)
+			return
+		}
+
+		fmt.Printf(
+			"Prompt %s %s imported.\n",
+			prompt.ID,
+			prompt.Metadata.Version,
+		)
+	},
+}
+
+var promptCopyCmd = &cobra.Command{
+	Use:   "copy [id] [new-id]",
+	Short: "Copy a prompt under a new ID",
+	Long:  `Copy a prompt under a new ID, as the first version of a new prompt.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Please provide the ID of the prompt to copy and the new ID.")
 			return
 		}
 
-		// Write the YAML to the temp file
-		_, err = tempFile.Write(promptYaml)
+		prompt, err := prompts.CopyPrompt(args[0], args[1])
 		if err != nil {
-			fmt.Printf("Error writing to temp file: %v\n", err)
+			fmt.Printf("Error copying prompt: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Prompt %s copied to %s.\n", args[0], prompt.ID)
+	},
+}
+
+var promptDeleteCmd = &cobra.Command{
+	Use:   "delete [id]",
+	Short: "Delete a prompt",
+	Long:  `Delete a prompt of the user and its history, the prompts of the project are kept.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the prompt to delete.")
+			return
+		}
+		id := args[0]
+
+		if _, err := prompts.LoadRawPrompt(id); err != nil {
+			fmt.Printf("Error fetching prompt: %v\n", err)
+			return
+		}
+
+		if !promptDeleteYes && !term.PromptForBool(
+			fmt.Sprintf("Delete the prompt %s and its history?", id),
+			false,
+		) {
+			fmt.Println("Prompt not deleted.")
+			return
+		}
+
+		if err := prompts.DeletePrompt(id); err != nil {
+			fmt.Printf("Error deleting prompt: %v\n", err)
 			return
 		}
 
-		// Close the file to ensure all data is flushed
-		tempFile.Close()
+		fmt.Printf("Prompt %s deleted.\n", id)
+	},
+}
 
-		editor := os.Getenv("EDITOR")
-		if editor == "" {
-			editor = "vim"
+var promptShowCmd = &cobra.Command{
+	Use:   "show [id] [version]",
+	Short: "Show a prompt",
+	Long:  `Show a prompt, or one of its previous versions, as stored or with its extended prompt and fragments resolved.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the prompt to show.")
+			return
 		}
 
-		process := exec.Command(editor, tempFile.Name())
-		process.Stdin = os.Stdin
-		process.Stdout = os.Stdout
-		process.Stderr = os.Stderr
+		var (
+			prompt *prompts.Prompt
+			err    error
+		)
+		if len(args) > 1 {
+			prompt, err = prompts.LoadPromptVersion(args[0], args[1])
+		} else {
+			prompt, err = prompts.LoadRawPrompt(args[0])
+		}
+		if err != nil {
+			fmt.Printf("Error fetching prompt: %v\n", err)
+			return
+		}
+
+		if promptShowResolved {
+			prompt, err = prompt.Resolve()
+			if err != nil {
+				fmt.Printf("Error resolving prompt: %v\n", err)
+				return
+			}
+		}
+
+		printPrompt(prompt)
+	},
+}
+
+// editPrompt opens the prompt in $EDITOR and returns the validated result.
+func editPrompt(prompt *prompts.Prompt) (*prompts.Prompt, error) {
+	// Write the current prompt to a temporary YAML file
+	tempFile, err := os.CreateTemp("", "*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("Error creating temporary file: %v", err)
+	}
+	defer os.Remove(tempFile.Name()) // Clean up the file afterwards
+
+	promptYaml, err := yaml.Marshal(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling prompt to YAML: %v", err)
+	}
+
+	// Write the YAML to the temp file
+	_, err = tempFile.Write(promptYaml)
+	if err != nil {
+		return nil, fmt.Errorf("Error writing to temp file: %v", err)
+	}
 
-		// Open the temp file in Vim
-		if err := process.Run(); err != nil {
-			fmt.Printf("Error opening Vim: %v\n", err)
+	// Close the file to ensure all data is flushed
+	tempFile.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+
+	process := exec.Command(editor, tempFile.Name())
+	process.Stdin = os.Stdin
+	process.Stdout = os.Stdout
+	process.Stderr = os.Stderr
+
+	// Open the temp file in the editor
+	if err := process.Run(); err != nil {
+		return nil, fmt.Errorf("Error opening %s: %v", editor, err)
+	}
+
+	// Read the updated content
+	updatedData, err := os.ReadFile(tempFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("Error reading updated file: %v", err)
+	}
+
+	// Unmarshal the updated YAML back to the prompt struct
+	var updatedPrompt prompts.Prompt
+	if err := yaml.Unmarshal(updatedData, &updatedPrompt); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling updated YAML: %v", err)
+	}
+
+	// Validate the updated prompt
+	if err := updatedPrompt.Validate(); err != nil {
+		return nil, fmt.Errorf("Validation error: %v", err)
+	}
+
+	return &updatedPrompt, nil
+}
+
+func printPrompt(prompt *prompts.Prompt) {
+	promptYaml, err := yaml.Marshal(prompt)
+	if err != nil {
+		fmt.Printf("Error marshalling prompt to YAML: %v\n", err)
+		return
+	}
+
+	fmt.Println("---\n" + string(promptYaml))
+}
+
+var promptHistoryCmd = &cobra.Command{
+	Use:   "history [id]",
+	Short: "List the versions of a prompt",
+	Long:  `List the archived versions of a prompt, the current one last.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the prompt.")
 			return
 		}
 
-		// Read the updated content
-		updatedData, err := os.ReadFile(tempFile.Name())
+		versions, err := prompts.History(args[0])
 		if err != nil {
-			fmt.Printf("Error reading updated file: %v\n", err)
+			fmt.Printf("Error loading prompt history: %v\n", err)
 			return
 		}
 
-		// Unmarshal the updated YAML back to the prompt struct
-		var updatedPrompt prompts.Prompt
-		if err := yaml.Unmarshal(updatedData, &updatedPrompt); err != nil {
-			fmt.Printf("Error unmarshalling updated YAML: %v\n", err)
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleLight)
+		t.Style().Options.DrawBorder = false
+
+		t.AppendHeader(table.Row{"Version", "Updated At", "Author", ""})
+		for i, prompt := range versions {
+			current := ""
+			if i == len(versions)-1 {
+				current = "current"
+			}
+
+			t.AppendRow(table.Row{
+				prompt.Metadata.Version,
+				prompt.Metadata.UpdatedAt.Format(time.RFC3339),
+				prompt.Metadata.Author,
+				current,
+			})
+		}
+
+		t.Render()
+	},
+}
+
+var promptDiffCmd = &cobra.Command{
+	Use:   "diff [id] [version] [version]",
+	Short: "Show the changes between two versions of a prompt",
+	Long:  `Show the changes between two versions of a prompt, the second version defaults to the current one.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Please provide the ID of the prompt and the versions to compare.")
 			return
 		}
+		id := args[0]
 
-		// Validate the updated prompt
-		if err := updatedPrompt.Validate(); err != nil {
-			fmt.Printf("Validation error: %v\n", err)
+		from, err := prompts.LoadPromptVersion(id, args[1])
+		if err != nil {
+			fmt.Printf("Error loading prompt: %v\n", err)
 			return
 		}
 
-		// Save the updated prompt
-		if err := updatedPrompt.Save(); err != nil {
-			fmt.Printf("Error saving updated prompt: %v\n", err)
+		to, err := prompts.LoadRawPrompt(id)
+		if len(args) > 2 {
+			to, err = prompts.LoadPromptVersion(id, args[2])
+		}
+		if err != nil {
+			fmt.Printf("Error loading prompt: %v\n", err)
 			return
 		}
 
-		fmt.Println("Prompt edited successfully.")
+		if err := printPromptDiff(from, to); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// printPromptDiff prints the changes between two prompts as YAML.
+func printPromptDiff(from, to *prompts.Prompt) error {
+	fromYaml, err := yaml.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("Error marshalling prompt to YAML: %v", err)
+	}
+	toYaml, err := yaml.Marshal(to)
+	if err != nil {
+		return fmt.Errorf("Error marshalling prompt to YAML: %v", err)
+	}
+
+	lines := diff.Lines(string(fromYaml), string(toYaml))
+	if !diff.Changed(lines) {
+		fmt.Println("No differences.")
+		return nil
+	}
 
-		promptYaml, err = yaml.Marshal(updatedPrompt)
+	fmt.Printf("--- %s@%s\n", from.ID, from.Metadata.Version)
+	fmt.Printf("+++ %s@%s\n", to.ID, to.Metadata.Version)
+	fmt.Print(term.FormatHunks(diff.Hunks(lines, promptDiffContext)))
+
+	return nil
+}
+
+var promptRollbackCmd = &cobra.Command{
+	Use:   "rollback [id] [version]",
+	Short: "Restore a previous version of a prompt",
+	Long:  `Restore the content of a previous version of a prompt, saved as a new version.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Please provide the ID of the prompt and the version to restore.")
+			return
+		}
+
+		prompt, err := prompts.Rollback(args[0], args[1])
 		if err != nil {
-			fmt.Printf("Error marshalling prompt to YAML: %v\n", err)
+			fmt.Printf("Error rolling back prompt: %v\n", err)
 			return
 		}
 
-		fmt.Println("---\n" + string(promptYaml))
+		fmt.Printf(
+			"Prompt %s restored from %s as version %s.\n",
+			prompt.ID,
+			args[1],
+			prompt.Metadata.Version,
+		)
 	},
 }
+
+// promptRenderOptions resolve the variables of a templated prompt, asking
+// for the missing required ones and before reading files, environment
+// variables or running commands.
+func promptRenderOptions(vars map[string]string) prompts.RenderOptions {
+	return prompts.RenderOptions{
+		Vars: vars,
+		Ask: func(v prompts.Variable) (string, error) {
+			label := v.Name
+			if v.Description != "" {
+				label = v.Description
+			}
+
+			return term.PromptForString(label, v.Default, func(s string) error {
+				if s == "" {
+					return fmt.Errorf("%s is required", v.Name)
+				}
+				return nil
+			}), nil
+		},
+		Confirm: func(function, argument string) bool {
+			question := fmt.Sprintf("Run %q to fill the prompt?", argument)
+			switch function {
+			case "file":
+				question = fmt.Sprintf("Read the file %q into the prompt?", argument)
+			case "env":
+				question = fmt.Sprintf("Read the variable $%s into the prompt?", argument)
+			}
+			return term.PromptForBool(question, false)
+		},
+	}
+}
+
+// promptRequestOptions returns the request options of the prompt, with the
+// flags set on the command line taking precedence.
+func promptRequestOptions(
+	cmd *cobra.Command,
+	preferences prompts.Preferences,
+) completion.RequestOptions {
+	opts := preferences.RequestOptions()
+
+	flags := cmd.Flags()
+	if flags.Changed("temperature") {
+		opts.Temperature = &temperature
+	}
+	if flags.Changed("top-p") {
+		opts.TopP = &topP
+	}
+	if flags.Changed("max-tokens") {
+		opts.MaxTokens = maxTokens
+	}
+	if flags.Changed("stop") {
+		opts.Stop = stopSequences
+	}
+	if flags.Changed("seed") {
+		opts.Seed = &seed
+	}
+	if flags.Changed("json") {
+		opts.JSON = jsonMode
+	}
+
+	return opts
+}
+
+// promptModel returns the provider and the model to use, the --provider
+// and --model flags take precedence over the preferred models of the
+// prompt.
+func promptModel(
+	preferences prompts.Preferences,
+) (providers.AIProvider, string, error) {
+	if targetProvider == "" && targetModel == "" {
+		provider, model := providers.SelectModel(preferences.Models)
+		return provider, model, nil
+	}
+
+	if targetProvider == "" {
+		return providers.CheckProvider(), targetModel, nil
+	}
+
+	provider, err := providers.ParseProvider(targetProvider)
+	if err != nil {
+		return "", "", err
+	}
+
+	return provider, targetModel, nil
+}
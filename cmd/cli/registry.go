@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/nullswan/nomi/internal/config"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// registryDescriptionWidth truncates the descriptions in the tables.
+const registryDescriptionWidth = 60
+
+var promptSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search the prompt registry",
+	Long:  `Search the registry for the prompts matching every word of the query, all of them without query.`,
+	Run: func(_ *cobra.Command, args []string) {
+		registry, err := prompts.LoadRegistry(prompts.NewTrust(cfg.Prompts))
+		if err != nil {
+			fmt.Printf("Error loading prompt registry: %v\n", err)
+			return
+		}
+
+		matches := registry.Search(strings.Join(args, " "))
+		if len(matches) == 0 {
+			fmt.Println("No prompt found.")
+			return
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleLight)
+		t.Style().Options.DrawBorder = false
+
+		t.AppendHeader(
+			table.Row{"Id", "Name", "Description", "Version", "Installed"},
+		)
+		for _, prompt := range matches {
+			description := []rune(prompt.Description)
+			if len(description) > registryDescriptionWidth {
+				description = append(
+					description[:registryDescriptionWidth-3],
+					[]rune("...")...,
+				)
+			}
+
+			installed := ""
+			if p, err := prompts.LoadRawPrompt(prompt.ID); err == nil {
+				installed = p.Metadata.Version
+			}
+
+			t.AppendRow(table.Row{
+				prompt.ID,
+				prompt.Name,
+				string(description),
+				prompt.Metadata.Version,
+				installed,
+			})
+		}
+
+		t.Render()
+	},
+}
+
+var promptInstallCmd = &cobra.Command{
+	Use:   "install [author/id]",
+	Short: "Install a prompt from the registry",
+	Long:  `Install a prompt from the registry, under its namespaced ID.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the prompt to install.")
+			return
+		}
+
+		registry, err := prompts.LoadRegistry(prompts.NewTrust(cfg.Prompts))
+		if err != nil {
+			fmt.Printf("Error loading prompt registry: %v\n", err)
+			return
+		}
+
+		for _, id := range args {
+			prompt, err := registry.Install(id)
+			if err != nil {
+				fmt.Printf("Error installing prompt: %v\n", err)
+				continue
+			}
+
+			fmt.Printf(
+				"Prompt %s %s installed, use it with nomi -p %s\n",
+				prompt.ID,
+				prompt.Metadata.Version,
+				prompt.ID,
+			)
+		}
+	},
+}
+
+var promptUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the prompt registry",
+	Long:  `Download the prompt registry and upgrade the installed prompts having a newer version.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		url := cfg.Prompts.RegistryURL
+		if url == "" {
+			url = config.DefaultRegistryURL
+		}
+
+		fmt.Printf("Updating the prompt registry from %s\n", url)
+		registry, err := prompts.UpdateRegistry(
+			context.Background(),
+			url,
+			prompts.NewTrust(cfg.Prompts),
+		)
+		if err != nil {
+			fmt.Printf("Error updating prompt registry: %v\n", err)
+			return
+		}
+		fmt.Printf("%d prompts available.\n", len(registry.Prompts))
+
+		upgraded, err := registry.Upgrade()
+		for _, prompt := range upgraded {
+			fmt.Printf(
+				"Prompt %s upgraded to %s\n",
+				prompt.ID,
+				prompt.Metadata.Version,
+			)
+		}
+		if err != nil {
+			fmt.Printf("Error upgrading prompts: %v\n", err)
+		}
+	},
+}
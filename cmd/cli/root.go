@@ -23,6 +23,8 @@ func main() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configSetupCmd)
 	// #endregion
 
@@ -31,6 +33,16 @@ func main() {
 	conversationCmd.AddCommand(conversationListCmd)
 	conversationCmd.AddCommand(conversationShowCmd)
 	conversationCmd.AddCommand(conversationDeleteCmd)
+	conversationCmd.AddCommand(conversationExportCmd)
+	// #endregion
+
+	// #region Snippet commands
+	rootCmd.AddCommand(snippetCmd)
+	snippetCmd.AddCommand(snippetListCmd)
+	snippetCmd.AddCommand(snippetShowCmd)
+	snippetCmd.AddCommand(snippetRunCmd)
+	snippetCmd.AddCommand(snippetDeleteCmd)
+	snippetCmd.AddCommand(snippetSearchCmd)
 	// #endregion
 
 	// #region Version commands
@@ -46,30 +58,140 @@ func main() {
 	promptCmd.AddCommand(promptListCmd)
 	promptCmd.AddCommand(promptAddCmd)
 	promptCmd.AddCommand(promptEditCmd)
+	promptCmd.AddCommand(promptNewCmd)
+	promptCmd.AddCommand(promptImportCmd)
+	promptCmd.AddCommand(promptCopyCmd)
+	promptCmd.AddCommand(promptDeleteCmd)
+	promptCmd.AddCommand(promptShowCmd)
+	promptCmd.AddCommand(promptTestCmd)
+	promptCmd.AddCommand(promptHistoryCmd)
+	promptCmd.AddCommand(promptDiffCmd)
+	promptCmd.AddCommand(promptRollbackCmd)
+	promptCmd.AddCommand(promptSearchCmd)
+	promptCmd.AddCommand(promptInstallCmd)
+	promptCmd.AddCommand(promptUpdateCmd)
 	// #endregion
 
 	// #region Use case commands
 	rootCmd.AddCommand(usecaseCmd)
 	usecaseCmd.AddCommand(usecaseListCmd)
-	// usecaseCmd.AddCommand(usecaseAddCmd)
+	usecaseCmd.AddCommand(usecaseAddCmd)
+	usecaseCmd.AddCommand(usecaseRemoveCmd)
+	// #endregion
+
+	// #region MCP commands
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
 	// #endregion
 
+	// #region Workflow commands
+	rootCmd.AddCommand(runCmd)
+	// #endregion
+
+	// #region Schedule commands
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleHistoryCmd)
+	rootCmd.AddCommand(daemonCmd)
+	// #endregion
+
+	// #region Shell commands
+	rootCmd.AddCommand(shellInitCmd)
+	rootCmd.AddCommand(suggestCmd)
+	rootCmd.AddCommand(envCmd)
+	// #endregion
+
+	usecaseAddCmd.Flags().
+		BoolVarP(&usecaseForceAdd, "force", "f", false, "Replace an already installed usecase")
+	conversationExportCmd.Flags().
+		StringVarP(&conversationExportFormat, "format", "f", "ipynb", "Export format (ipynb)")
+	conversationExportCmd.Flags().
+		StringVarP(&conversationExportOutput, "output", "o", "", "Output file, <id>.<format> by default")
+	envCmd.Flags().
+		BoolVar(&envRefresh, "refresh", false, "Probe the environment again")
+	snippetRunCmd.Flags().
+		BoolVarP(&snippetRunYes, "yes", "y", false, "Run without confirmation")
+	configShowCmd.Flags().
+		BoolVar(&configShowSources, "sources", false, "Show the file setting each value")
+	promptAddCmd.Flags().
+		BoolVarP(&promptAddYes, "yes", "y", false, "Replace an installed prompt without confirmation")
+	promptNewCmd.Flags().
+		StringVarP(&promptNewName, "name", "n", "", "Name of the prompt, its ID by default")
+	promptNewCmd.Flags().
+		StringVarP(&promptNewDescription, "description", "d", "", "Description of the prompt")
+	promptNewCmd.Flags().
+		StringVarP(&promptNewAuthor, "author", "a", "", "Author of the prompt, the current user by default")
+	promptNewCmd.Flags().
+		StringVarP(&promptNewSystem, "system", "s", "", "System prompt, skips the editor")
+	promptImportCmd.Flags().
+		BoolVarP(&promptImportForce, "force", "f", false, "Replace an existing prompt of the same ID")
+	promptDeleteCmd.Flags().
+		BoolVarP(&promptDeleteYes, "yes", "y", false, "Delete without confirmation")
+	promptShowCmd.Flags().
+		BoolVarP(&promptShowResolved, "resolved", "r", false, "Resolve the extended prompt and the included fragments")
+	promptTestCmd.Flags().
+		StringArrayVarP(&promptTestModels, "model", "m", nil, "Model to test, as [provider:]model, repeat for a matrix")
+	promptTestCmd.Flags().
+		StringVar(&promptTestGrader, "grader", "", "Model grading the rubric assertions, as [provider:]model")
+	promptTestCmd.Flags().
+		BoolVar(&promptTestJSON, "json", false, "Print the report as JSON")
+	runCmd.Flags().
+		StringArrayVarP(&runVars, "var", "v", nil, "Set a workflow variable (key=value)")
+	scheduleAddCmd.Flags().
+		StringVarP(&scheduleName, "name", "n", "", "Name of the task")
+	scheduleAddCmd.Flags().
+		StringVarP(&schedulePrompt, "prompt", "p", "", "Prompt used for the task")
+	scheduleAddCmd.Flags().
+		StringVarP(&scheduleWorkflow, "workflow", "w", "", "Workflow to run")
+	scheduleAddCmd.Flags().
+		StringVar(&scheduleInput, "input", "", "Input sent to the prompt, or the workflow input variable")
+	scheduleAddCmd.Flags().
+		StringArrayVarP(&scheduleVars, "var", "v", nil, "Set a prompt or workflow variable (key=value)")
+	suggestCmd.Flags().
+		StringVarP(&suggestShell, "shell", "s", "bash", "Shell of the suggested command (bash, zsh, fish)")
+	suggestCmd.Flags().
+		StringVarP(&suggestModel, "model", "m", "", "Specify a model")
+	scheduleHistoryCmd.Flags().
+		IntVarP(&scheduleRunLimit, "limit", "l", 20, "Number of runs to show")
+
 	// Attach flags to rootCmd only, so they are not inherited by subcommands
 	rootCmd.Flags().
 		StringVarP(&startPrompt, "prompt", "p", "", "Specify a prompt")
 	rootCmd.Flags().
 		StringVarP(&targetModel, "model", "m", "", "Specify a model")
+	rootCmd.Flags().
+		StringVar(&targetProvider, "provider", "", "Specify a provider (openai, openrouter, ollama)")
+	rootCmd.Flags().
+		Float32Var(&temperature, "temperature", 0, "Sampling temperature, overrides the prompt")
+	rootCmd.Flags().
+		Float32Var(&topP, "top-p", 0, "Nucleus sampling probability, overrides the prompt")
+	rootCmd.Flags().
+		IntVar(&maxTokens, "max-tokens", 0, "Maximum number of tokens of a completion, overrides the prompt")
+	rootCmd.Flags().
+		StringArrayVar(&stopSequences, "stop", nil, "Stop sequence, overrides the prompt")
+	rootCmd.Flags().
+		IntVar(&seed, "seed", 0, "Sampling seed, overrides the prompt")
+	rootCmd.Flags().
+		BoolVar(&jsonMode, "json", false, "Ask for JSON answers, overrides the prompt")
 	rootCmd.Flags().
 		StringVarP(&startConversationID, "conversation", "c", "", "Open a conversation by ID")
 	rootCmd.Flags().
 		BoolVarP(&interactiveMode, "interactive", "i", false, "Start in interactive mode")
+	rootCmd.Flags().
+		StringArrayVarP(&promptVars, "var", "v", nil, "Set a prompt variable (key=value)")
 
 	// Initialize cfg in PersistentPreRun, making it available to all commands
-	rootCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
-		if !config.Exists() {
+	// Messages go to stderr, stdout can be captured by a shell or a client
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
+		// The commands owning stdout cannot run the interactive setup, they
+		// run with the default configuration, left unsaved so the setup
+		// still runs on the next interactive command
+		if !config.Exists() && !ownsStdout(cmd) {
 			fmt.Println("Looks like this is your first time running nomi!")
 			if err := setup.Setup(); err != nil {
-				fmt.Printf("Error during configuration setup: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error during configuration setup: %v\n", err)
 				os.Exit(1)
 			}
 		}
@@ -77,20 +199,30 @@ func main() {
 		var err error
 		cfg, err = config.LoadConfig()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			// Let the config commands fix the configuration
+			if cmd.Parent() != configCmd {
+				os.Exit(1)
+			}
+		}
+
 		oaiKey := os.Getenv("OPENAI_API_KEY")
 		if oaiKey == "" {
 			if cfg.Input.Voice.Enabled {
-				fmt.Println(
+				fmt.Fprintln(
+					os.Stderr,
 					ErrLocalSTTNotSupported,
 				)
 				cfg.Input.Voice.Enabled = false
 			}
 			if cfg.Output.Speech.Enabled {
-				fmt.Println(
+				fmt.Fprintln(
+					os.Stderr,
 					ErrLocalTTSSNotSupported,
 				)
 				cfg.Output.Speech.Enabled = false
@@ -120,11 +252,11 @@ func main() {
 
 				ln, err := net.Listen("tcp", "localhost:0")
 				if err != nil {
-					fmt.Printf("Error starting pprof server: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error starting pprof server: %v\n", err)
 					os.Exit(1)
 				}
 				port := ln.Addr().(*net.TCPAddr).Port
-				fmt.Printf("pprof server started on localhost:%d\n", port)
+				fmt.Fprintf(os.Stderr, "pprof server started on localhost:%d\n", port)
 
 				server := &http.Server{
 					Handler:      mux,
@@ -134,7 +266,7 @@ func main() {
 				}
 
 				if err := server.Serve(ln); err != nil {
-					fmt.Printf("Error starting pprof server: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error starting pprof server: %v\n", err)
 					os.Exit(1)
 				}
 			}()
@@ -147,3 +279,9 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// ownsStdout reports whether the output of the command is captured, by a
+// shell widget or an MCP client.
+func ownsStdout(cmd *cobra.Command) bool {
+	return cmd == suggestCmd || cmd == shellInitCmd || cmd == mcpServeCmd
+}
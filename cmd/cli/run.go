@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/logger"
+	"github.com/nullswan/nomi/internal/tools"
+	"github.com/nullswan/nomi/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var runVars []string
+
+var runCmd = &cobra.Command{
+	Use:   "run [workflow]",
+	Short: "Run a workflow",
+	Long: `Run a declarative YAML workflow from the workflows directory, or from
+a file path. Variables can be passed with --var key=value.`,
+	Run: func(_ *cobra.Command, args []string) {
+		// Fallback to list command
+		if len(args) == 0 {
+			listWorkflows()
+			return
+		}
+
+		var (
+			wf  *workflow.Workflow
+			err error
+		)
+		if _, statErr := os.Stat(args[0]); statErr == nil {
+			wf, err = workflow.LoadWorkflowFile(args[0])
+		} else {
+			wf, err = workflow.LoadWorkflow(args[0])
+		}
+		if err != nil {
+			if errors.Is(err, workflow.ErrWorkflowNotFound) {
+				fmt.Println("workflow " + args[0] + " not found")
+				return
+			}
+			fmt.Printf("Error loading workflow: %v\n", err)
+			return
+		}
+
+		pairs, err := parseVars(runVars)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		vars := make(map[string]interface{}, len(pairs))
+		for k, v := range pairs {
+			vars[k] = v
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			fmt.Println("Sig received, quitting...")
+			cancel()
+		}()
+
+		if err := cli.InitInterpreter(cfg.Interpreter); err != nil {
+			fmt.Printf("Error initializing interpreter: %v\n", err)
+			return
+		}
+
+		logger := logger.Init()
+
+		textToJSONBackend, err := cli.InitJSONProviders(
+			logger,
+			targetModel,
+		)
+		if err != nil {
+			fmt.Printf("Error initializing providers: %v\n", err)
+			return
+		}
+		defer textToJSONBackend.Close()
+
+		textToTextBackend, err := cli.InitTextProviders(
+			logger,
+			targetModel,
+			false,
+		)
+		if err != nil {
+			fmt.Printf("Error initializing providers: %v\n", err)
+			return
+		}
+		defer textToTextBackend.Close()
+
+		ttjBackend := tools.NewTextToJSONBackend(textToJSONBackend, logger)
+		tttBackend := tools.NewTextToTextBackend(textToTextBackend, logger)
+
+		engine := &workflow.Engine{
+			TextToText:   &tttBackend,
+			TextToJSON:   &ttjBackend,
+			Console:      tools.NewBashConsole(),
+			Selector:     tools.NewSelector(),
+			InputHandler: tools.NewInputHandler(logger),
+			Logger:       tools.NewLogger(cfg.DevMode),
+		}
+
+		if _, err := engine.Run(ctx, wf, vars); err != nil {
+			fmt.Printf("Error running workflow: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func listWorkflows() {
+	workflows, err := workflow.ListWorkflows()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Println("No workflows found.")
+			return
+		}
+		fmt.Printf("Error listing workflows: %v\n", err)
+		return
+	}
+
+	if len(workflows) == 0 {
+		fmt.Println("No workflows found.")
+		return
+	}
+
+	fmt.Println("Available workflows:")
+	for _, wf := range workflows {
+		fmt.Println(wf.ID + " - " + wf.Description)
+	}
+}
+
+// parseVars parses key=value pairs given on the command line.
+func parseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf(
+				"invalid variable %q, expected key=value",
+				pair,
+			)
+		}
+		vars[key] = value
+	}
+
+	return vars, nil
+}
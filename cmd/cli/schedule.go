@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/nullswan/nomi/internal/schedule"
+	"github.com/nullswan/nomi/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleName     string
+	schedulePrompt   string
+	scheduleWorkflow string
+	scheduleInput    string
+	scheduleVars     []string
+	scheduleRunLimit int
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage scheduled tasks",
+	Long: `Manage prompts and workflows executed on a cron schedule.
+Scheduled tasks are executed by the daemon, see 'nomi daemon'.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			fmt.Println("Error displaying help:", err)
+		}
+	},
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add [cron]",
+	Short: "Schedule a prompt or a workflow",
+	Long: `Schedule a prompt or a workflow using a cron expression,
+e.g. "0 9 * * mon-fri" or "@daily".
+
+  nomi schedule add "0 8 * * *" --input "Give me a quote of the day"
+  nomi schedule add "0 9 * * *" --workflow standup --var repo=~/nomi`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the cron expression of the task.")
+			return
+		}
+
+		vars, err := parseVars(scheduleVars)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		job := &schedule.Job{
+			Name:    scheduleName,
+			Cron:    args[0],
+			Kind:    schedule.JobKindPrompt,
+			Target:  schedulePrompt,
+			Input:   scheduleInput,
+			Vars:    vars,
+			Enabled: true,
+		}
+
+		if scheduleWorkflow != "" {
+			if schedulePrompt != "" {
+				fmt.Println("--prompt and --workflow are exclusive.")
+				return
+			}
+
+			if _, err := workflow.LoadWorkflow(scheduleWorkflow); err != nil {
+				fmt.Printf("Error loading workflow: %v\n", err)
+				return
+			}
+
+			job.Kind = schedule.JobKindWorkflow
+			job.Target = scheduleWorkflow
+		}
+
+		if err := job.Validate(); err != nil {
+			fmt.Printf("Invalid task: %v\n", err)
+			return
+		}
+
+		if err := job.Reschedule(time.Now()); err != nil {
+			fmt.Printf("Invalid task: %v\n", err)
+			return
+		}
+
+		repo, err := schedule.NewSQLiteRepository(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		if err := repo.SaveJob(job); err != nil {
+			fmt.Println("Error saving task:", err)
+			return
+		}
+
+		fmt.Printf(
+			"Task %s scheduled, next run at %s.\n",
+			job.ID,
+			job.NextRunAt.Local().Format(time.RFC3339),
+		)
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all scheduled tasks",
+	Run: func(_ *cobra.Command, _ []string) {
+		repo, err := schedule.NewSQLiteRepository(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		jobs, err := repo.LoadJobs()
+		if err != nil {
+			fmt.Println("Error listing tasks:", err)
+			return
+		}
+
+		t := newScheduleTable()
+		t.AppendHeader(
+			table.Row{"Id", "Name", "Cron", "Task", "Next Run", "Last Run"},
+		)
+
+		for _, job := range jobs {
+			task := string(job.Kind) + ":" + job.Target
+			if job.Kind == schedule.JobKindPrompt && job.Target == "" {
+				task = string(job.Kind)
+			}
+
+			t.AppendRow(
+				[]interface{}{
+					job.ID,
+					job.Name,
+					job.Cron,
+					task,
+					formatScheduleTime(job.NextRunAt),
+					formatScheduleTime(job.LastRunAt),
+				},
+			)
+		}
+
+		t.Render()
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a scheduled task",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the task to remove.")
+			return
+		}
+
+		repo, err := schedule.NewSQLiteRepository(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		if err := repo.DeleteJob(args[0]); err != nil {
+			if errors.Is(err, schedule.ErrJobNotFound) {
+				fmt.Println("task " + args[0] + " not found")
+				return
+			}
+			fmt.Println("Error removing task:", err)
+			return
+		}
+
+		fmt.Println("Task removed.")
+	},
+}
+
+var scheduleHistoryCmd = &cobra.Command{
+	Use:   "history [id]",
+	Short: "Show the run history of the scheduled tasks",
+	Run: func(_ *cobra.Command, args []string) {
+		jobID := ""
+		if len(args) > 0 {
+			jobID = args[0]
+		}
+
+		repo, err := schedule.NewSQLiteRepository(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		runs, err := repo.LoadRuns(jobID, scheduleRunLimit)
+		if err != nil {
+			fmt.Println("Error listing runs:", err)
+			return
+		}
+
+		t := newScheduleTable()
+		t.AppendHeader(
+			table.Row{"Task", "Started At", "Duration", "Status", "Conversation", "Error"},
+		)
+
+		for _, run := range runs {
+			t.AppendRow(
+				[]interface{}{
+					run.JobID,
+					formatScheduleTime(run.StartedAt),
+					run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond),
+					run.Status,
+					run.ConversationID,
+					run.Error,
+				},
+			)
+		}
+
+		t.Render()
+	},
+}
+
+func newScheduleTable() table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleLight)
+
+	t.Style().Options.SeparateHeader = false
+	t.Style().Options.SeparateFooter = false
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateRows = false
+	t.Style().Options.SeparateColumns = false
+
+	return t
+}
+
+func formatScheduleTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
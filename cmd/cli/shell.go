@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/environment"
+	"github.com/nullswan/nomi/internal/shell"
+	"github.com/nullswan/nomi/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestShell string
+	suggestModel string
+)
+
+var shellInitCmd = &cobra.Command{
+	Use:       "shell-init [bash|zsh|fish]",
+	Short:     "Print the shell integration script",
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Long: `Print a widget binding Ctrl+G to 'nomi suggest' in your shell.
+The current line is replaced by the suggested command, which is never run.
+
+  bash: eval "$(nomi shell-init bash)"   in ~/.bashrc
+  zsh:  eval "$(nomi shell-init zsh)"    in ~/.zshrc
+  fish: nomi shell-init fish | source    in ~/.config/fish/config.fish`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the shell: bash, zsh or fish.")
+			return
+		}
+
+		s, err := shell.ParseShell(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		script, err := shell.InitScript(s, binaryName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Print(script)
+	},
+}
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest [line]",
+	Short: "Suggest a shell command from the current line",
+	Long: `Suggest a shell command from a natural language request or a partial
+command. Recent history can be piped on stdin, one command per line.
+
+Only the command is printed on stdout, the explanation and the risk level
+are printed on stderr.`,
+	Run: func(_ *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		s, err := shell.ParseShell(suggestShell)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		cwd, _ := os.Getwd()
+		req := shell.Request{
+			Shell:   s,
+			OS:      runtime.GOOS,
+			Cwd:     cwd,
+			Buffer:  strings.Join(args, " "),
+			History: readHistory(),
+		}
+
+		// Stdout is reserved to the command, report errors on stderr
+		logger := slog.New(
+			slog.NewTextHandler(
+				os.Stderr,
+				&slog.HandlerOptions{Level: slog.LevelError},
+			),
+		)
+
+		env, err := environment.Load(ctx, config.GetEnvironmentCachePath())
+		if err != nil {
+			logger.Error("Failed to probe the environment", "error", err)
+		}
+		req.Environment = env
+
+		textToJSONBackend, err := cli.InitJSONProviders(
+			logger,
+			suggestModel,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing providers: %v\n", err)
+			os.Exit(1)
+		}
+		defer textToJSONBackend.Close()
+
+		suggestion, err := shell.Suggest(
+			ctx,
+			tools.NewTextToJSONBackend(textToJSONBackend, logger),
+			req,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error suggesting command: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(
+			os.Stderr,
+			"[%s risk] %s\n",
+			suggestion.Risk,
+			suggestion.Explanation,
+		)
+		fmt.Println(suggestion.Command)
+	},
+}
+
+// readHistory reads the piped shell history, if any.
+func readHistory() []string {
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice != 0 {
+		return nil
+	}
+
+	var history []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+
+	return history
+}
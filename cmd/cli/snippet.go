@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/term"
+	"github.com/spf13/cobra"
+)
+
+// snippetDescriptionWidth truncates the descriptions in the tables.
+const snippetDescriptionWidth = 60
+
+var snippetRunYes bool
+
+var snippetCmd = &cobra.Command{
+	Use:   "snippet",
+	Short: "Manage the saved code snippets",
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			fmt.Println("Error displaying help:", err)
+		}
+	},
+}
+
+var snippetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all snippets",
+	Long:  `List the code saved from the successful interpreter executions.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		repo, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		blocks, err := repo.LoadCodeBlocks()
+		if err != nil {
+			fmt.Println("Error listing snippets:", err)
+			return
+		}
+
+		renderSnippets(blocks)
+	},
+}
+
+var snippetSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search the snippets",
+	Long:  `Search the snippets whose description, code or language contain every word of the query.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the query to search.")
+			return
+		}
+
+		repo, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		blocks, err := repo.SearchCodeBlocks(strings.Join(args, " "))
+		if err != nil {
+			fmt.Println("Error searching snippets:", err)
+			return
+		}
+
+		if len(blocks) == 0 {
+			fmt.Println("No snippets found.")
+			return
+		}
+
+		renderSnippets(blocks)
+	},
+}
+
+var snippetShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show a snippet",
+	Long:  `Show a snippet by its ID.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the snippet to show.")
+			return
+		}
+
+		repo, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		block, err := repo.LoadCodeBlock(args[0])
+		if err != nil {
+			fmt.Println("Error showing snippet:", err)
+			return
+		}
+
+		if err := renderSnippet(block); err != nil {
+			fmt.Println("Error rendering markdown:", err)
+			return
+		}
+	},
+}
+
+var snippetRunCmd = &cobra.Command{
+	Use:   "run [id]",
+	Short: "Run a snippet",
+	Long:  `Run a snippet by its ID, after confirmation unless --yes is set.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the snippet to run.")
+			return
+		}
+
+		repo, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		block, err := repo.LoadCodeBlock(args[0])
+		if err != nil {
+			fmt.Println("Error loading snippet:", err)
+			return
+		}
+
+		if !snippetRunYes {
+			if err := renderSnippet(block); err != nil {
+				fmt.Println("Error rendering markdown:", err)
+				return
+			}
+			if !term.PromptForBool("Run this snippet?", false) {
+				return
+			}
+		}
+
+		if err := cli.InitInterpreter(cfg.Interpreter); err != nil {
+			fmt.Printf("Error initializing interpreter: %v\n", err)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sigChan)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		result := code.ExecuteCodeBlock(ctx, block, code.ExecuteOptions{
+			OnOutput: func(stream code.Stream, data []byte) {
+				if stream == code.StreamStderr {
+					_, _ = os.Stderr.Write(data)
+					return
+				}
+				_, _ = os.Stdout.Write(data)
+			},
+		})
+
+		fmt.Printf("Exited with code %d\n", result.ExitCode)
+		if result.ExitCode != 0 {
+			os.Exit(result.ExitCode)
+		}
+	},
+}
+
+var snippetDeleteCmd = &cobra.Command{
+	Use:   "delete [id]",
+	Short: "Delete a snippet",
+	Long:  `Delete a snippet by its ID.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the snippet to delete.")
+			return
+		}
+
+		repo, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+		if err != nil {
+			fmt.Println("Error creating repository:", err)
+			return
+		}
+		defer repo.Close()
+
+		if err := repo.DeleteCodeBlock(args[0]); err != nil {
+			fmt.Println("Error deleting snippet:", err)
+			return
+		}
+
+		fmt.Println("Snippet deleted.")
+	},
+}
+
+func renderSnippets(blocks []code.CodeBlock) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleLight)
+	t.Style().Options.DrawBorder = false
+
+	t.AppendHeader(
+		table.Row{"Id", "Created At", "Language", "Description"},
+	)
+
+	for _, block := range blocks {
+		description := block.Description
+		if len(description) > snippetDescriptionWidth {
+			description = description[:snippetDescriptionWidth-3] + "..."
+		}
+
+		t.AppendRow(table.Row{
+			block.ID,
+			block.CreatedAt.Format(time.RFC3339),
+			block.Language,
+			description,
+		})
+	}
+
+	t.Render()
+}
+
+func renderSnippet(block code.CodeBlock) error {
+	renderer, err := term.InitRenderer()
+	if err != nil {
+		return err
+	}
+
+	content, err := renderer.Render(
+		fmt.Sprintf(
+			"%s\n\n```%s\n%s\n```",
+			block.Description,
+			block.Language,
+			block.Code,
+		),
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Println(content)
+
+	return nil
+}
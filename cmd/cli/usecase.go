@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 
+	"github.com/nullswan/nomi/internal/approval"
 	"github.com/nullswan/nomi/internal/chat"
 	"github.com/nullswan/nomi/internal/cli"
+	"github.com/nullswan/nomi/internal/code"
 	"github.com/nullswan/nomi/internal/logger"
+	"github.com/nullswan/nomi/internal/plugin"
 	"github.com/nullswan/nomi/internal/providers"
 	"github.com/nullswan/nomi/internal/tools"
 	"github.com/nullswan/nomi/usecases/browser"
@@ -19,8 +24,88 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// usecaseTools holds the tools shared with the usecases.
+type usecaseTools struct {
+	console      tools.Console
+	selector     tools.Selector
+	logger       tools.Logger
+	inputHandler tools.InputHandler
+	textToJSON   tools.TextToJSONBackend
+	textToSpeech *tools.TextToSpeechBackend
+	conversation chat.Conversation
+	approver     *approval.Approver
+	snippets     code.Repository
+}
+
+type builtinUsecase struct {
+	description string
+	run         func(ctx context.Context, t usecaseTools) error
+}
+
+var builtinUsecases = map[string]builtinUsecase{
+	"commit": {
+		description: "Quickly commit changes to git",
+		run: func(ctx context.Context, t usecaseTools) error {
+			return commit.OnStart(
+				ctx,
+				t.console,
+				t.selector,
+				t.logger,
+				t.textToJSON,
+				t.inputHandler,
+				t.conversation,
+			)
+		},
+	},
+	"copywriter": {
+		description: "Generate copywriting documents",
+		run: func(ctx context.Context, t usecaseTools) error {
+			return copywriter.OnStart(
+				ctx,
+				t.selector,
+				t.logger,
+				t.inputHandler,
+				t.textToJSON,
+				t.conversation,
+			)
+		},
+	},
+	"browser": {
+		description: "Browse the web with LLM",
+		run: func(ctx context.Context, t usecaseTools) error {
+			return browser.OnStart(
+				ctx,
+				t.selector,
+				t.logger,
+				t.inputHandler,
+				t.textToJSON,
+				t.textToSpeech,
+				t.conversation,
+			)
+		},
+	},
+	"interpreter": {
+		description: "Interact with the console",
+		run: func(ctx context.Context, t usecaseTools) error {
+			return interpreter.OnStart(
+				ctx,
+				t.selector,
+				t.logger,
+				t.textToJSON,
+				t.inputHandler,
+				t.conversation,
+				t.approver,
+				t.snippets,
+				cfg.Interpreter.Notebook,
+			)
+		},
+	},
+}
+
+var usecaseForceAdd bool
+
 var usecaseCmd = &cobra.Command{
-	Use:     "usecase [usecaseID]",
+	Use:     "usecase [usecaseID] [arguments]",
 	Aliases: []string{"u"},
 	Short:   "Run a usecase",
 	Run: func(_ *cobra.Command, args []string) {
@@ -32,6 +117,21 @@ var usecaseCmd = &cobra.Command{
 
 		usecaseID := args[0]
 
+		builtin, isBuiltin := builtinUsecases[usecaseID]
+		var manifest *plugin.Manifest
+		if !isBuiltin {
+			var err error
+			manifest, err = plugin.Load(usecaseID)
+			if err != nil {
+				if errors.Is(err, plugin.ErrUsecaseNotFound) {
+					fmt.Println("usecase " + usecaseID + " not found")
+					return
+				}
+				fmt.Printf("Error loading usecase: %v\n", err)
+				return
+			}
+		}
+
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
@@ -87,23 +187,26 @@ var usecaseCmd = &cobra.Command{
 			)
 		}
 
-		textToJSONBackend, err := cli.InitJSONProviders(
-			logger,
-			targetModel,
-		)
-		if err != nil {
-			fmt.Printf("Error initializing providers: %v\n", err)
-			return
-		}
-		defer textToJSONBackend.Close()
+		var ttjBackend tools.TextToJSONBackend
+		if isBuiltin || manifest.HasCapability(plugin.CapabilityJSON) {
+			textToJSONBackend, err := cli.InitJSONProviders(
+				logger,
+				targetModel,
+			)
+			if err != nil {
+				fmt.Printf("Error initializing providers: %v\n", err)
+				return
+			}
+			defer textToJSONBackend.Close()
 
-		ttjBackend := tools.NewTextToJSONBackend(
-			textToJSONBackend,
-			logger,
-		)
+			ttjBackend = tools.NewTextToJSONBackend(
+				textToJSONBackend,
+				logger,
+			)
+		}
 
 		var ttsBackend *tools.TextToSpeechBackend
-		if cfg.Output.Speech.Enabled {
+		if isBuiltin && cfg.Output.Speech.Enabled {
 			ttsProvider, err := providers.LoadTextToSpeechProvider(
 				providers.OpenAIProvider,
 				"",
@@ -119,48 +222,73 @@ var usecaseCmd = &cobra.Command{
 			defer ttsProvider.Close()
 		}
 
-		switch usecaseID {
-		case "commit":
-			err = commit.OnStart(
-				ctx,
-				console,
-				selector,
-				toolsLogger,
-				ttjBackend,
-				inputHandler,
-				conversation,
-			)
-		case "copywriter":
-			err = copywriter.OnStart(
-				ctx,
-				selector,
-				toolsLogger,
-				inputHandler,
-				ttjBackend,
-				conversation,
-			)
-		case "browser":
-			err = browser.OnStart(
-				ctx,
-				selector,
-				toolsLogger,
-				inputHandler,
-				ttjBackend,
-				ttsBackend,
-				conversation,
-			)
-		case "interpreter":
-			err = interpreter.OnStart(
-				ctx,
+		if isBuiltin {
+			if err := cli.InitInterpreter(cfg.Interpreter); err != nil {
+				fmt.Printf("Error initializing interpreter: %v\n", err)
+				return
+			}
+
+			approver, err := approval.NewApprover(
+				cfg.Interpreter.Approval,
+				usecaseID,
 				selector,
 				toolsLogger,
-				ttjBackend,
-				inputHandler,
-				conversation,
 			)
-		default:
-			fmt.Println("usecase " + usecaseID + " not found")
-			return
+			if err != nil {
+				fmt.Printf("Error initializing approval: %v\n", err)
+				return
+			}
+
+			snippets, err := cli.InitCodeDatabase(cfg.Output.Sqlite.Path)
+			if err != nil {
+				fmt.Printf("Error initializing snippets: %v\n", err)
+				return
+			}
+			defer snippets.Close()
+
+			err = builtin.run(ctx, usecaseTools{
+				console:      console,
+				selector:     selector,
+				logger:       toolsLogger,
+				inputHandler: inputHandler,
+				textToJSON:   ttjBackend,
+				textToSpeech: ttsBackend,
+				conversation: conversation,
+				approver:     approver,
+				snippets:     snippets,
+			})
+		} else {
+			host := &plugin.Host{
+				InputHandler: inputHandler,
+				Selector:     selector,
+				Console:      console,
+				Logger:       toolsLogger,
+			}
+
+			if manifest.HasCapability(plugin.CapabilityJSON) {
+				host.TextToJSON = &ttjBackend
+			}
+
+			if manifest.HasCapability(plugin.CapabilityText) {
+				textToTextBackend, err := cli.InitTextProviders(
+					logger,
+					targetModel,
+					false,
+				)
+				if err != nil {
+					fmt.Printf("Error initializing providers: %v\n", err)
+					return
+				}
+				defer textToTextBackend.Close()
+
+				tttBackend := tools.NewTextToTextBackend(
+					textToTextBackend,
+					logger,
+				)
+				host.TextToText = &tttBackend
+			}
+
+			err = host.Run(ctx, manifest, args[1:])
 		}
 
 		if err != nil {
@@ -173,10 +301,97 @@ var usecaseListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all usecases",
 	Run: func(_ *cobra.Command, _ []string) {
+		ids := make([]string, 0, len(builtinUsecases))
+		for id := range builtinUsecases {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
 		fmt.Println("Available usecases:")
-		fmt.Println("commit - Quickly commit changes to git")
-		fmt.Println("copywriter - Generate copywriting documents")
-		fmt.Println("browser - Browse the web with LLM")
-		fmt.Println("console - Interact with the console")
+		for _, id := range ids {
+			fmt.Println(id + " - " + builtinUsecases[id].description)
+		}
+
+		manifests, err := plugin.List()
+		if err != nil {
+			fmt.Printf("Error listing installed usecases: %v\n", err)
+			return
+		}
+
+		if len(manifests) == 0 {
+			return
+		}
+
+		fmt.Println()
+		fmt.Println("Installed usecases:")
+		for _, m := range manifests {
+			fmt.Println(m.ID + " - " + m.Description)
+		}
+	},
+}
+
+var usecaseAddCmd = &cobra.Command{
+	Use:   "add [path]",
+	Short: "Install an external usecase",
+	Long: `Install an external usecase from a directory containing a manifest.yml,
+or from the manifest file itself.`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the path of the usecase to install.")
+			return
+		}
+
+		manifest, err := plugin.LoadManifest(args[0])
+		if err != nil {
+			fmt.Printf("Error loading usecase: %v\n", err)
+			return
+		}
+
+		if _, ok := builtinUsecases[manifest.ID]; ok {
+			fmt.Printf(
+				"Usecase %s conflicts with a built-in usecase.\n",
+				manifest.ID,
+			)
+			return
+		}
+
+		installed, err := plugin.Install(args[0], usecaseForceAdd)
+		if err != nil {
+			if errors.Is(err, plugin.ErrUsecaseExists) {
+				fmt.Printf(
+					"Usecase %s is already installed, use --force to replace it.\n",
+					manifest.ID,
+				)
+				return
+			}
+			fmt.Printf("Error installing usecase: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Usecase %s installed successfully.\n", installed.ID)
+		if len(installed.Capabilities) > 0 {
+			fmt.Println("Capabilities:")
+			for _, c := range installed.Capabilities {
+				fmt.Println("  - " + string(c))
+			}
+		}
+	},
+}
+
+var usecaseRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove an external usecase",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println("Please provide the ID of the usecase to remove.")
+			return
+		}
+
+		if err := plugin.Remove(args[0]); err != nil {
+			fmt.Printf("Error removing usecase: %v\n", err)
+			return
+		}
+
+		fmt.Println("Usecase removed.")
 	},
 }
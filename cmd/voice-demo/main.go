@@ -12,6 +12,7 @@ import (
 	"github.com/nullswan/nomi/internal/audio"
 	"github.com/nullswan/nomi/internal/logger"
 	"github.com/nullswan/nomi/internal/transcription"
+	"github.com/nullswan/nomi/internal/transcription/languages"
 )
 
 func main() {
@@ -24,10 +25,10 @@ func main() {
 
 	flag.Parse()
 
-	var lang transcription.STTLang
+	var lang languages.STTLang
 	var err error
 	if *langFlag != "" {
-		lang, err = transcription.LoadLangFromValue(*langFlag)
+		lang, err = languages.LoadLangFromValue(*langFlag)
 		if err != nil {
 			logger.Error("Invalid language code", "error", err)
 			return
@@ -25,6 +25,7 @@ require (
 	github.com/robotn/gohook v0.41.0
 	github.com/sashabaranov/go-openai v1.36.0
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/crypto v0.29.0
 	golang.org/x/sync v0.9.0
 	golang.org/x/term v0.26.0
 	gopkg.in/yaml.v2 v2.4.0
@@ -0,0 +1,191 @@
+package approval
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/diff"
+	"github.com/nullswan/nomi/internal/term"
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+const (
+	choiceRun  = "Run"
+	choiceEdit = "Edit before running"
+	choiceDeny = "Deny"
+)
+
+// Approver reviews the generated code before its execution, following the
+// policy, and records every decision in the audit log.
+type Approver struct {
+	Policy   Policy
+	Usecase  string
+	Selector tools.Selector
+	Logger   tools.Logger
+	// Audit is optional
+	Audit *AuditLog
+	// Edit opens the code in an editor, defaults to $EDITOR
+	Edit func(code, language string) (string, error)
+}
+
+// NewApprover returns the approver of the usecase executions, the policy of
+// the usecase takes precedence over the global one.
+func NewApprover(
+	cfg config.ApprovalConfig,
+	usecase string,
+	selector tools.Selector,
+	logger tools.Logger,
+) (*Approver, error) {
+	name := cfg.Policy
+	if override, ok := cfg.Usecases[usecase]; ok {
+		name = override
+	}
+
+	policy, err := ParsePolicy(name)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog := cfg.AuditLog
+	if auditLog == "" {
+		auditLog = config.GetAuditLogPath()
+	}
+
+	return &Approver{
+		Policy:   policy,
+		Usecase:  usecase,
+		Selector: selector,
+		Logger:   logger,
+		Audit:    NewAuditLog(auditLog),
+	}, nil
+}
+
+// Review is the outcome of an approval, Code holds the code to run, which
+// differs from the reviewed code when edited.
+type Review struct {
+	Approved   bool
+	Edited     bool
+	Code       string
+	Assessment Assessment
+}
+
+// Review classifies the code and asks the user when the policy requires it.
+func (a *Approver) Review(language, code string) Review {
+	assessment := Classify(language, code)
+	review := Review{Code: code, Assessment: assessment}
+
+	if !a.Policy.RequiresApproval(assessment) {
+		review.Approved = true
+		a.record(language, review, false)
+		return review
+	}
+
+	fmt.Println(formatAssessment(language, assessment))
+	fmt.Print(term.FormatDiff(diff.Lines("", code)))
+
+	for {
+		switch a.Selector.SelectString(
+			"Run this code?",
+			[]string{choiceRun, choiceEdit, choiceDeny},
+		) {
+		case choiceRun:
+			review.Approved = true
+			a.record(language, review, true)
+			return review
+		case choiceEdit:
+			edited, err := a.edit(review.Code, language)
+			if err != nil {
+				fmt.Printf("Error editing code: %v\n", err)
+				continue
+			}
+
+			if edited == review.Code {
+				fmt.Println("No changes.")
+				continue
+			}
+
+			review.Code = edited
+			review.Edited = true
+			review.Assessment = Classify(language, edited)
+
+			fmt.Println(formatAssessment(language, review.Assessment))
+			fmt.Print(term.FormatDiff(diff.Lines(code, edited)))
+		default:
+			a.record(language, review, true)
+			return review
+		}
+	}
+}
+
+func (a *Approver) edit(code, language string) (string, error) {
+	if a.Edit != nil {
+		return a.Edit(code, language)
+	}
+	return term.Edit(code, "nomi-*"+fileExtension(language))
+}
+
+func (a *Approver) record(language string, review Review, prompted bool) {
+	if a.Audit == nil {
+		return
+	}
+
+	decision := DecisionDenied
+	if review.Approved {
+		decision = DecisionApproved
+	}
+
+	err := a.Audit.Record(Entry{
+		Time:     time.Now(),
+		Usecase:  a.Usecase,
+		Language: language,
+		Policy:   a.Policy,
+		Risk:     review.Assessment.Level,
+		Reasons:  review.Assessment.Reasons(),
+		Decision: decision,
+		Prompted: prompted,
+		Edited:   review.Edited,
+		Code:     review.Code,
+	})
+	if err != nil && a.Logger != nil {
+		a.Logger.Error("Failed to record execution: " + err.Error())
+	}
+}
+
+func formatAssessment(language string, a Assessment) string {
+	var sb strings.Builder
+
+	color := term.ColorGreen
+	switch a.Level {
+	case LevelHigh:
+		color = term.ColorRed
+	case LevelMedium:
+		color = term.ColorYellow
+	}
+
+	fmt.Fprintf(
+		&sb,
+		"%s[%s risk]%s %s code",
+		color,
+		a.Level,
+		term.ColorDefault,
+		languageName(language),
+	)
+	for _, reason := range a.Reasons() {
+		sb.WriteString("\n  - " + reason)
+	}
+
+	return sb.String()
+}
+
+func fileExtension(language string) string {
+	switch strings.ToLower(language) {
+	case "bash", "sh", "shell", "zsh":
+		return ".sh"
+	case "python", "python3", "py":
+		return ".py"
+	default:
+		return ".txt"
+	}
+}
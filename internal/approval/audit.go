@@ -0,0 +1,78 @@
+package approval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type Decision string
+
+const (
+	DecisionApproved Decision = "approved"
+	DecisionDenied   Decision = "denied"
+)
+
+// Entry of the audit log, one per reviewed execution.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Usecase  string    `json:"usecase"`
+	Language string    `json:"language"`
+	Policy   Policy    `json:"policy"`
+	Risk     Level     `json:"risk"`
+	Reasons  []string  `json:"reasons,omitempty"`
+	Decision Decision  `json:"decision"`
+	// Prompted is false when the policy approved the code on its own
+	Prompted bool   `json:"prompted"`
+	Edited   bool   `json:"edited"`
+	Code     string `json:"code"`
+}
+
+// AuditLog appends the entries to a JSON lines file.
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Path of the audit log file.
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Record appends the entry to the log.
+func (l *AuditLog) Record(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("error creating audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(
+		l.path,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		0o600,
+	)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+
+	return nil
+}
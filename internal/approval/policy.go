@@ -0,0 +1,52 @@
+package approval
+
+import "fmt"
+
+// Policy decides when the user is asked before running generated code.
+type Policy string
+
+const (
+	// PolicyAlwaysAsk asks before every execution.
+	PolicyAlwaysAsk Policy = "always-ask"
+	// PolicyAskOnRisky asks only when the classifier finds a risk.
+	PolicyAskOnRisky Policy = "ask-on-risky"
+	// PolicyAuto never asks, executions are still audited.
+	PolicyAuto Policy = "auto"
+)
+
+// DefaultPolicy applies when none is configured.
+const DefaultPolicy = PolicyAskOnRisky
+
+var Policies = []Policy{PolicyAlwaysAsk, PolicyAskOnRisky, PolicyAuto}
+
+// ParsePolicy returns the policy matching the name, the default policy
+// when empty.
+func ParsePolicy(name string) (Policy, error) {
+	if name == "" {
+		return DefaultPolicy, nil
+	}
+
+	for _, p := range Policies {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"unknown approval policy %q, expected one of %v",
+		name,
+		Policies,
+	)
+}
+
+// RequiresApproval reports whether the assessed code must be approved.
+func (p Policy) RequiresApproval(a Assessment) bool {
+	switch p {
+	case PolicyAuto:
+		return false
+	case PolicyAskOnRisky:
+		return a.Risky()
+	default:
+		return true
+	}
+}
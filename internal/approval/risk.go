@@ -0,0 +1,406 @@
+package approval
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+type Level string
+
+const (
+	LevelLow    Level = "low"
+	LevelMedium Level = "medium"
+	LevelHigh   Level = "high"
+)
+
+func (l Level) rank() int {
+	switch l {
+	case LevelHigh:
+		return 2
+	case LevelMedium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type Category string
+
+const (
+	CategoryDestructive Category = "destructive"
+	CategoryRemoteCode  Category = "remote-code"
+	CategoryPrivilege   Category = "privilege"
+	CategoryOutsideCwd  Category = "outside-cwd"
+	CategoryNetwork     Category = "network"
+	CategoryShell       Category = "shell"
+	CategoryUnknown     Category = "unknown"
+)
+
+// Finding is a risky construct spotted in the code.
+type Finding struct {
+	Category Category `json:"category"`
+	Level    Level    `json:"level"`
+	Reason   string   `json:"reason"`
+}
+
+// Assessment is the static risk analysis of a piece of code.
+type Assessment struct {
+	Level    Level     `json:"level"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Risky reports whether anything worth a confirmation was found.
+func (a Assessment) Risky() bool {
+	return len(a.Findings) > 0
+}
+
+// Reasons lists the reasons of the findings.
+func (a Assessment) Reasons() []string {
+	reasons := make([]string, 0, len(a.Findings))
+	for _, f := range a.Findings {
+		reasons = append(reasons, f.Reason)
+	}
+	return reasons
+}
+
+func (a *Assessment) add(category Category, level Level, reason string) {
+	for _, f := range a.Findings {
+		if f.Reason == reason {
+			return
+		}
+	}
+
+	a.Findings = append(a.Findings, Finding{
+		Category: category,
+		Level:    level,
+		Reason:   reason,
+	})
+	if level.rank() > a.Level.rank() {
+		a.Level = level
+	}
+}
+
+// Classify statically assesses the risk of running the code. Shell and
+// Python are analysed, other languages are reported as unknown.
+func Classify(language, code string) Assessment {
+	a := Assessment{Level: LevelLow}
+
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "bash", "sh", "shell", "zsh", "fish":
+		classifyShell(&a, code)
+	case "python", "python3", "py":
+		classifyPython(&a, code)
+	default:
+		a.add(
+			CategoryUnknown,
+			LevelMedium,
+			"cannot analyse "+languageName(language)+" code",
+		)
+	}
+
+	return a
+}
+
+func languageName(language string) string {
+	if language == "" {
+		return "unlabelled"
+	}
+	return language
+}
+
+var (
+	shellPipeToInterpreter = regexp.MustCompile(
+		`\b(curl|wget|fetch)\b[^\n]*\|\s*(sudo\s+)?(\S*/)?(ba|z|da|k)?sh\b|` +
+			`\b(curl|wget|fetch)\b[^\n]*\|\s*(sudo\s+)?(\S*/)?(python[0-9.]*|perl|ruby|node)\b`,
+	)
+	shellEvalDownload = regexp.MustCompile(
+		`(\beval\b|\b(ba|z)?sh\b|\bsource\b)[^\n]*(\$\(|<\(|` + "`" + `)\s*(curl|wget)\b`,
+	)
+	shellForkBomb   = regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`)
+	shellRedirect   = regexp.MustCompile(`(?:^|[^0-9&<>])>>?\|?\s*([^\s;&|<>()]+)`)
+	shellSeparators = regexp.MustCompile(`&&|\|\||[;|&\n()]`)
+)
+
+// Commands reaching the network, or installing packages from it
+var (
+	networkCommands = map[string]bool{
+		"curl": true, "wget": true, "ssh": true, "scp": true, "sftp": true,
+		"nc": true, "ncat": true, "netcat": true, "telnet": true, "ftp": true,
+		"rsync": true, "socat": true,
+	}
+	packageManagers = map[string]bool{
+		"pip": true, "pip3": true, "npm": true, "yarn": true, "pnpm": true,
+		"gem": true, "cargo": true, "brew": true, "apt": true, "apt-get": true,
+		"dnf": true, "yum": true, "pacman": true, "go": true, "uv": true,
+	}
+	privilegeCommands = map[string]bool{
+		"sudo": true, "su": true, "doas": true, "pkexec": true,
+	}
+	destructiveCommands = map[string]bool{
+		"shred": true, "wipefs": true, "fdisk": true, "parted": true,
+		"shutdown": true, "reboot": true, "halt": true, "poweroff": true,
+	}
+	// Commands writing to their last argument
+	copyCommands = map[string]bool{
+		"cp": true, "mv": true, "ln": true, "install": true,
+	}
+	// Commands writing to all their arguments
+	writeCommands = map[string]bool{
+		"tee": true, "touch": true, "mkdir": true, "truncate": true,
+		"chmod": true, "chown": true, "rmdir": true,
+	}
+	// Wrappers running their arguments as a command
+	wrapperCommands = map[string]bool{
+		"command": true, "exec": true, "env": true, "nohup": true,
+		"time": true, "xargs": true, "nice": true,
+	}
+)
+
+func classifyShell(a *Assessment, code string) {
+	var lines []string
+	for _, line := range strings.Split(code, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	code = strings.Join(lines, "\n")
+
+	if shellPipeToInterpreter.MatchString(code) ||
+		shellEvalDownload.MatchString(code) {
+		a.add(
+			CategoryRemoteCode,
+			LevelHigh,
+			"runs code downloaded from the network",
+		)
+	}
+	if shellForkBomb.MatchString(code) {
+		a.add(CategoryDestructive, LevelHigh, "contains a fork bomb")
+	}
+
+	for _, m := range shellRedirect.FindAllStringSubmatch(code, -1) {
+		checkWrite(a, m[1])
+	}
+
+	for _, command := range shellSeparators.Split(code, -1) {
+		classifyCommand(a, strings.Fields(command))
+	}
+}
+
+func classifyCommand(a *Assessment, fields []string) {
+	fields = stripWrappers(a, fields)
+	if len(fields) == 0 {
+		return
+	}
+
+	name := path.Base(unquote(fields[0]))
+	args := fields[1:]
+	flags, operands := splitArgs(args)
+
+	switch {
+	case name == "rm":
+		recursive := false
+		for _, f := range flags {
+			if f == "--recursive" || f == "--force" ||
+				!strings.HasPrefix(f, "--") && strings.ContainsAny(f, "rRf") {
+				recursive = true
+			}
+		}
+		if recursive {
+			a.add(
+				CategoryDestructive,
+				LevelHigh,
+				"removes files recursively or forcefully",
+			)
+		}
+		for _, op := range operands {
+			checkWrite(a, op)
+		}
+	case strings.HasPrefix(name, "mkfs") || destructiveCommands[name]:
+		a.add(CategoryDestructive, LevelHigh, "runs "+name)
+	case name == "dd":
+		for _, op := range operands {
+			if strings.HasPrefix(op, "of=") {
+				checkWrite(a, strings.TrimPrefix(op, "of="))
+			}
+		}
+	case networkCommands[name]:
+		a.add(CategoryNetwork, LevelMedium, "accesses the network ("+name+")")
+	case packageManagers[name] && len(operands) > 0 &&
+		(operands[0] == "install" || operands[0] == "add" || operands[0] == "get"):
+		a.add(
+			CategoryNetwork,
+			LevelMedium,
+			"installs packages from the network ("+name+")",
+		)
+	case name == "git" && len(operands) > 0:
+		switch operands[0] {
+		case "clone", "fetch", "pull", "push":
+			a.add(
+				CategoryNetwork,
+				LevelMedium,
+				"accesses the network (git "+operands[0]+")",
+			)
+		}
+	case copyCommands[name] && len(operands) > 1:
+		checkWrite(a, operands[len(operands)-1])
+	case writeCommands[name]:
+		for _, op := range operands {
+			checkWrite(a, op)
+		}
+	}
+}
+
+// stripWrappers skips the variable assignments and the commands running
+// their arguments, such as sudo or env.
+func stripWrappers(a *Assessment, fields []string) []string {
+	for len(fields) > 0 {
+		name := path.Base(unquote(fields[0]))
+		switch {
+		case strings.Contains(fields[0], "=") &&
+			!strings.HasPrefix(fields[0], "-"):
+			fields = fields[1:]
+		case privilegeCommands[name]:
+			a.add(
+				CategoryPrivilege,
+				LevelHigh,
+				"runs commands with elevated privileges ("+name+")",
+			)
+			fields = skipFlags(fields[1:])
+		case wrapperCommands[name]:
+			fields = skipFlags(fields[1:])
+		default:
+			return fields
+		}
+	}
+	return fields
+}
+
+func skipFlags(fields []string) []string {
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+		fields = fields[1:]
+	}
+	return fields
+}
+
+func splitArgs(args []string) ([]string, []string) {
+	var flags, operands []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			flags = append(flags, arg)
+		} else {
+			operands = append(operands, unquote(arg))
+		}
+	}
+	return flags, operands
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, `"'`)
+}
+
+// checkWrite reports writes outside of the working directory.
+func checkWrite(a *Assessment, target string) {
+	target = unquote(target)
+	if target == "" || strings.HasPrefix(target, "&") {
+		return
+	}
+
+	switch {
+	case target == "/dev/null" ||
+		target == "/dev/stdout" ||
+		target == "/dev/stderr" ||
+		target == "/dev/tty" ||
+		strings.HasPrefix(target, "/dev/fd/"):
+		return
+	case strings.HasPrefix(target, "/dev/"):
+		a.add(
+			CategoryDestructive,
+			LevelHigh,
+			"writes to the device "+target,
+		)
+	case target == "/tmp" || strings.HasPrefix(target, "/tmp/") ||
+		strings.HasPrefix(target, "$TMPDIR"):
+		return
+	case outsideCwd(target):
+		a.add(
+			CategoryOutsideCwd,
+			LevelMedium,
+			"writes outside of the working directory ("+target+")",
+		)
+	}
+}
+
+func outsideCwd(target string) bool {
+	if strings.HasPrefix(target, "/") ||
+		strings.HasPrefix(target, "~") ||
+		strings.HasPrefix(target, "$HOME") ||
+		strings.HasPrefix(target, "${HOME}") {
+		return true
+	}
+
+	cleaned := path.Clean(target)
+	return cleaned == ".." || strings.HasPrefix(cleaned, "../")
+}
+
+var (
+	pythonRmtree   = regexp.MustCompile(`\bshutil\.rmtree\s*\(`)
+	pythonRemove   = regexp.MustCompile(`\bos\.(remove|unlink|rmdir|removedirs)\s*\(|\.(unlink|rmdir)\s*\(`)
+	pythonShell    = regexp.MustCompile(`\b(os\.system|os\.popen|os\.exec\w*|os\.spawn\w*|subprocess\.\w+|pty\.spawn)\s*\(`)
+	pythonSetuid   = regexp.MustCompile(`\bos\.set(e|re|res)?[ug]id\s*\(`)
+	pythonNetwork  = regexp.MustCompile(`(?m)^\s*(import|from)\s+(requests|urllib|urllib3|http\.client|httpx|aiohttp|socket|ftplib|smtplib|paramiko|telnetlib|websocket|websockets)\b`)
+	pythonOpen     = regexp.MustCompile(`\bopen\s*\(\s*[rbfuRBFU]*(["'])(.*?)["']\s*,\s*(mode\s*=\s*)?[rbfuRBFU]*["'][^"']*[wax+]`)
+	pythonPathW    = regexp.MustCompile(`\bPath\s*\(\s*[rfRF]?(["'])(.*?)["']\s*\)\s*\.(write_text|write_bytes|touch|mkdir|open\s*\(\s*["'][^"']*[wax+])`)
+	pythonCopy     = regexp.MustCompile(`\bshutil\.(copy\w*|move)\s*\([^,]+,\s*[rfRF]?(["'])(.*?)["']`)
+	pythonLiterals = regexp.MustCompile(`"((?:[^"\\\n]|\\.)*)"|'((?:[^'\\\n]|\\.)*)'`)
+)
+
+func classifyPython(a *Assessment, code string) {
+	if pythonRmtree.MatchString(code) {
+		a.add(
+			CategoryDestructive,
+			LevelHigh,
+			"removes directories recursively (shutil.rmtree)",
+		)
+	}
+	if pythonRemove.MatchString(code) {
+		a.add(CategoryDestructive, LevelMedium, "deletes files")
+	}
+	if pythonSetuid.MatchString(code) {
+		a.add(CategoryPrivilege, LevelHigh, "changes the process privileges")
+	}
+	for _, m := range pythonNetwork.FindAllStringSubmatch(code, -1) {
+		a.add(
+			CategoryNetwork,
+			LevelMedium,
+			"accesses the network ("+m[2]+")",
+		)
+	}
+
+	for _, m := range pythonOpen.FindAllStringSubmatch(code, -1) {
+		checkWrite(a, m[2])
+	}
+	for _, m := range pythonPathW.FindAllStringSubmatch(code, -1) {
+		checkWrite(a, m[2])
+	}
+	for _, m := range pythonCopy.FindAllStringSubmatch(code, -1) {
+		checkWrite(a, m[3])
+	}
+
+	if pythonShell.MatchString(code) {
+		a.add(CategoryShell, LevelMedium, "runs shell commands")
+
+		// The commands are usually string literals, either a whole command
+		// or its arguments: analyse the literals of each line as shell
+		var commands []string
+		for _, line := range strings.Split(code, "\n") {
+			var args []string
+			for _, m := range pythonLiterals.FindAllStringSubmatch(line, -1) {
+				args = append(args, m[1]+m[2])
+			}
+			commands = append(commands, strings.Join(args, " "))
+		}
+		classifyShell(a, strings.Join(commands, "\n"))
+	}
+}
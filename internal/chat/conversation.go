@@ -26,6 +26,10 @@ type Conversation interface {
 	// WithPrompt attaches a prompt to the conversation.
 	WithPrompt(prompt prompts.Prompt)
 
+	// GetPrompt returns the ID and the version of the attached prompt,
+	// empty when there is none.
+	GetPrompt() (string, string)
+
 	// Reset clears the conversation but retains system messages.
 	Reset() (Conversation, error)
 
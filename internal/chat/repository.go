@@ -6,6 +6,7 @@ package chat
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -24,6 +25,7 @@ type Repository interface {
 	DeleteConversation(id string) error
 
 	GetConversations() ([]Conversation, error)
+	SearchConversations(query string, limit int) ([]SearchResult, error)
 
 	Close() error
 }
@@ -79,12 +81,15 @@ func (r *sqliteRepository) SaveConversation(
 	}
 	defer tx.Rollback()
 
-	// Insert or ignore the conversation
-	insertConversation := `INSERT OR IGNORE INTO conversations (id, created_at) VALUES (?, ?)`
+	// Insert the conversation or update its prompt
+	insertConversation := `INSERT INTO conversations (id, created_at, prompt_id, prompt_version) VALUES (?, ?, ?, ?) ON CONFLICT(id) DO UPDATE SET prompt_id = excluded.prompt_id, prompt_version = excluded.prompt_version`
+	promptID, promptVersion := conversation.GetPrompt()
 	_, err = tx.Exec(
 		insertConversation,
 		conversation.GetID(),
 		time.Now().UTC().Format(time.RFC3339),
+		promptID,
+		promptVersion,
 	)
 	if err != nil {
 		return fmt.Errorf("error inserting conversation: %w", err)
@@ -118,12 +123,12 @@ func (r *sqliteRepository) SaveConversation(
 func (r *sqliteRepository) LoadConversation(
 	id string,
 ) (Conversation, error) {
-	queryConversation := `SELECT id, created_at FROM conversations WHERE id = ?`
+	queryConversation := `SELECT id, created_at, COALESCE(prompt_id, ''), COALESCE(prompt_version, '') FROM conversations WHERE id = ?`
 	row := r.db.QueryRow(queryConversation, id)
 
-	var convoID string
+	var convoID, promptID, promptVersion string
 	var convoCreatedAt time.Time
-	err := row.Scan(&convoID, &convoCreatedAt)
+	err := row.Scan(&convoID, &convoCreatedAt, &promptID, &promptVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error scanning conversation: %w", err)
 	}
@@ -161,6 +166,9 @@ func (r *sqliteRepository) LoadConversation(
 		id:        convoID,
 		messages:  messages,
 		createdAt: convoCreatedAt.UTC(),
+
+		promptID:      promptID,
+		promptVersion: promptVersion,
 	}, nil
 }
 
@@ -228,3 +236,61 @@ func (r *sqliteRepository) GetConversations() ([]Conversation, error) {
 
 	return convos, nil
 }
+
+// SearchResult is a conversation matching a search, with its first
+// matching message.
+type SearchResult struct {
+	ID        string
+	CreatedAt time.Time
+	Messages  int
+	Match     string
+}
+
+// SearchConversations returns the conversations having at least one message
+// matching the query, most recent first, at most limit of them.
+func (r *sqliteRepository) SearchConversations(
+	query string,
+	limit int,
+) ([]SearchResult, error) {
+	querySearch := `SELECT id, created_at, messages, match FROM (
+		SELECT c.id, c.created_at,
+			(SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id) AS messages,
+			(SELECT m.content FROM messages m WHERE m.conversation_id = c.id AND m.content LIKE ? ESCAPE '\' ORDER BY m.created_at ASC LIMIT 1) AS match
+		FROM conversations c
+	) WHERE match IS NOT NULL ORDER BY created_at DESC LIMIT ?`
+	rows, err := r.db.Query(querySearch, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("error searching conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		err := rows.Scan(
+			&result.ID,
+			&result.CreatedAt,
+			&result.Messages,
+			&result.Match,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning conversation: %w", err)
+		}
+		result.CreatedAt = result.CreatedAt.UTC()
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return results, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"%", `\%`,
+		"_", `\_`,
+	).Replace(s)
+}
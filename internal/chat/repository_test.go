@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearchConversations(t *testing.T) {
+	t.Parallel()
+
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "nomi.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	conversations := []struct {
+		id       string
+		messages []string
+	}{
+		{id: "old", messages: []string{"Deploy the service", "The deploy failed"}},
+		{id: "new", messages: []string{"Hello", "Deploy with 100% uptime?", "Thanks"}},
+		{id: "other", messages: []string{"Unrelated_question"}},
+	}
+	for i, c := range conversations {
+		convo := &stackedConversation{repo: repo, id: c.id}
+		for j, content := range c.messages {
+			msg := NewMessage(RoleUser, content)
+			msg.CreatedAt = start.Add(time.Duration(j) * time.Minute)
+			convo.messages = append(convo.messages, msg)
+		}
+		if err := repo.SaveConversation(convo); err != nil {
+			t.Fatalf("SaveConversation() error = %v", err)
+		}
+
+		// Order the conversations by creation, saved in the same second
+		_, err := repo.(*sqliteRepository).db.Exec(
+			"UPDATE conversations SET created_at = ? WHERE id = ?",
+			start.Add(time.Duration(i)*time.Hour).Format(time.RFC3339),
+			c.id,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		limit    int
+		expected []SearchResult
+	}{
+		{
+			query: "DEPLOY",
+			limit: 10,
+			expected: []SearchResult{
+				{ID: "new", CreatedAt: start.Add(time.Hour), Messages: 3, Match: "Deploy with 100% uptime?"},
+				{ID: "old", CreatedAt: start, Messages: 2, Match: "Deploy the service"},
+			},
+		},
+		{
+			query: "deploy",
+			limit: 1,
+			expected: []SearchResult{
+				{ID: "new", CreatedAt: start.Add(time.Hour), Messages: 3, Match: "Deploy with 100% uptime?"},
+			},
+		},
+		{
+			query: "failed",
+			limit: 10,
+			expected: []SearchResult{
+				{ID: "old", CreatedAt: start, Messages: 2, Match: "The deploy failed"},
+			},
+		},
+		// The wildcards of LIKE are matched literally
+		{
+			query: "%",
+			limit: 10,
+			expected: []SearchResult{
+				{ID: "new", CreatedAt: start.Add(time.Hour), Messages: 3, Match: "Deploy with 100% uptime?"},
+			},
+		},
+		{
+			query: "_",
+			limit: 10,
+			expected: []SearchResult{
+				{ID: "other", CreatedAt: start.Add(2 * time.Hour), Messages: 1, Match: "Unrelated_question"},
+			},
+		},
+		{query: "d_ploy", limit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			results, err := repo.SearchConversations(tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("SearchConversations() error = %v", err)
+			}
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("SearchConversations(%q, %d) = %+v, want %+v", tt.query, tt.limit, results, tt.expected)
+			}
+		})
+	}
+}
@@ -14,6 +14,12 @@ type stackedConversation struct {
 	id        string
 	messages  []Message
 	createdAt time.Time
+
+	promptID      string
+	promptVersion string
+	// promptMessages is the number of messages set by the prompt, kept
+	// by Reset with the examples
+	promptMessages int
 }
 
 // #region Getters
@@ -29,6 +35,10 @@ func (c *stackedConversation) GetMessages() []Message {
 	return c.messages
 }
 
+func (c *stackedConversation) GetPrompt() (string, string) {
+	return c.promptID, c.promptVersion
+}
+
 // #endregion
 
 func (c *stackedConversation) AddMessage(message Message) {
@@ -49,19 +59,11 @@ func (c *stackedConversation) RemoveMessage(id uuid.UUID) {
 }
 
 func (c *stackedConversation) WithPrompt(prompt prompts.Prompt) {
-	if prompt.Settings.SystemPrompt != "" {
-		c.messages = append(c.messages, NewMessage(
-			RoleSystem,
-			prompt.Settings.SystemPrompt,
-		))
-	}
+	c.promptID = prompt.ID
+	c.promptVersion = prompt.Metadata.Version
 
-	if prompt.Settings.PrePrompt != nil && *prompt.Settings.PrePrompt != "" {
-		c.messages = append(c.messages, NewMessage(
-			RoleSystem,
-			*prompt.Settings.PrePrompt,
-		))
-	}
+	c.messages = append(c.messages, PromptMessages(prompt)...)
+	c.promptMessages = len(c.messages)
 }
 
 // TODO(nullswan): Conversation should remain immutable
@@ -73,9 +75,9 @@ func (c *stackedConversation) Reset() (Conversation, error) {
 
 	conversation := NewStackedConversation(c.repo)
 
-	// Copy system messages
-	for _, message := range c.messages {
-		if message.Role != RoleSystem {
+	// Copy system messages and the examples of the prompt
+	for i, message := range c.messages {
+		if message.Role != RoleSystem && i >= c.promptMessages {
 			break
 		}
 		conversation.AddMessage(
@@ -101,10 +103,43 @@ func (c *stackedConversation) Clean() (Conversation, error) {
 	c.createdAt = conversation.GetCreatedAt()
 	c.id = conversation.GetID()
 	c.messages = conversation.GetMessages()
+	c.promptID = ""
+	c.promptVersion = ""
+	c.promptMessages = 0
 
 	return c, nil
 }
 
+// PromptMessages returns the messages starting a conversation with the
+// prompt: its system prompt, its pre-prompt, and its examples as user and
+// assistant messages.
+func PromptMessages(prompt prompts.Prompt) []Message {
+	var messages []Message
+	if prompt.Settings.SystemPrompt != "" {
+		messages = append(messages, NewMessage(
+			RoleSystem,
+			prompt.Settings.SystemPrompt,
+		))
+	}
+
+	if prompt.Settings.PrePrompt != nil && *prompt.Settings.PrePrompt != "" {
+		messages = append(messages, NewMessage(
+			RoleSystem,
+			*prompt.Settings.PrePrompt,
+		))
+	}
+
+	for _, example := range prompt.Settings.Examples {
+		messages = append(
+			messages,
+			NewMessage(RoleUser, example.User),
+			NewMessage(RoleAssistant, example.Assistant),
+		)
+	}
+
+	return messages
+}
+
 func NewStackedConversation(
 	repo Repository,
 ) Conversation {
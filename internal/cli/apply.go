@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/diff"
+	"github.com/nullswan/nomi/internal/patch"
+	"github.com/nullswan/nomi/internal/term"
+)
+
+// diffContext is the number of unchanged lines shown around the changes.
+const diffContext = 3
+
+// lastApply is the backup of the last /apply, restored by /revert.
+var lastApply *patch.Backup
+
+// applyEdits applies the file edits of the last answer to the working
+// directory, after showing them and asking for confirmation.
+func applyEdits(conversation chat.Conversation) {
+	message, ok := lastAssistantMessage(conversation)
+	if !ok {
+		fmt.Println("No answer to apply.")
+		return
+	}
+
+	edits := patch.Parse(message)
+	if len(edits) == 0 {
+		fmt.Println(
+			"No file edits found in the last answer, expected unified diffs or code blocks with a path.",
+		)
+		return
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Println("Error getting working directory:", err)
+		return
+	}
+
+	changes, err := patch.Plan(wd, edits)
+	if err != nil {
+		fmt.Println("Error preparing edits:", err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("The files are already up to date.")
+		return
+	}
+
+	for _, c := range changes {
+		switch {
+		case c.Delete:
+			fmt.Println(term.ColorYellow + c.Path + " (deleted)" + term.ColorDefault)
+		case !c.Exists:
+			fmt.Println(term.ColorYellow + c.Path + " (new file)" + term.ColorDefault)
+		default:
+			fmt.Println(term.ColorYellow + c.Path + term.ColorDefault)
+		}
+		fmt.Print(term.FormatHunks(diff.Hunks(c.Diff(), diffContext)))
+	}
+
+	if !term.PromptForBool(fmt.Sprintf("Apply changes to %d file(s)?", len(changes)), true) {
+		fmt.Println("Changes discarded.")
+		return
+	}
+
+	dir := filepath.Join(
+		config.GetBackupDirectory(),
+		time.Now().Format("20060102-150405.000000"),
+	)
+	backup, err := patch.Apply(changes, dir)
+	if err != nil {
+		fmt.Println("Error applying edits:", err)
+		return
+	}
+	lastApply = backup
+
+	fmt.Printf("Applied changes to %d file(s), /revert to undo.\n", len(changes))
+}
+
+// revertEdits restores the files changed by the last /apply.
+func revertEdits() {
+	if lastApply == nil {
+		fmt.Println("Nothing to revert.")
+		return
+	}
+
+	if err := lastApply.Revert(); err != nil {
+		fmt.Println("Error reverting edits:", err)
+		return
+	}
+
+	fmt.Printf("Reverted changes to %d file(s).\n", len(lastApply.Files()))
+	lastApply = nil
+}
+
+func lastAssistantMessage(conversation chat.Conversation) (string, bool) {
+	messages := conversation.GetMessages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == chat.RoleAssistant {
+			return messages[i].Content, true
+		}
+	}
+	return "", false
+}
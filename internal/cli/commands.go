@@ -45,6 +45,10 @@ func HandleCommands(text string, conversation chat.Conversation) string {
 			}
 
 			processLocalResource(conversation, args[1])
+		case strings.HasPrefix(line, "/apply"):
+			applyEdits(conversation)
+		case strings.HasPrefix(line, "/revert"):
+			revertEdits()
 		case strings.HasPrefix(line, "/exit") || strings.HasPrefix(line, "/quit"):
 			fmt.Println("Exiting...")
 			os.Exit(0)
@@ -64,6 +68,10 @@ func printHelp() {
 	fmt.Println(
 		"  /add <file>  Add a file or directory to the conversation",
 	)
+	fmt.Println(
+		"  /apply       Apply the file edits of the last answer",
+	)
+	fmt.Println("  /revert      Revert the last /apply")
 	fmt.Println("  /exit        Exit the application")
 	fmt.Println()
 	fmt.Println("Use triple quotes (\"\"\") to enter multi-line text.")
@@ -19,12 +19,13 @@ func GenerateCompletion(
 	conversation chat.Conversation,
 	renderer *term.Renderer,
 	textToTextBackend baseprovider.TextToTextProvider,
+	opts completion.RequestOptions,
 ) (string, error) {
 	outCh := make(chan completion.Completion)
 
 	go func() {
 		defer close(outCh)
-		if err := textToTextBackend.GenerateCompletion(ctx, conversation.GetMessages(), outCh); err != nil {
+		if err := textToTextBackend.GenerateCompletion(ctx, conversation.GetMessages(), opts, outCh); err != nil {
 			if strings.Contains(err.Error(), "context canceled") {
 				return
 			}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+// InitInterpreter applies the interpreter configuration to the executors.
+func InitInterpreter(cfg config.InterpreterConfig) error {
+	for language, seconds := range cfg.Timeouts {
+		code.SetTimeout(language, time.Duration(seconds)*time.Second)
+	}
+	code.SetSQLDatabase(cfg.SQLDatabase)
+
+	return initSandbox(cfg.Sandbox)
+}
+
+// initSandbox runs the configured executors inside the sandbox.
+func initSandbox(cfg config.SandboxConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	sb, err := sandbox.New(sandbox.Options{
+		Backend: sandbox.Backend(cfg.Backend),
+		Network: cfg.Network,
+		CPUTime: time.Duration(cfg.CPUTime) * time.Second,
+		Memory:  int64(cfg.Memory) * 1024 * 1024,
+		Output:  int64(cfg.OutputSize) * 1024,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating sandbox: %w", err)
+	}
+
+	// auto falls back to the limits only, do not let the user believe the
+	// code is isolated
+	if sb.Backend() == sandbox.BackendNone &&
+		sandbox.Backend(cfg.Backend) != sandbox.BackendNone {
+		fmt.Fprintln(
+			os.Stderr,
+			"Warning: neither bubblewrap nor user namespaces are available, generated code runs without filesystem and network isolation",
+		)
+	}
+
+	code.UseSandbox(sb, cfg.Exclude...)
+
+	return nil
+}
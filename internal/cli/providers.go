@@ -14,8 +14,22 @@ func InitTextProviders(
 	targetModel string,
 	reasoning bool,
 ) (baseprovider.TextToTextProvider, error) {
-	provider := providers.CheckProvider()
+	return InitProviderTextProviders(
+		logger,
+		providers.CheckProvider(),
+		targetModel,
+		reasoning,
+	)
+}
 
+// InitProviderTextProviders initializes the text-to-text provider of the
+// given provider.
+func InitProviderTextProviders(
+	logger *logger.Logger,
+	provider providers.AIProvider,
+	targetModel string,
+	reasoning bool,
+) (baseprovider.TextToTextProvider, error) {
 	var textToTextBackend baseprovider.TextToTextProvider
 	if reasoning {
 		var err error
@@ -11,6 +11,7 @@ import (
 	"github.com/nullswan/nomi/internal/config"
 	"github.com/nullswan/nomi/internal/logger"
 	"github.com/nullswan/nomi/internal/transcription"
+	"github.com/nullswan/nomi/internal/transcription/languages"
 )
 
 // InitTranscriptionServer initializes the Transcription Server with predefined buffer settings.
@@ -31,7 +32,7 @@ func InitTranscriptionServer(
 		log,
 	)
 	if language != "" {
-		lang, err := transcription.LoadLangFromValue(language)
+		lang, err := languages.LoadLangFromValue(language)
 		if err != nil {
 			return nil, fmt.Errorf("invalid language code: %w", err)
 		}
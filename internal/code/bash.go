@@ -1,33 +1,29 @@
 package code
 
 import (
-	"os/exec"
-	"strings"
+	"context"
+
+	"github.com/nullswan/nomi/internal/sandbox"
 )
 
-type BashExecutor struct{}
+type BashExecutor struct {
+	sandbox *sandbox.Sandbox
+}
 
-func (be *BashExecutor) Execute(code string) ExecutionResult {
-	cmd := exec.Command("bash", "-c", code)
-	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+func (be *BashExecutor) setSandbox(sb *sandbox.Sandbox) {
+	be.sandbox = sb
+}
 
-	err := cmd.Run()
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			exitCode = 1
-		}
+func (be *BashExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	if be.sandbox != nil {
+		return runSandboxed(ctx, be.sandbox, opts, "bash", "-c", code)
 	}
 
-	return ExecutionResult{
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		ExitCode: exitCode,
-	}
+	return runCommand(ctx, opts, "bash", "-c", code)
 }
 
 func initBashExecutor() {
@@ -1,28 +1,86 @@
 package code
 
 import (
-	"runtime"
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds the execution of the languages without a timeout.
+const DefaultTimeout = 2 * time.Minute
+
+const (
+	exitCodeTimeout   = 124
+	exitCodeCancelled = 130
 )
 
 var (
 	executors                = make(map[string]Executor)
 	onceExecutorRegistration sync.Once
+
+	timeoutsMu sync.RWMutex
+	timeouts   = make(map[string]time.Duration)
 )
 
 func registerExecutor(language string, executor Executor) {
 	executors[language] = executor
 }
 
-func ExecuteCodeBlock(block CodeBlock) ExecutionResult {
+func registerExecutors() {
 	onceExecutorRegistration.Do(
 		func() {
 			initBashExecutor()
+			initShExecutor()
 			initPowerShellExecutor()
 			initPythonExecutor()
 			initOsascriptExecutor()
+			initJavaScriptExecutor()
+			initRubyExecutor()
+			initGoExecutor()
+			initSQLExecutor()
 		},
 	)
+}
+
+// SetTimeout sets the execution timeout of a language, 0 restores the
+// default timeout.
+func SetTimeout(language string, timeout time.Duration) {
+	language = NormalizeLanguage(language)
+
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+
+	if timeout <= 0 {
+		delete(timeouts, language)
+		return
+	}
+	timeouts[language] = timeout
+}
+
+func getTimeout(language string, opts ExecuteOptions) time.Duration {
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+
+	if timeout, ok := timeouts[language]; ok {
+		return timeout
+	}
+	return DefaultTimeout
+}
+
+func ExecuteCodeBlock(
+	ctx context.Context,
+	block CodeBlock,
+	opts ExecuteOptions,
+) ExecutionResult {
+	registerExecutors()
+
+	block.Language = NormalizeLanguage(block.Language)
 
 	executor, ok := executors[block.Language]
 	if !ok {
@@ -32,22 +90,45 @@ func ExecuteCodeBlock(block CodeBlock) ExecutionResult {
 		}
 	}
 
-	if block.Language == "osascript" && runtime.GOOS != "darwin" {
+	if reason := unavailable(block.Language); reason != "" {
 		return ExecutionResult{
-			Stderr:   "Osascript is only supported on macOS",
+			Stderr:   reason,
 			ExitCode: 1,
 		}
 	}
 
-	if block.Language == "powershell" && runtime.GOOS != "windows" {
-		return ExecutionResult{
-			Stderr:   "Powershell is only supported on Windows",
-			ExitCode: 1,
+	if opts.Sessions != nil {
+		if session, ok := opts.Sessions.Get(block.Language); ok {
+			executor = session
 		}
 	}
 
-	r := executor.Execute(block.Code)
+	timeout := getTimeout(block.Language, opts)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	r := executor.Execute(execCtx, block.Code, opts)
 	r.Block = block
 
+	switch {
+	case ctx.Err() != nil:
+		r.ExitCode = exitCodeCancelled
+		r.Stderr = appendLine(r.Stderr, "Execution cancelled")
+	case errors.Is(execCtx.Err(), context.DeadlineExceeded):
+		r.TimedOut = true
+		r.ExitCode = exitCodeTimeout
+		r.Stderr = appendLine(
+			r.Stderr,
+			fmt.Sprintf("Execution timed out after %s", timeout),
+		)
+	}
+
 	return r
 }
+
+func appendLine(s, line string) string {
+	if s == "" || s[len(s)-1] == '\n' {
+		return s + line
+	}
+	return s + "\n" + line
+}
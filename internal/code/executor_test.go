@@ -1,10 +1,12 @@
 package code
 
 import (
+	"context"
 	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 type MockExecutor struct {
@@ -13,7 +15,11 @@ type MockExecutor struct {
 	code   int
 }
 
-func (m *MockExecutor) Execute(_ string) ExecutionResult {
+func (m *MockExecutor) Execute(
+	_ context.Context,
+	_ string,
+	_ ExecuteOptions,
+) ExecutionResult {
 	return ExecutionResult{
 		Stdout:   m.output,
 		Stderr:   m.err,
@@ -46,6 +52,7 @@ func TestExecuteCodeBlock(t *testing.T) {
 				Stdout:   "Mock output",
 				Stderr:   "",
 				ExitCode: 0,
+				Block:    CodeBlock{Language: "mock", Code: "test code"},
 			},
 		},
 		{
@@ -55,6 +62,7 @@ func TestExecuteCodeBlock(t *testing.T) {
 				Stdout:   "",
 				Stderr:   "Mock error",
 				ExitCode: 1,
+				Block:    CodeBlock{Language: "error", Code: "test code"},
 			},
 		},
 		{
@@ -72,7 +80,11 @@ func TestExecuteCodeBlock(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := ExecuteCodeBlock(tt.block)
+			result := ExecuteCodeBlock(
+				context.Background(),
+				tt.block,
+				ExecuteOptions{},
+			)
 			if !reflect.DeepEqual(result, tt.expected) {
 				t.Errorf(
 					"ExecuteCodeBlock() = %v, want %v",
@@ -93,7 +105,11 @@ func TestOsascriptExecution(t *testing.T) {
 	)
 
 	block := CodeBlock{Language: "osascript", Code: "test code"}
-	result := ExecuteCodeBlock(block)
+	result := ExecuteCodeBlock(
+		context.Background(),
+		block,
+		ExecuteOptions{},
+	)
 
 	if runtime.GOOS == "darwin" {
 		if result.Stdout != "Osascript output" {
@@ -105,3 +121,137 @@ func TestOsascriptExecution(t *testing.T) {
 		}
 	}
 }
+
+type blockingExecutor struct{}
+
+func (b *blockingExecutor) Execute(
+	ctx context.Context,
+	_ string,
+	_ ExecuteOptions,
+) ExecutionResult {
+	<-ctx.Done()
+	return ExecutionResult{ExitCode: -1}
+}
+
+func TestExecuteCodeBlockTimeout(t *testing.T) {
+	t.Parallel()
+
+	registerExecutor("blocking", &blockingExecutor{})
+	block := CodeBlock{Language: "blocking"}
+
+	result := ExecuteCodeBlock(
+		context.Background(),
+		block,
+		ExecuteOptions{Timeout: 10 * time.Millisecond},
+	)
+	if !result.TimedOut || result.ExitCode != exitCodeTimeout {
+		t.Errorf("Expected a timeout, got %+v", result)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result = ExecuteCodeBlock(ctx, block, ExecuteOptions{})
+	if result.TimedOut || result.ExitCode != exitCodeCancelled {
+		t.Errorf("Expected a cancellation, got %+v", result)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	var streamed strings.Builder
+	result := runCommand(
+		context.Background(),
+		ExecuteOptions{
+			OnOutput: func(stream Stream, data []byte) {
+				streamed.WriteString(string(stream) + ":" + string(data))
+			},
+		},
+		"sh", "-c", "echo out; echo err >&2; exit 3",
+	)
+
+	if result.Stdout != "out\n" || result.Stderr != "err\n" ||
+		result.ExitCode != 3 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	if !strings.Contains(streamed.String(), "stdout:out\n") ||
+		!strings.Contains(streamed.String(), "stderr:err\n") {
+		t.Errorf("Output was not streamed: %q", streamed.String())
+	}
+
+	// Background children are killed with the process group
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	runCommand(ctx, ExecuteOptions{}, "sh", "-c", "sleep 30 & sleep 30; wait")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Process group was not killed, took %s", elapsed)
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"py":         "python",
+		"Python3":    "python",
+		"shell":      "bash",
+		"sh":         "sh",
+		"js":         "javascript",
+		"node":       "javascript",
+		"golang":     "go",
+		" SQLite ":   "sql",
+		"ruby":       "ruby",
+		"powershell": "powershell",
+	}
+
+	for input, expected := range tests {
+		if result := NormalizeLanguage(input); result != expected {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", input, result, expected)
+		}
+	}
+}
+
+func TestGoProgram(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "Complete program",
+			code:     "package main\n\nfunc main() {}\n",
+			expected: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:     "Missing package clause",
+			code:     "func main() {}",
+			expected: "package main\n\nfunc main() {}",
+		},
+		{
+			name: "Statements",
+			code: "import (\n\t\"fmt\"\n)\n\nfmt.Println(1)",
+			expected: "package main\n\nimport (\n\t\"fmt\"\n)\n\n" +
+				"func main() {\nfmt.Println(1)\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := goProgram(tt.code); result != tt.expected {
+				t.Errorf("goProgram() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -2,7 +2,26 @@ package code
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/nullswan/nomi/internal/proc"
+)
+
+// MaxOutputSize is the size of each output kept for the LLM, the head and
+// the tail of longer outputs are preserved.
+const MaxOutputSize = 8 * 1024
+
+// resultSeparator separates the results formatted for the LLM.
+var resultSeparator = "\n\n" + strings.Repeat("-", 40) + "\n\n"
+
+var (
+	resultHeader = regexp.MustCompile(
+		`(?m)^--- Execution Result (\d+)(?: \(([^)]*)\))? ---$`,
+	)
+	resultExitCode = regexp.MustCompile(`(?:^|\n\n)Exit Code: (-?\d+)$`)
 )
 
 func FormatExecutionResultForLLM(results []ExecutionResult) string {
@@ -11,22 +30,27 @@ func FormatExecutionResultForLLM(results []ExecutionResult) string {
 	for i, r := range results {
 		var sectionParts []string
 
-		sectionParts = append(
-			sectionParts,
-			fmt.Sprintf("--- Execution Result %d ---", i+1),
-		)
+		header := fmt.Sprintf("--- Execution Result %d ---", i+1)
+		if r.Block.Language != "" {
+			header = fmt.Sprintf(
+				"--- Execution Result %d (%s) ---",
+				i+1,
+				r.Block.Language,
+			)
+		}
+		sectionParts = append(sectionParts, header)
 
 		if r.Stderr != "" {
 			sectionParts = append(
 				sectionParts,
-				"Error:\n"+r.Stderr,
+				"Error:\n"+TruncateOutput(r.Stderr, MaxOutputSize),
 			)
 		}
 
 		if r.Stdout != "" {
 			sectionParts = append(
 				sectionParts,
-				"Output:\n"+r.Stdout,
+				"Output:\n"+TruncateOutput(r.Stdout, MaxOutputSize),
 			)
 		}
 
@@ -42,5 +66,82 @@ func FormatExecutionResultForLLM(results []ExecutionResult) string {
 		}
 	}
 
-	return strings.Join(sections, "\n\n"+strings.Repeat("-", 40)+"\n\n")
+	return strings.Join(sections, resultSeparator)
+}
+
+// ParseExecutionResults reverses FormatExecutionResultForLLM, the results
+// without output, which are left out of the text, are returned empty.
+// It reports false when the text is not formatted execution results.
+func ParseExecutionResults(text string) ([]ExecutionResult, bool) {
+	var headers [][]int
+	for _, loc := range resultHeader.FindAllStringSubmatchIndex(text, -1) {
+		// Only the headers after a separator, not the ones in the outputs
+		if loc[0] == 0 || strings.HasSuffix(text[:loc[0]], resultSeparator) {
+			headers = append(headers, loc)
+		}
+	}
+	if len(headers) == 0 || headers[0][0] != 0 {
+		return nil, false
+	}
+
+	var results []ExecutionResult
+	for i, loc := range headers {
+		end := len(text)
+		if i+1 < len(headers) {
+			end = headers[i+1][0] - len(resultSeparator)
+		}
+
+		n, err := strconv.Atoi(text[loc[2]:loc[3]])
+		if err != nil || n < 1 {
+			return nil, false
+		}
+		for len(results) < n {
+			results = append(results, ExecutionResult{})
+		}
+
+		r := parseExecutionResult(strings.TrimPrefix(text[loc[1]:end], "\n\n"))
+		if loc[4] >= 0 {
+			r.Block.Language = text[loc[4]:loc[5]]
+		}
+		results[n-1] = r
+	}
+
+	return results, true
+}
+
+func parseExecutionResult(body string) ExecutionResult {
+	var r ExecutionResult
+
+	if m := resultExitCode.FindStringSubmatchIndex(body); m != nil {
+		r.ExitCode, _ = strconv.Atoi(body[m[2]:m[3]])
+		body = body[:m[0]]
+	}
+
+	if rest, ok := strings.CutPrefix(body, "Error:\n"); ok {
+		r.Stderr, r.Stdout, _ = strings.Cut(rest, "\n\nOutput:\n")
+		return r
+	}
+	r.Stdout = strings.TrimPrefix(body, "Output:\n")
+
+	return r
+}
+
+// TruncateOutput keeps the head and the tail of the output when it exceeds
+// limit bytes, the middle is replaced by a marker.
+func TruncateOutput(output string, limit int) string {
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+
+	head := limit / 2
+	for head > 0 && !utf8.RuneStart(output[head]) {
+		head--
+	}
+
+	tail := len(output) - (limit - head)
+	for tail < len(output) && !utf8.RuneStart(output[tail]) {
+		tail++
+	}
+
+	return output[:head] + proc.TruncationMarker(tail-head) + output[tail:]
 }
@@ -1,6 +1,8 @@
 package code
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -90,6 +92,66 @@ Third output`,
 					tt.expected,
 				)
 			}
+
+			parsed, ok := ParseExecutionResults(result)
+			if ok != (len(tt.results) > 0) ||
+				(ok && !reflect.DeepEqual(parsed, tt.results)) {
+				t.Errorf(
+					"ParseExecutionResults() = %+v, %v, want %+v",
+					parsed,
+					ok,
+					tt.results,
+				)
+			}
+		})
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		output   string
+		limit    int
+		expected string
+	}{
+		{
+			name:     "Short output",
+			output:   "hello",
+			limit:    10,
+			expected: "hello",
+		},
+		{
+			name:     "Head and tail",
+			output:   "0123456789",
+			limit:    4,
+			expected: "01\n... [6 bytes truncated] ...\n89",
+		},
+		{
+			name:     "Rune boundaries",
+			output:   "ééééé",
+			limit:    5,
+			expected: "é\n... [6 bytes truncated] ...\né",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := TruncateOutput(tt.output, tt.limit)
+			if result != tt.expected {
+				t.Errorf("TruncateOutput() = %q, want %q", result, tt.expected)
+			}
 		})
 	}
+
+	long := strings.Repeat("a", MaxOutputSize*2)
+	formatted := FormatExecutionResultForLLM(
+		[]ExecutionResult{{Stdout: long}},
+	)
+	if len(formatted) > MaxOutputSize+200 {
+		t.Errorf("FormatExecutionResultForLLM() did not truncate the output")
+	}
 }
@@ -0,0 +1,114 @@
+package code
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+// goModule is the go.mod of the temporary module running the code.
+const goModule = "module snippet\n\ngo 1.21\n"
+
+// goEnv isolates the temporary module from the workspace of the user.
+var goEnv = []string{"GOWORK=off", "GOFLAGS=-mod=mod"}
+
+// goScript writes the module in the sandbox working directory and runs it.
+// The working directory is the temporary directory of the sandbox, where
+// go ignores go.mod files, hence the subdirectory.
+const goScript = `mkdir snippet && cd snippet &&
+printf '%s' "$1" > main.go &&
+printf '%s' "$2" > go.mod &&
+exec go run .`
+
+type GoExecutor struct {
+	sandbox *sandbox.Sandbox
+}
+
+func (ge *GoExecutor) setSandbox(sb *sandbox.Sandbox) {
+	ge.sandbox = sb
+}
+
+func (ge *GoExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	program := goProgram(code)
+
+	if ge.sandbox != nil {
+		args := append(
+			slices.Clone(goEnv),
+			"sh", "-c", goScript, "sh", program, goModule,
+		)
+		return runSandboxed(ctx, ge.sandbox, opts, "env", args...)
+	}
+
+	dir, err := os.MkdirTemp("", "nomi-go-")
+	if err != nil {
+		return ExecutionResult{
+			Stderr:   fmt.Sprintf("error creating module directory: %v", err),
+			ExitCode: 1,
+		}
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{"main.go": program, "go.mod": goModule}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		if err != nil {
+			return ExecutionResult{
+				Stderr:   fmt.Sprintf("error writing %s: %v", name, err),
+				ExitCode: 1,
+			}
+		}
+	}
+
+	return runCommandIn(ctx, opts, dir, goEnv, "go", "run", ".")
+}
+
+// goProgram completes a snippet into a main package: the package clause is
+// added when missing, and the statements are wrapped in a main function
+// when there is none.
+func goProgram(code string) string {
+	if strings.HasPrefix(strings.TrimSpace(code), "package ") {
+		return code
+	}
+
+	if strings.Contains(code, "func main()") {
+		return "package main\n\n" + code
+	}
+
+	// Keep the imports at the top level
+	lines := strings.Split(code, "\n")
+	header := 0
+	inImports := false
+scan:
+	for ; header < len(lines); header++ {
+		line := strings.TrimSpace(lines[header])
+		switch {
+		case inImports:
+			inImports = line != ")"
+		case line == "" || strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "import ("):
+			inImports = true
+		case strings.HasPrefix(line, "import "):
+		default:
+			break scan
+		}
+	}
+
+	return "package main\n\n" +
+		strings.Join(lines[:header], "\n") +
+		"\nfunc main() {\n" +
+		strings.Join(lines[header:], "\n") +
+		"\n}\n"
+}
+
+func initGoExecutor() {
+	registerExecutor("go", &GoExecutor{})
+}
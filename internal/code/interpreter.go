@@ -1,12 +1,50 @@
 package code
 
-func InterpretCodeBlocks(input string) []ExecutionResult {
+import "context"
+
+// InterpretCodeBlocks runs the code blocks of the input in order and returns
+// a result per block.
+func InterpretCodeBlocks(
+	ctx context.Context,
+	input string,
+	opts ExecuteOptions,
+) []ExecutionResult {
 	blocks := ParseCodeBlocks(input)
-	results := make([]ExecutionResult, len(blocks))
+	if !opts.AllLanguages {
+		blocks = firstLanguageBlocks(blocks)
+	}
 
+	return ExecuteCodeBlocks(ctx, blocks, opts)
+}
+
+// ExecuteCodeBlocks runs the code blocks in order and returns a result per
+// block.
+func ExecuteCodeBlocks(
+	ctx context.Context,
+	blocks []CodeBlock,
+	opts ExecuteOptions,
+) []ExecutionResult {
+	results := make([]ExecutionResult, len(blocks))
 	for i, block := range blocks {
-		results[i] = ExecuteCodeBlock(block)
+		results[i] = ExecuteCodeBlock(ctx, block, opts)
 	}
 
 	return results
 }
+
+// firstLanguageBlocks keeps the blocks in the language of the first one.
+func firstLanguageBlocks(blocks []CodeBlock) []CodeBlock {
+	if len(blocks) == 0 {
+		return blocks
+	}
+
+	language := NormalizeLanguage(blocks[0].Language)
+	filtered := make([]CodeBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if NormalizeLanguage(block.Language) == language {
+			filtered = append(filtered, block)
+		}
+	}
+
+	return filtered
+}
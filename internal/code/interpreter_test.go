@@ -1,6 +1,7 @@
 package code
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -8,25 +9,80 @@ import (
 func TestInterpretCodeBlocks(t *testing.T) {
 	t.Parallel()
 
-	// Register mock executors
+	// Register mock executors, after the real ones
+	registerExecutors()
 	registerExecutor(
-		"python",
-		&MockExecutor{output: "Python output", err: "", code: 0},
+		"mock-first",
+		&MockExecutor{output: "First output", err: "", code: 0},
 	)
 	registerExecutor(
-		"bash",
-		&MockExecutor{output: "Bash output", err: "", code: 0},
+		"mock-second",
+		&MockExecutor{output: "Second output", err: "", code: 0},
 	)
 
-	input := "```python\nprint('Hello')\n```\n```bash\necho 'World'\n```"
-	expected := []ExecutionResult{
-		{Stdout: "Python output", Stderr: "", ExitCode: 0},
-		{Stdout: "Bash output", Stderr: "", ExitCode: 0},
+	input := "```mock-first\nfirst\n```\n```mock-second\nsecond\n```\n" +
+		"```mock-first\nthird\n```"
+
+	first := ExecutionResult{
+		Stdout: "First output",
+		Block: CodeBlock{
+			Language:  "mock-first",
+			Code:      "first",
+			Info:      "mock-first",
+			StartLine: 1,
+			EndLine:   3,
+		},
+	}
+	second := ExecutionResult{
+		Stdout: "Second output",
+		Block: CodeBlock{
+			Language:  "mock-second",
+			Code:      "second",
+			Info:      "mock-second",
+			StartLine: 4,
+			EndLine:   6,
+		},
+	}
+	third := ExecutionResult{
+		Stdout: "First output",
+		Block: CodeBlock{
+			Language:  "mock-first",
+			Code:      "third",
+			Info:      "mock-first",
+			StartLine: 7,
+			EndLine:   9,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     ExecuteOptions
+		expected []ExecutionResult
+	}{
+		{
+			name:     "First language only",
+			opts:     ExecuteOptions{},
+			expected: []ExecutionResult{first, third},
+		},
+		{
+			name:     "All languages in order",
+			opts:     ExecuteOptions{AllLanguages: true},
+			expected: []ExecutionResult{first, second, third},
+		},
 	}
 
-	results := InterpretCodeBlocks(input)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	if !reflect.DeepEqual(results, expected) {
-		t.Errorf("InterpretCodeBlocks() = %v, want %v", results, expected)
+			results := InterpretCodeBlocks(context.Background(), input, tt.opts)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf(
+					"InterpretCodeBlocks() = %+v, want %+v",
+					results,
+					tt.expected,
+				)
+			}
+		})
 	}
 }
@@ -0,0 +1,31 @@
+package code
+
+import (
+	"context"
+
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+type JavaScriptExecutor struct {
+	sandbox *sandbox.Sandbox
+}
+
+func (je *JavaScriptExecutor) setSandbox(sb *sandbox.Sandbox) {
+	je.sandbox = sb
+}
+
+func (je *JavaScriptExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	if je.sandbox != nil {
+		return runSandboxed(ctx, je.sandbox, opts, "node", "-e", code)
+	}
+
+	return runCommand(ctx, opts, "node", "-e", code)
+}
+
+func initJavaScriptExecutor() {
+	registerExecutor("javascript", &JavaScriptExecutor{})
+}
@@ -0,0 +1,29 @@
+# nomi bash kernel: runs the code sent on stdin in a persistent shell.
+#
+# Request: "<nonce> <length>\n" followed by <length> bytes of code.
+# Response: the output of the code, then "\036<nonce>:<status>\n" on stdout
+# and "\036<nonce>\n" on stderr.
+exec 3<&0 </dev/null
+__nomi_initial=$(compgen -v)
+trap : INT
+
+__nomi_inspect() {
+	pwd
+	local name
+	for name in $(compgen -v); do
+		case "$name" in __nomi_* | BASH_* | FUNCNAME | PIPESTATUS | _) continue ;; esac
+		grep -qx "$name" <<<"$__nomi_initial" || declare -p "$name"
+	done
+	declare -F | grep -v ' __nomi_'
+}
+
+while IFS=' ' read -r -u 3 __nomi_nonce __nomi_length; do
+	__nomi_code=
+	if [ "$__nomi_length" -gt 0 ]; then
+		LC_ALL=C IFS= read -r -d '' -N "$__nomi_length" -u 3 __nomi_code
+	fi
+	eval "$__nomi_code"
+	__nomi_status=$?
+	printf '\036%s:%d\n' "$__nomi_nonce" "$__nomi_status"
+	printf '\036%s\n' "$__nomi_nonce" >&2
+done
@@ -0,0 +1,77 @@
+# nomi python kernel: runs the code sent on stdin in a persistent namespace.
+#
+# Request: "<nonce> <length>\n" followed by <length> bytes of code.
+# Response: the output of the code, then "\x1e<nonce>:<status>\n" on stdout
+# and "\x1e<nonce>\n" on stderr.
+import ast
+import os
+import sys
+import traceback
+
+_proto = os.fdopen(os.dup(0), "rb", buffering=0)
+_devnull = os.open(os.devnull, os.O_RDONLY)
+os.dup2(_devnull, 0)
+sys.stdin = open(os.devnull)
+
+_namespace = {"__name__": "__main__", "__builtins__": __builtins__}
+
+
+def _read_exactly(n):
+    data = b""
+    while len(data) < n:
+        chunk = _proto.read(n - len(data))
+        if not chunk:
+            raise EOFError
+        data += chunk
+    return data
+
+
+def _read_line():
+    line = b""
+    while not line.endswith(b"\n"):
+        c = _proto.read(1)
+        if not c:
+            raise EOFError
+        line += c
+    return line.decode()
+
+
+def _run(code):
+    tree = ast.parse(code, "<nomi>", "exec")
+    # Echo the value of a trailing expression, like the REPL
+    last = None
+    if tree.body and isinstance(tree.body[-1], ast.Expr):
+        last = ast.Expression(tree.body.pop().value)
+    exec(compile(tree, "<nomi>", "exec"), _namespace)
+    if last is not None:
+        value = eval(compile(last, "<nomi>", "eval"), _namespace)
+        if value is not None:
+            print(repr(value))
+
+
+while True:
+    try:
+        nonce, length = _read_line().split()
+        code = _read_exactly(int(length)).decode()
+    except (EOFError, ValueError):
+        break
+
+    status = 0
+    try:
+        _run(code)
+    except SystemExit as e:
+        if isinstance(e.code, int):
+            status = e.code
+        elif e.code is not None:
+            print(e.code, file=sys.stderr)
+            status = 1
+    except BaseException:
+        traceback.print_exc()
+        status = 1
+
+    sys.stdout.flush()
+    sys.stderr.flush()
+    sys.stdout.write("\x1e%s:%d\n" % (nonce, status))
+    sys.stdout.flush()
+    sys.stderr.write("\x1e%s\n" % nonce)
+    sys.stderr.flush()
@@ -0,0 +1,111 @@
+package code
+
+import (
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// aliases maps the common names of the languages to their executor.
+var aliases = map[string]string{
+	"py":          "python",
+	"python3":     "python",
+	"shell":       "bash",
+	"console":     "bash",
+	"zsh":         "bash",
+	"js":          "javascript",
+	"node":        "javascript",
+	"nodejs":      "javascript",
+	"golang":      "go",
+	"rb":          "ruby",
+	"sqlite":      "sql",
+	"sqlite3":     "sql",
+	"ps1":         "powershell",
+	"pwsh":        "powershell",
+	"applescript": "osascript",
+}
+
+// interpreters lists the binaries required by the languages, the first
+// one found is used. Languages executed in-process are not listed.
+var interpreters = map[string][]string{
+	"bash":       {"bash"},
+	"sh":         {"sh"},
+	"python":     {"python3"},
+	"javascript": {"node"},
+	"ruby":       {"ruby"},
+	"go":         {"go"},
+	"powershell": {"powershell", "pwsh"},
+	"osascript":  {"osascript"},
+}
+
+// NormalizeLanguage returns the executor name of a language or alias.
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := aliases[language]; ok {
+		return alias
+	}
+	return language
+}
+
+var (
+	lookupOnce sync.Once
+	installed  map[string]string
+)
+
+// lookupInterpreters detects the interpreters installed on the machine.
+func lookupInterpreters() {
+	lookupOnce.Do(func() {
+		installed = make(map[string]string)
+		for language, binaries := range interpreters {
+			for _, binary := range binaries {
+				if _, err := exec.LookPath(binary); err == nil {
+					installed[language] = binary
+					break
+				}
+			}
+		}
+	})
+}
+
+// interpreterFor returns the installed binary running the language.
+func interpreterFor(language string) string {
+	lookupInterpreters()
+	return installed[language]
+}
+
+// unavailable explains why the language cannot run on this machine, it
+// returns an empty string when it can.
+func unavailable(language string) string {
+	switch {
+	case language == "osascript" && runtime.GOOS != "darwin":
+		return "Osascript is only supported on macOS"
+	case language == "powershell" && runtime.GOOS != "windows":
+		return "Powershell is only supported on Windows"
+	}
+
+	binaries, ok := interpreters[language]
+	if ok && interpreterFor(language) == "" {
+		return "Interpreter not found for " + language + ": " +
+			strings.Join(binaries, " or ") + " is not installed"
+	}
+
+	return ""
+}
+
+// InstalledLanguages lists the supported languages that can run on this
+// machine.
+func InstalledLanguages() []string {
+	registerExecutors()
+
+	var languages []string
+	for language := range executors {
+		if unavailable(language) == "" {
+			languages = append(languages, language)
+		}
+	}
+	sort.Strings(languages)
+
+	return languages
+}
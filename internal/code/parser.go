@@ -1,48 +1,191 @@
 package code
 
 import (
-	"bufio"
 	"strings"
+	"unicode"
 )
 
+// ParseCodeBlocks returns the fenced code blocks of a Markdown document,
+// following CommonMark: fences of three or more backticks or tildes,
+// indented by up to three spaces, closed by a fence of the same character
+// at least as long. Unclosed blocks run to the end of the document.
 func ParseCodeBlocks(input string) []CodeBlock {
-	var blocks []CodeBlock
-	scanner := bufio.NewScanner(strings.NewReader(input))
-	var currentBlock CodeBlock
-	inCodeBlock := false
-	var selectedLang string
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if strings.HasPrefix(line, "```") {
-			if inCodeBlock {
-				if currentBlock.Language == selectedLang {
-					blocks = append(blocks, currentBlock)
-				}
-				currentBlock = CodeBlock{}
-				inCodeBlock = false
-			} else {
-				currentBlock.Language = strings.TrimSpace(strings.TrimPrefix(line, "```"))
-				if selectedLang == "" {
-					selectedLang = currentBlock.Language
-				}
-				inCodeBlock = true
-			}
-		} else if inCodeBlock {
-			if currentBlock.Code == "" {
-				currentBlock.Code = line
-			} else {
-				currentBlock.Code += "\n" + line
+	blocks := []CodeBlock{}
+
+	lines := strings.Split(input, "\n")
+	for i := 0; i < len(lines); i++ {
+		fence, ok := parseOpeningFence(lines[i])
+		if !ok {
+			continue
+		}
+
+		block := CodeBlock{
+			Info:       fence.info,
+			Language:   infoLanguage(fence.info),
+			Attributes: infoAttributes(fence.info),
+			StartLine:  i + 1,
+			EndLine:    len(lines),
+		}
+
+		var content []string
+		j := i + 1
+		for ; j < len(lines); j++ {
+			if fence.closedBy(lines[j]) {
+				block.EndLine = j + 1
+				break
 			}
+			content = append(content, removeIndent(lines[j], fence.indent))
+		}
+
+		block.Code = strings.Join(content, "\n")
+		blocks = append(blocks, block)
+		i = j
+	}
+
+	return blocks
+}
+
+type codeFence struct {
+	char   byte
+	length int
+	indent int
+	info   string
+}
+
+func parseOpeningFence(line string) (codeFence, bool) {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return codeFence{}, false
+	}
+	rest := line[indent:]
+
+	if len(rest) < 3 || (rest[0] != '`' && rest[0] != '~') {
+		return codeFence{}, false
+	}
+
+	f := codeFence{char: rest[0], indent: indent}
+	for f.length < len(rest) && rest[f.length] == f.char {
+		f.length++
+	}
+	if f.length < 3 {
+		return codeFence{}, false
+	}
+
+	f.info = strings.TrimSpace(rest[f.length:])
+	// The info string of a backtick fence cannot contain backticks, it is
+	// an inline code span otherwise
+	if f.char == '`' && strings.ContainsRune(f.info, '`') {
+		return codeFence{}, false
+	}
+
+	return f, true
+}
+
+func (f codeFence) closedBy(line string) bool {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return false
+	}
+	rest := line[indent:]
+
+	length := 0
+	for length < len(rest) && rest[length] == f.char {
+		length++
+	}
+
+	return length >= f.length && strings.TrimSpace(rest[length:]) == ""
+}
+
+// removeIndent removes up to n leading spaces, as the opening fence.
+func removeIndent(line string, n int) string {
+	indent := min(leadingSpaces(line), n)
+	return line[indent:]
+}
+
+func leadingSpaces(line string) int {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+// infoLanguage returns the first word of the info string, also accepting
+// the {.language} and {language} forms.
+func infoLanguage(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	language := fields[0]
+	if strings.HasPrefix(language, "{") {
+		language = strings.TrimLeft(language, "{.")
+		language = strings.TrimRight(language, "}")
+	}
+
+	return language
+}
+
+// infoAttributes parses the key=value pairs following the language, values
+// can be quoted. Words without a value are set to "true".
+func infoAttributes(info string) map[string]string {
+	words := splitInfo(info)
+	if len(words) < 2 {
+		return nil
+	}
+
+	attributes := make(map[string]string)
+	for _, word := range words[1:] {
+		word = strings.Trim(word, "{}")
+		if word == "" {
+			continue
 		}
+
+		key, value, found := strings.Cut(word, "=")
+		if !found {
+			value = "true"
+		}
+		attributes[key] = strings.Trim(value, `"'`)
+	}
+
+	if len(attributes) == 0 {
+		return nil
 	}
 
-	if inCodeBlock {
-		if currentBlock.Language == selectedLang {
-			blocks = append(blocks, currentBlock)
+	return attributes
+}
+
+// splitInfo splits the info string on the spaces outside of the quotes.
+func splitInfo(info string) []string {
+	var (
+		words   []string
+		current strings.Builder
+		quote   rune
+	)
+
+	for _, r := range info {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case unicode.IsSpace(r):
+			if current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
 		}
 	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
 
-	return blocks
+	return words
 }
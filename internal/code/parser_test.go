@@ -14,10 +14,23 @@ func TestParseCodeBlocks(t *testing.T) {
 		expected []CodeBlock
 	}{
 		{
-			name:  "Multiple languages, selects first language (python)",
+			name:  "Multiple languages",
 			input: "```python\nprint('Hello')\n```\n```bash\necho 'World'\n```",
 			expected: []CodeBlock{
-				{Language: "python", Code: "print('Hello')"},
+				{
+					Language:  "python",
+					Code:      "print('Hello')",
+					Info:      "python",
+					StartLine: 1,
+					EndLine:   3,
+				},
+				{
+					Language:  "bash",
+					Code:      "echo 'World'",
+					Info:      "bash",
+					StartLine: 4,
+					EndLine:   6,
+				},
 			},
 		},
 		{
@@ -29,33 +42,98 @@ func TestParseCodeBlocks(t *testing.T) {
 			name:  "Empty code block",
 			input: "```python\n```",
 			expected: []CodeBlock{
-				{Language: "python", Code: ""},
+				{
+					Language:  "python",
+					Code:      "",
+					Info:      "python",
+					StartLine: 1,
+					EndLine:   2,
+				},
 			},
 		},
 		{
 			name:  "Unclosed code block",
 			input: "```python\nprint('Unclosed')",
 			expected: []CodeBlock{
-				{Language: "python", Code: "print('Unclosed')"},
+				{
+					Language:  "python",
+					Code:      "print('Unclosed')",
+					Info:      "python",
+					StartLine: 1,
+					EndLine:   2,
+				},
 			},
 		},
 		{
-			name:  "Multiple languages, selects first language (bash)",
-			input: "```bash\necho 'World'\n```\n```python\nprint('Hello')\n```",
+			name:  "Info string attributes",
+			input: "Save it:\n\n```python title=\"main file.py\" linenos\nprint(1)\n```",
 			expected: []CodeBlock{
-				{Language: "bash", Code: "echo 'World'"},
+				{
+					Language: "python",
+					Code:     "print(1)",
+					Info:     "python title=\"main file.py\" linenos",
+					Attributes: map[string]string{
+						"title":   "main file.py",
+						"linenos": "true",
+					},
+					StartLine: 3,
+					EndLine:   5,
+				},
+			},
+		},
+		{
+			name:  "Longer fence containing a fence",
+			input: "````markdown\n```bash\nls\n```\n````",
+			expected: []CodeBlock{
+				{
+					Language:  "markdown",
+					Code:      "```bash\nls\n```",
+					Info:      "markdown",
+					StartLine: 1,
+					EndLine:   5,
+				},
+			},
+		},
+		{
+			name:  "Tilde fence and indentation",
+			input: "  ~~~ {.sh}\n  echo a\n    echo b\n  ~~~~\n```\nplain\n```",
+			expected: []CodeBlock{
+				{
+					Language:  "sh",
+					Code:      "echo a\n  echo b",
+					Info:      "{.sh}",
+					StartLine: 1,
+					EndLine:   4,
+				},
+				{
+					Code:      "plain",
+					StartLine: 5,
+					EndLine:   7,
+				},
+			},
+		},
+		{
+			name:  "Not a fence",
+			input: "Use ``` code ``` inline\n    ```indented\n``\n~~~ ~~~\n~~~",
+			expected: []CodeBlock{
+				{
+					Language:  "~~~",
+					Code:      "",
+					Info:      "~~~",
+					StartLine: 4,
+					EndLine:   5,
+				},
 			},
 		},
 	}
 
 	for _, tt := range tests {
-		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
 			result := ParseCodeBlocks(tt.input)
 			if !reflect.DeepEqual(result, tt.expected) {
-				t.Errorf("ParseCodeBlocks() = %v, want %v", result, tt.expected)
+				t.Errorf("ParseCodeBlocks() = %+v, want %+v", result, tt.expected)
 			}
 		})
 	}
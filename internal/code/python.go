@@ -1,33 +1,29 @@
 package code
 
 import (
-	"os/exec"
-	"strings"
+	"context"
+
+	"github.com/nullswan/nomi/internal/sandbox"
 )
 
-type PythonExecutor struct{}
+type PythonExecutor struct {
+	sandbox *sandbox.Sandbox
+}
 
-func (pe *PythonExecutor) Execute(code string) ExecutionResult {
-	cmd := exec.Command("python3", "-c", code)
-	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+func (pe *PythonExecutor) setSandbox(sb *sandbox.Sandbox) {
+	pe.sandbox = sb
+}
 
-	err := cmd.Run()
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			exitCode = 1
-		}
+func (pe *PythonExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	if pe.sandbox != nil {
+		return runSandboxed(ctx, pe.sandbox, opts, "python3", "-c", code)
 	}
 
-	return ExecutionResult{
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		ExitCode: exitCode,
-	}
+	return runCommand(ctx, opts, "python3", "-c", code)
 }
 
 func initPythonExecutor() {
@@ -2,7 +2,9 @@ package code
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -18,11 +20,20 @@ import (
 
 // TODO(nullswan): Add sqlc here
 
+var ErrCodeBlockNotFound = errors.New("code block not found")
+
 type Repository interface {
+	// SaveCodeBlock saves the block, under a new ID when empty.
 	SaveCodeBlock(block CodeBlock) error
 	LoadCodeBlock(id string) (CodeBlock, error)
+	DeleteCodeBlock(id string) error
 
 	LoadCodeBlocks() ([]CodeBlock, error)
+	// SearchCodeBlocks returns the blocks matching every word of the query
+	// in their description, code or language.
+	SearchCodeBlocks(query string) ([]CodeBlock, error)
+	// HasCodeBlock reports whether the same code was already saved.
+	HasCodeBlock(language, code string) (bool, error)
 
 	Close() error
 }
@@ -80,10 +91,19 @@ func (r *sqliteRepository) SaveCodeBlock(block CodeBlock) error {
 		INSERT OR REPLACE INTO code_snippets (id, created_at, description, code, language)
 		VALUES (?, ?, ?, ?, ?)
 	`
+	id := block.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	createdAt := block.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+
 	_, err = tx.Exec(
 		insertCodeBlock,
-		uuid.New().String(),
-		time.Now().UTC(),
+		id,
+		createdAt,
 		block.Description,
 		block.Code,
 		block.Language,
@@ -100,24 +120,41 @@ func (r *sqliteRepository) SaveCodeBlock(block CodeBlock) error {
 	return nil
 }
 
-func (r *sqliteRepository) LoadCodeBlock(id string) (CodeBlock, error) {
-	query := `
-		SELECT id, description, code, language
-		FROM code_snippets
-		WHERE id = ?
-	`
-	row := r.db.QueryRow(query, id)
+const selectCodeBlock = `
+	SELECT id, created_at, description, code, language
+	FROM code_snippets
+`
 
-	var block CodeBlock
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCodeBlock(row rowScanner) (CodeBlock, error) {
+	var (
+		block       CodeBlock
+		createdAt   sql.NullTime
+		description sql.NullString
+	)
 	err := row.Scan(
 		&block.ID,
-		&block.Description,
+		&createdAt,
+		&description,
 		&block.Code,
 		&block.Language,
 	)
+	block.CreatedAt = createdAt.Time
+	block.Description = description.String
+
+	return block, err
+}
+
+func (r *sqliteRepository) LoadCodeBlock(id string) (CodeBlock, error) {
+	row := r.db.QueryRow(selectCodeBlock+" WHERE id = ?", id)
+
+	block, err := scanCodeBlock(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return block, fmt.Errorf("code block not found: %w", err)
+			return block, ErrCodeBlockNotFound
 		}
 		return block, fmt.Errorf("error querying code block: %w", err)
 	}
@@ -125,13 +162,69 @@ func (r *sqliteRepository) LoadCodeBlock(id string) (CodeBlock, error) {
 	return block, nil
 }
 
+func (r *sqliteRepository) DeleteCodeBlock(id string) error {
+	res, err := r.db.Exec(`DELETE FROM code_snippets WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting code block: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting code block: %w", err)
+	}
+	if affected == 0 {
+		return ErrCodeBlockNotFound
+	}
+
+	return nil
+}
+
 func (r *sqliteRepository) LoadCodeBlocks() ([]CodeBlock, error) {
-	query := `
-		SELECT id, description, code, language
-		FROM code_snippets
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query)
+	return r.queryCodeBlocks(selectCodeBlock + " ORDER BY created_at DESC")
+}
+
+func (r *sqliteRepository) SearchCodeBlocks(query string) ([]CodeBlock, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	for _, word := range strings.Fields(query) {
+		pattern := "%" + escapeLike(word) + "%"
+		conditions = append(
+			conditions,
+			`(description LIKE ? ESCAPE '\' OR code LIKE ? ESCAPE '\' OR language LIKE ? ESCAPE '\')`,
+		)
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	stmt := selectCodeBlock
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	stmt += " ORDER BY created_at DESC"
+
+	return r.queryCodeBlocks(stmt, args...)
+}
+
+func (r *sqliteRepository) HasCodeBlock(language, code string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM code_snippets WHERE language = ? AND code = ?`,
+		language,
+		code,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error querying code blocks: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *sqliteRepository) queryCodeBlocks(
+	query string,
+	args ...interface{},
+) ([]CodeBlock, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying code blocks: %w", err)
 	}
@@ -139,13 +232,7 @@ func (r *sqliteRepository) LoadCodeBlocks() ([]CodeBlock, error) {
 
 	var blocks []CodeBlock
 	for rows.Next() {
-		var block CodeBlock
-		err := rows.Scan(
-			&block.ID,
-			&block.Description,
-			&block.Code,
-			&block.Language,
-		)
+		block, err := scanCodeBlock(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning code block: %w", err)
 		}
@@ -160,6 +247,11 @@ func (r *sqliteRepository) LoadCodeBlocks() ([]CodeBlock, error) {
 	return blocks, nil
 }
 
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 func (r *sqliteRepository) Close() error {
 	err := r.db.Close()
 	if err != nil {
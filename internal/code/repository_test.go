@@ -0,0 +1,71 @@
+package code
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteRepository(t *testing.T) {
+	t.Parallel()
+
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "nomi.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer repo.Close()
+
+	blocks := []CodeBlock{
+		{ID: "1", Language: "bash", Code: "du -sh *", Description: "Show the size of the files"},
+		{ID: "2", Language: "python", Code: "print(100%7)", Description: "Compute a modulo"},
+	}
+	for _, block := range blocks {
+		if err := repo.SaveCodeBlock(block); err != nil {
+			t.Fatalf("SaveCodeBlock() error = %v", err)
+		}
+	}
+
+	searches := []struct {
+		query string
+		want  []string
+	}{
+		{query: "size", want: []string{"1"}},
+		{query: "PYTHON modulo", want: []string{"2"}},
+		{query: "size modulo", want: nil},
+		{query: "%", want: []string{"2"}},
+		{query: "_", want: nil},
+	}
+	for _, s := range searches {
+		found, err := repo.SearchCodeBlocks(s.query)
+		if err != nil {
+			t.Fatalf("SearchCodeBlocks(%q) error = %v", s.query, err)
+		}
+
+		var ids []string
+		for _, block := range found {
+			ids = append(ids, block.ID)
+		}
+		if len(ids) != len(s.want) || (len(ids) > 0 && ids[0] != s.want[0]) {
+			t.Errorf("SearchCodeBlocks(%q) = %v, want %v", s.query, ids, s.want)
+		}
+	}
+
+	if ok, _ := repo.HasCodeBlock("bash", "du -sh *"); !ok {
+		t.Error("HasCodeBlock() = false, want true")
+	}
+
+	if err := repo.DeleteCodeBlock("1"); err != nil {
+		t.Fatalf("DeleteCodeBlock() error = %v", err)
+	}
+	if _, err := repo.LoadCodeBlock("1"); !errors.Is(err, ErrCodeBlockNotFound) {
+		t.Errorf("LoadCodeBlock() error = %v, want %v", err, ErrCodeBlockNotFound)
+	}
+	if err := repo.DeleteCodeBlock("1"); !errors.Is(err, ErrCodeBlockNotFound) {
+		t.Errorf("DeleteCodeBlock() error = %v, want %v", err, ErrCodeBlockNotFound)
+	}
+
+	block, err := repo.LoadCodeBlock("2")
+	if err != nil || block.Code != "print(100%7)" || block.CreatedAt.IsZero() {
+		t.Errorf("LoadCodeBlock() = %+v, %v", block, err)
+	}
+}
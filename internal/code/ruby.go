@@ -0,0 +1,31 @@
+package code
+
+import (
+	"context"
+
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+type RubyExecutor struct {
+	sandbox *sandbox.Sandbox
+}
+
+func (re *RubyExecutor) setSandbox(sb *sandbox.Sandbox) {
+	re.sandbox = sb
+}
+
+func (re *RubyExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	if re.sandbox != nil {
+		return runSandboxed(ctx, re.sandbox, opts, "ruby", "-e", code)
+	}
+
+	return runCommand(ctx, opts, "ruby", "-e", code)
+}
+
+func initRubyExecutor() {
+	registerExecutor("ruby", &RubyExecutor{})
+}
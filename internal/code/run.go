@@ -0,0 +1,87 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/nullswan/nomi/internal/proc"
+)
+
+// waitDelay bounds the wait for orphaned children holding the pipes once
+// the process is killed.
+const waitDelay = time.Second
+
+// runCommand runs the command until it exits or the context is done, in
+// which case its whole process group is killed.
+func runCommand(
+	ctx context.Context,
+	opts ExecuteOptions,
+	name string,
+	args ...string,
+) ExecutionResult {
+	return runCommandIn(ctx, opts, "", nil, name, args...)
+}
+
+// runCommandIn is runCommand with the working directory of the command and
+// variables added to its environment.
+func runCommandIn(
+	ctx context.Context,
+	opts ExecuteOptions,
+	dir string,
+	env []string,
+	name string,
+	args ...string,
+) ExecutionResult {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	proc.SetProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+
+	stdout := proc.NewOutputBuffer(proc.DefaultOutputLimit)
+	stderr := proc.NewOutputBuffer(proc.DefaultOutputLimit)
+	cmd.Stdout = io.MultiWriter(stdout, streamWriter(StreamStdout, opts))
+	cmd.Stderr = io.MultiWriter(stderr, streamWriter(StreamStderr, opts))
+
+	err := cmd.Run()
+	exitCode := 0
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			exitCode = exitError.ExitCode()
+		} else {
+			exitCode = 1
+		}
+	}
+
+	return ExecutionResult{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		ExitCode:  exitCode,
+		Truncated: stdout.Truncated() || stderr.Truncated(),
+	}
+}
+
+// streamWriter forwards the written bytes to the OnOutput callback.
+func streamWriter(stream Stream, opts ExecuteOptions) io.Writer {
+	if opts.OnOutput == nil {
+		return io.Discard
+	}
+
+	return writerFunc(func(p []byte) (int, error) {
+		opts.OnOutput(stream, p)
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,80 @@
+package code
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+// sandboxable executors can run their code inside a sandbox.
+type sandboxable interface {
+	setSandbox(sb *sandbox.Sandbox)
+}
+
+var (
+	sandboxesMu sync.RWMutex
+	sandboxes   = make(map[string]*sandbox.Sandbox)
+)
+
+// UseSandbox runs the executors and the sessions of every sandbox-capable
+// language inside the sandbox, but the excluded ones.
+func UseSandbox(sb *sandbox.Sandbox, exclude ...string) {
+	registerExecutors()
+
+	excluded := make(map[string]bool, len(exclude))
+	for _, language := range exclude {
+		excluded[NormalizeLanguage(language)] = true
+	}
+
+	sandboxesMu.Lock()
+	defer sandboxesMu.Unlock()
+
+	for language, executor := range executors {
+		if excluded[language] {
+			continue
+		}
+		if executor, ok := executor.(sandboxable); ok {
+			executor.setSandbox(sb)
+			sandboxes[language] = sb
+		}
+	}
+}
+
+// sandboxFor returns the sandbox of the language, nil when it runs on the
+// host.
+func sandboxFor(language string) *sandbox.Sandbox {
+	sandboxesMu.RLock()
+	defer sandboxesMu.RUnlock()
+
+	return sandboxes[language]
+}
+
+func runSandboxed(
+	ctx context.Context,
+	sb *sandbox.Sandbox,
+	opts ExecuteOptions,
+	name string,
+	args ...string,
+) ExecutionResult {
+	r, err := sb.Run(
+		ctx,
+		streamWriter(StreamStdout, opts),
+		streamWriter(StreamStderr, opts),
+		name,
+		args...,
+	)
+	if err != nil {
+		return ExecutionResult{
+			Stderr:   err.Error(),
+			ExitCode: 1,
+		}
+	}
+
+	return ExecutionResult{
+		Stdout:    r.Stdout,
+		Stderr:    r.Stderr,
+		ExitCode:  r.ExitCode,
+		Truncated: r.Truncated,
+	}
+}
@@ -0,0 +1,51 @@
+package code
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+func TestRunSandboxedOutputLimit(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available on windows")
+	}
+
+	sb, err := sandbox.New(sandbox.Options{
+		Backend: sandbox.BackendNone,
+		Output:  1024,
+	})
+	if err != nil {
+		t.Fatalf("sandbox.New() error = %v", err)
+	}
+
+	result := runSandboxed(
+		context.Background(),
+		sb,
+		ExecuteOptions{},
+		"sh", "-c", "yes | head -c 100000; echo end",
+	)
+	if result.ExitCode != 0 {
+		t.Fatalf("runSandboxed() exit code = %d, stderr = %q",
+			result.ExitCode, result.Stderr)
+	}
+	if !result.Truncated {
+		t.Error("runSandboxed() Truncated = false, want true")
+	}
+	if !strings.HasPrefix(result.Stdout, "y\n") {
+		t.Errorf("runSandboxed() lost the head of the output: %q",
+			result.Stdout[:min(len(result.Stdout), 16)])
+	}
+	if !strings.HasSuffix(result.Stdout, "\nend\n") {
+		t.Errorf("runSandboxed() lost the tail of the output: %q",
+			result.Stdout[max(0, len(result.Stdout)-16):])
+	}
+	if !strings.Contains(result.Stdout, "bytes truncated") {
+		t.Error("runSandboxed() output has no truncation marker")
+	}
+}
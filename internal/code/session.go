@@ -0,0 +1,433 @@
+package code
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nullswan/nomi/internal/proc"
+)
+
+//go:embed kernels/*
+var kernelsFS embed.FS
+
+// frameMarker starts the frames closing the output of an execution.
+const frameMarker = '\x1e'
+
+// interruptGracePeriod is the time given to an interrupted execution to
+// finish before the session is killed.
+const interruptGracePeriod = 2 * time.Second
+
+// ErrSessionUnsupported is returned for languages without a kernel.
+var ErrSessionUnsupported = errors.New("sessions are not supported")
+
+type kernel struct {
+	file string
+	// command runs the kernel source
+	command func(source string) (string, []string)
+	// inspect lists the state of the session
+	inspect string
+}
+
+var kernels = map[string]kernel{
+	"python": {
+		file: "kernels/python.py",
+		command: func(source string) (string, []string) {
+			return "python3", []string{"-u", "-c", source}
+		},
+		inspect: `for __name, __value in sorted(globals().items()):
+    if not __name.startswith("_"):
+        print("%s: %s = %.80r" % (__name, type(__value).__name__, __value))
+del __name, __value
+`,
+	},
+	"bash": {
+		file: "kernels/bash.sh",
+		command: func(source string) (string, []string) {
+			return "bash", []string{"--noprofile", "--norc", "-c", source}
+		},
+		inspect: "__nomi_inspect",
+	},
+}
+
+// SupportsSession reports whether the language can run in a session.
+func SupportsSession(language string) bool {
+	_, ok := kernels[NormalizeLanguage(language)]
+	return ok
+}
+
+// Session is a long-lived interpreter keeping its state, variables and
+// imports, across executions. It implements Executor.
+type Session struct {
+	language string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stdin  io.WriteCloser
+	stdout *frameReader
+	stderr *frameReader
+	done   chan struct{}
+}
+
+var _ Executor = (*Session)(nil)
+
+// NewSession returns a session of the language, its kernel is started on
+// the first execution.
+func NewSession(language string) (*Session, error) {
+	language = NormalizeLanguage(language)
+	if _, ok := kernels[language]; !ok {
+		return nil, fmt.Errorf("%w for %s", ErrSessionUnsupported, language)
+	}
+
+	return &Session{language: language}, nil
+}
+
+// Language of the session.
+func (s *Session) Language() string {
+	return s.language
+}
+
+// Execute runs the code in the session. The session is restarted, losing
+// its state, when the kernel exits or cannot be interrupted.
+func (s *Session) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cmd == nil {
+		if err := s.start(); err != nil {
+			return ExecutionResult{
+				Stderr:   fmt.Sprintf("error starting session: %v", err),
+				ExitCode: 1,
+			}
+		}
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return ExecutionResult{Stderr: err.Error(), ExitCode: 1}
+	}
+
+	type frameResult struct {
+		trailer string
+		err     error
+	}
+	stdoutBuf := proc.NewOutputBuffer(proc.DefaultOutputLimit)
+	stderrBuf := proc.NewOutputBuffer(proc.DefaultOutputLimit)
+	stdoutCh := make(chan frameResult, 1)
+	stderrCh := make(chan frameResult, 1)
+	go func() {
+		trailer, err := s.stdout.readFrame(
+			nonce,
+			io.MultiWriter(stdoutBuf, streamWriter(StreamStdout, opts)),
+		)
+		stdoutCh <- frameResult{trailer, err}
+	}()
+	go func() {
+		trailer, err := s.stderr.readFrame(
+			nonce,
+			io.MultiWriter(stderrBuf, streamWriter(StreamStderr, opts)),
+		)
+		stderrCh <- frameResult{trailer, err}
+	}()
+
+	request := nonce + " " + strconv.Itoa(len(code)) + "\n" + code
+	if _, err := io.WriteString(s.stdin, request); err != nil {
+		s.stop()
+		<-stdoutCh
+		<-stderrCh
+		return ExecutionResult{
+			Stderr:   "Session exited, its state was lost: " + err.Error(),
+			ExitCode: 1,
+		}
+	}
+
+	var stdout frameResult
+	select {
+	case stdout = <-stdoutCh:
+	case <-ctx.Done():
+		// Try to keep the state, kill the session otherwise
+		_ = proc.Interrupt(s.cmd)
+		select {
+		case stdout = <-stdoutCh:
+		case <-time.After(interruptGracePeriod):
+			s.stop()
+			stdout = <-stdoutCh
+		}
+	}
+	stderr := <-stderrCh
+
+	result := ExecutionResult{
+		Stdout:    stdoutBuf.String(),
+		Stderr:    stderrBuf.String(),
+		Truncated: stdoutBuf.Truncated() || stderrBuf.Truncated(),
+	}
+
+	if stdout.err != nil || stderr.err != nil {
+		s.stop()
+		result.ExitCode = 1
+		result.Stderr = appendLine(
+			result.Stderr,
+			"Session exited, its state was lost",
+		)
+		return result
+	}
+
+	// The trailer of stdout holds the exit status
+	status, err := strconv.Atoi(stdout.trailer)
+	if err != nil {
+		status = 1
+	}
+	result.ExitCode = status
+
+	return result
+}
+
+// Inspect describes the state of the session, such as its variables.
+func (s *Session) Inspect(ctx context.Context) ExecutionResult {
+	return s.Execute(ctx, kernels[s.language].inspect, ExecuteOptions{})
+}
+
+// Reset stops the kernel, the next execution starts from a clean state.
+func (s *Session) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stop()
+}
+
+// Close stops the kernel.
+func (s *Session) Close() error {
+	s.Reset()
+	return nil
+}
+
+func (s *Session) start() error {
+	k := kernels[s.language]
+	source, err := kernelsFS.ReadFile(k.file)
+	if err != nil {
+		return fmt.Errorf("error reading kernel: %w", err)
+	}
+	name, args := k.command(string(source))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var cmd *exec.Cmd
+	cleanup := func() {}
+	if sb := sandboxFor(s.language); sb != nil {
+		cmd, cleanup, err = sb.Command(ctx, name, args...)
+		if err != nil {
+			cancel()
+			return err
+		}
+	} else {
+		cmd = exec.CommandContext(ctx, name, args...)
+		proc.SetProcessGroup(cmd)
+		cmd.WaitDelay = waitDelay
+	}
+
+	fail := func(err error) error {
+		cancel()
+		cleanup()
+		return err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fail(fmt.Errorf("error creating stdin pipe: %w", err))
+	}
+
+	// Plain pipes, read until the kernel and its children exit, unlike
+	// the pipes of exec.Cmd which are closed by Wait
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return fail(fmt.Errorf("error creating stdout pipe: %w", err))
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return fail(fmt.Errorf("error creating stderr pipe: %w", err))
+	}
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+
+	err = cmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		stdoutR.Close()
+		stderrR.Close()
+		return fail(fmt.Errorf("error starting kernel: %w", err))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		cleanup()
+		close(done)
+	}()
+
+	s.cmd = cmd
+	s.cancel = cancel
+	s.stdin = stdin
+	s.stdout = &frameReader{r: stdoutR}
+	s.stderr = &frameReader{r: stderrR}
+	s.done = done
+
+	return nil
+}
+
+// stop kills the kernel and waits for it, the lock must be held.
+func (s *Session) stop() {
+	if s.cmd == nil {
+		return
+	}
+
+	s.stdin.Close()
+	s.cancel()
+	<-s.done
+
+	s.stdout.Close()
+	s.stderr.Close()
+	s.cmd = nil
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// frameReader splits the output of a kernel in frames, each ending with
+// a marker line: frameMarker, the nonce, and an optional ":<trailer>".
+type frameReader struct {
+	r       io.ReadCloser
+	pending []byte
+}
+
+func (f *frameReader) Close() error {
+	return f.r.Close()
+}
+
+// readFrame writes the output up to the marker of the nonce to w as it is
+// read, and returns the trailer of the marker.
+func (f *frameReader) readFrame(
+	nonce string,
+	w io.Writer,
+) (string, error) {
+	marker := append([]byte{frameMarker}, nonce...)
+
+	buf := make([]byte, 4096)
+	for {
+		if i := bytes.Index(f.pending, marker); i >= 0 {
+			if end := bytes.IndexByte(f.pending[i:], '\n'); end >= 0 {
+				_, _ = w.Write(f.pending[:i])
+
+				trailer := string(f.pending[i+len(marker) : i+end])
+				if len(trailer) > 0 && trailer[0] == ':' {
+					trailer = trailer[1:]
+				}
+				f.pending = f.pending[i+end+1:]
+				return trailer, nil
+			}
+		} else {
+			// Flush all but what could be the start of the marker
+			flush := len(f.pending)
+			if i := bytes.LastIndexByte(f.pending, frameMarker); i >= 0 &&
+				bytes.HasPrefix(marker, f.pending[i:]) {
+				flush = i
+			}
+			_, _ = w.Write(f.pending[:flush])
+			f.pending = f.pending[flush:]
+		}
+
+		n, err := f.r.Read(buf)
+		f.pending = append(f.pending, buf[:n]...)
+		if err != nil && n == 0 {
+			_, _ = w.Write(f.pending)
+			f.pending = nil
+			return "", err
+		}
+	}
+}
+
+// Sessions keeps a session per language, started on demand.
+type Sessions struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+func NewSessions() *Sessions {
+	return &Sessions{sessions: make(map[string]*Session)}
+}
+
+// Get returns the session of the language, false when the language does
+// not support sessions.
+func (s *Sessions) Get(language string) (*Session, bool) {
+	language = NormalizeLanguage(language)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[language]; ok {
+		return session, true
+	}
+
+	session, err := NewSession(language)
+	if err != nil {
+		return nil, false
+	}
+	s.sessions[language] = session
+
+	return session, true
+}
+
+// Languages lists the languages with a session, sorted.
+func (s *Sessions) Languages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	languages := make([]string, 0, len(s.sessions))
+	for language := range s.sessions {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Reset resets the session of the language, or all of them when empty.
+func (s *Sessions) Reset(language string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for l, session := range s.sessions {
+		if language == "" || l == NormalizeLanguage(language) {
+			session.Reset()
+		}
+	}
+}
+
+// Close stops all the sessions.
+func (s *Sessions) Close() error {
+	s.Reset("")
+	return nil
+}
@@ -0,0 +1,163 @@
+package code
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nullswan/nomi/internal/proc"
+)
+
+type sessionStep struct {
+	code     string
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+func TestSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		language string
+		binary   string
+		steps    []sessionStep
+	}{
+		{
+			language: "python",
+			binary:   "python3",
+			steps: []sessionStep{
+				{code: "import math\nx = 21", stdout: ""},
+				{code: "print(x * 2)\nmath.floor(2.5)", stdout: "42\n2\n"},
+				{code: "import sys\nprint('oops', file=sys.stderr, end='')", stderr: "oops"},
+				{code: "raise SystemExit(3)", exitCode: 3},
+				{code: "x", stdout: "21\n"},
+				{code: "import os\nos._exit(0)", stderr: "Session exited, its state was lost", exitCode: 1},
+				{code: "'x' in globals()", stdout: "False\n"},
+			},
+		},
+		{
+			language: "bash",
+			binary:   "bash",
+			steps: []sessionStep{
+				{code: "x=21; cd /; greet() { echo \"hi $1\"; }", stdout: ""},
+				{code: "echo $((x * 2)) $(pwd)\ngreet you", stdout: "42 /\nhi you\n"},
+				{code: "printf 'no newline'", stdout: "no newline"},
+				{code: "echo é; false", stdout: "é\n", exitCode: 1},
+				{code: "exit 4", stderr: "Session exited, its state was lost", exitCode: 1},
+				{code: "echo \"[$x]\"", stdout: "[]\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := exec.LookPath(tt.binary); err != nil {
+				t.Skipf("%s is not installed", tt.binary)
+			}
+
+			session, err := NewSession(tt.language)
+			if err != nil {
+				t.Fatalf("NewSession() error = %v", err)
+			}
+			defer session.Close()
+
+			for _, step := range tt.steps {
+				r := session.Execute(
+					context.Background(),
+					step.code,
+					ExecuteOptions{},
+				)
+				if r.Stdout != step.stdout ||
+					!strings.Contains(r.Stderr, step.stderr) ||
+					r.ExitCode != step.exitCode {
+					t.Errorf("Execute(%q) = %+v, want %+v", step.code, r, step)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionInterrupt(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not installed")
+	}
+
+	sessions := NewSessions()
+	defer sessions.Close()
+
+	opts := ExecuteOptions{Sessions: sessions, Timeout: time.Second}
+
+	r := ExecuteCodeBlock(
+		context.Background(),
+		CodeBlock{Language: "py", Code: "x = 1"},
+		opts,
+	)
+	if r.ExitCode != 0 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+
+	// The interrupted execution keeps the state of the session
+	r = ExecuteCodeBlock(
+		context.Background(),
+		CodeBlock{Language: "python", Code: "import time\ntime.sleep(30)"},
+		opts,
+	)
+	if !r.TimedOut || !strings.Contains(r.Stderr, "KeyboardInterrupt") {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	session, _ := sessions.Get("python")
+	r = session.Inspect(context.Background())
+	if !strings.Contains(r.Stdout, "x: int = 1") {
+		t.Errorf("Inspect() = %+v", r)
+	}
+
+	sessions.Reset("python")
+	r = session.Inspect(context.Background())
+	if strings.Contains(r.Stdout, "x: int") {
+		t.Errorf("Inspect() after Reset() = %+v", r)
+	}
+}
+
+func TestSessionOutputLimit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	session, err := NewSession("bash")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	defer session.Close()
+
+	// The head and the tail of the frame are kept, the session goes on
+	r := session.Execute(
+		context.Background(),
+		"echo start; yes | head -c 3000000; echo end",
+		ExecuteOptions{},
+	)
+	if !r.Truncated ||
+		len(r.Stdout) > 2*proc.DefaultOutputLimit ||
+		!strings.HasPrefix(r.Stdout, "start\n") ||
+		!strings.HasSuffix(r.Stdout, "\nend\n") {
+		t.Errorf(
+			"output limit not enforced: truncated=%v, len=%d",
+			r.Truncated,
+			len(r.Stdout),
+		)
+	}
+
+	r = session.Execute(context.Background(), "echo next", ExecuteOptions{})
+	if r.Stdout != "next\n" || r.Truncated {
+		t.Errorf("Execute() after a truncated frame = %+v", r)
+	}
+}
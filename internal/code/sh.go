@@ -0,0 +1,31 @@
+package code
+
+import (
+	"context"
+
+	"github.com/nullswan/nomi/internal/sandbox"
+)
+
+type ShExecutor struct {
+	sandbox *sandbox.Sandbox
+}
+
+func (se *ShExecutor) setSandbox(sb *sandbox.Sandbox) {
+	se.sandbox = sb
+}
+
+func (se *ShExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	if se.sandbox != nil {
+		return runSandboxed(ctx, se.sandbox, opts, "sh", "-c", code)
+	}
+
+	return runCommand(ctx, opts, "sh", "-c", code)
+}
+
+func initShExecutor() {
+	registerExecutor("sh", &ShExecutor{})
+}
@@ -0,0 +1,257 @@
+package code
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// inMemoryDatabase is used when no database is configured, it does not
+// persist across executions.
+const inMemoryDatabase = ":memory:"
+
+var (
+	sqlDatabaseMu sync.RWMutex
+	sqlDatabase   = inMemoryDatabase
+)
+
+// SetSQLDatabase sets the SQLite file the sql code runs against, an empty
+// path restores the in-memory database.
+func SetSQLDatabase(path string) {
+	sqlDatabaseMu.Lock()
+	defer sqlDatabaseMu.Unlock()
+
+	if path == "" {
+		path = inMemoryDatabase
+	}
+	sqlDatabase = path
+}
+
+// SQLExecutor runs the statements in-process against a SQLite database and
+// renders the rows as tables. It runs outside of the sandbox, so the
+// statements cannot attach other databases: ATTACH and VACUUM, which
+// attaches its target, would read or write any file of the host.
+type SQLExecutor struct{}
+
+func (se *SQLExecutor) Execute(
+	ctx context.Context,
+	code string,
+	opts ExecuteOptions,
+) ExecutionResult {
+	sqlDatabaseMu.RLock()
+	path := sqlDatabase
+	sqlDatabaseMu.RUnlock()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return ExecutionResult{
+			Stderr:   fmt.Sprintf("error opening database: %v", err),
+			ExitCode: 1,
+		}
+	}
+	defer db.Close()
+
+	// Keep a single connection, an in-memory database lives in it and
+	// the limits are set on it
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return ExecutionResult{
+			Stderr:   fmt.Sprintf("error opening database: %v", err),
+			ExitCode: 1,
+		}
+	}
+	defer conn.Close()
+
+	if _, err := sqlite.Limit(conn, sqlite3.SQLITE_LIMIT_ATTACHED, 0); err != nil {
+		return ExecutionResult{
+			Stderr:   fmt.Sprintf("error restricting database: %v", err),
+			ExitCode: 1,
+		}
+	}
+
+	var stdout strings.Builder
+	for _, statement := range splitStatements(code) {
+		output, err := runStatement(ctx, conn, statement)
+		if err != nil {
+			return ExecutionResult{
+				Stdout:   stdout.String(),
+				Stderr:   fmt.Sprintf("Error: %v", err),
+				ExitCode: 1,
+			}
+		}
+
+		if output != "" {
+			stdout.WriteString(output)
+			if opts.OnOutput != nil {
+				opts.OnOutput(StreamStdout, []byte(output))
+			}
+		}
+	}
+
+	return ExecutionResult{Stdout: stdout.String()}
+}
+
+func runStatement(
+	ctx context.Context,
+	db *sql.Conn,
+	statement string,
+) (string, error) {
+	keyword := strings.ToLower(firstKeyword(statement))
+	switch keyword {
+	case "select", "with", "pragma", "values", "explain":
+		return queryStatement(ctx, db, statement)
+	}
+
+	res, err := db.ExecContext(ctx, statement)
+	if err != nil {
+		return "", err
+	}
+
+	switch keyword {
+	case "insert", "update", "delete", "replace":
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return "", nil
+		}
+		return fmt.Sprintf("%d row(s) affected\n", affected), nil
+	default:
+		return "", nil
+	}
+}
+
+func queryStatement(
+	ctx context.Context,
+	db *sql.Conn,
+	statement string,
+) (string, error) {
+	rows, err := db.QueryContext(ctx, statement)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", rows.Err()
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.Style().Options.DrawBorder = false
+
+	header := make(table.Row, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	t.AppendHeader(header)
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return "", err
+		}
+
+		row := make(table.Row, len(columns))
+		for i, v := range values {
+			row[i] = formatValue(v)
+		}
+		t.AppendRow(row)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s\n(%d row(s))\n", t.Render(), count), nil
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// splitStatements splits the code on the semicolons outside of the quotes
+// and comments.
+func splitStatements(code string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      byte
+	)
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && strings.HasPrefix(code[i:], "--"):
+			end := strings.IndexByte(code[i:], '\n')
+			if end < 0 {
+				end = len(code) - i
+			}
+			i += end - 1
+			continue
+		case c == '/' && strings.HasPrefix(code[i:], "/*"):
+			end := strings.Index(code[i+2:], "*/")
+			if end < 0 {
+				i = len(code)
+			} else {
+				i += end + 3
+			}
+			continue
+		case c == ';':
+			flush()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	flush()
+
+	return statements
+}
+
+func firstKeyword(statement string) string {
+	fields := strings.FieldsFunc(statement, func(r rune) bool {
+		return r == ' ' || r == '\n' || r == '\t' || r == '\r' || r == '('
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func initSQLExecutor() {
+	registerExecutor("sql", &SQLExecutor{})
+}
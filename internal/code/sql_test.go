@@ -0,0 +1,80 @@
+package code
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+
+	code := `-- create; the table
+CREATE TABLE t (name TEXT);
+INSERT INTO t VALUES ('a;b'), ("c");
+/* select; */ SELECT * FROM t`
+
+	expected := []string{
+		"CREATE TABLE t (name TEXT)",
+		`INSERT INTO t VALUES ('a;b'), ("c")`,
+		"SELECT * FROM t",
+	}
+
+	if result := splitStatements(code); !reflect.DeepEqual(result, expected) {
+		t.Errorf("splitStatements() = %q, want %q", result, expected)
+	}
+}
+
+// Not parallel, the database is global
+func TestSQLExecutor(t *testing.T) {
+	SetSQLDatabase(filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(func() { SetSQLDatabase("") })
+
+	executor := &SQLExecutor{}
+
+	r := executor.Execute(
+		context.Background(),
+		"CREATE TABLE users (id INTEGER, name TEXT);\n"+
+			"INSERT INTO users VALUES (1, 'alice'), (2, NULL);\n"+
+			"SELECT id, name FROM users ORDER BY id;",
+		ExecuteOptions{},
+	)
+	if r.ExitCode != 0 {
+		t.Fatalf("Execute() failed: %s", r.Stderr)
+	}
+	for _, expected := range []string{"2 row(s) affected", "ID", "alice", "NULL", "(2 row(s))"} {
+		if !strings.Contains(r.Stdout, expected) {
+			t.Errorf("output %q does not contain %q", r.Stdout, expected)
+		}
+	}
+
+	// The database persists across executions
+	r = executor.Execute(
+		context.Background(),
+		"SELECT count(*) FROM users; SELECT * FROM missing",
+		ExecuteOptions{},
+	)
+	if r.ExitCode != 1 || !strings.Contains(r.Stdout, "2") ||
+		!strings.Contains(r.Stderr, "no such table") {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	// The statements cannot touch other files of the host
+	outside := filepath.Join(t.TempDir(), "outside.db")
+	for _, statement := range []string{
+		"ATTACH DATABASE '" + outside + "' AS outside",
+		"VACUUM INTO '" + outside + "'",
+		"SELECT 1; ATTACH DATABASE '" + outside + "' AS outside",
+	} {
+		r = executor.Execute(context.Background(), statement, ExecuteOptions{})
+		if r.ExitCode == 0 {
+			t.Errorf("Execute(%q) succeeded", statement)
+		}
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("%s was created", outside)
+	}
+}
@@ -1,19 +1,56 @@
 package code
 
+import (
+	"context"
+	"time"
+)
+
 type CodeBlock struct {
 	ID          string
+	CreatedAt   time.Time
 	Language    string
 	Code        string
 	Description string
+	// Info is the info string of the fence, e.g. "python title=x.py"
+	Info string
+	// Attributes are the key=value pairs of the info string
+	Attributes map[string]string
+	// StartLine and EndLine are the lines of the fences in the document,
+	// starting at 1
+	StartLine int
+	EndLine   int
 }
 
 type ExecutionResult struct {
 	Stdout   string
 	Stderr   string
 	ExitCode int
-	Block    CodeBlock
+	TimedOut bool
+	// Truncated is set when the middle of an output was dropped
+	Truncated bool
+	Block     CodeBlock
+}
+
+type Stream string
+
+const (
+	StreamStdout Stream = "stdout"
+	StreamStderr Stream = "stderr"
+)
+
+type ExecuteOptions struct {
+	// Timeout overrides the timeout of the language when positive.
+	Timeout time.Duration
+	// OnOutput receives the output as it is produced.
+	OnOutput func(stream Stream, data []byte)
+	// Sessions runs the code of the supported languages in persistent
+	// sessions when set.
+	Sessions *Sessions
+	// AllLanguages runs the blocks of every language in order, only the
+	// blocks in the language of the first block run otherwise.
+	AllLanguages bool
 }
 
 type Executor interface {
-	Execute(code string) ExecutionResult
+	Execute(ctx context.Context, code string, opts ExecuteOptions) ExecutionResult
 }
@@ -0,0 +1,14 @@
+package completion
+
+// RequestOptions are the parameters of a completion request, unset values
+// keep the defaults of the model.
+type RequestOptions struct {
+	Temperature *float32
+	TopP        *float32
+	// MaxTokens bounds the length of the completion, 0 keeps the default
+	MaxTokens int
+	Stop      []string
+	Seed      *int
+	// JSON asks for a JSON object, text-to-json providers always do
+	JSON bool
+}
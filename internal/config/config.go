@@ -20,20 +20,44 @@ func Exists() bool {
 	return true
 }
 
-// LoadConfig loads the configuration from the YAML file or creates a default one if it doesn't exist.
+// LoadConfig loads the configuration of the user, with the configuration
+// of the project merged over it and the NOMI_* environment variables
+// applied last.
 func LoadConfig() (*Config, error) {
-	var cfg Config
+	cfg, err := LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyProjectConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnvironment(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
 
+// LoadUserConfig loads the configuration from the YAML file, or the default
+// one if it doesn't exist. The default is not saved, the file is written by
+// the setup.
+func LoadUserConfig() (*Config, error) {
 	if !Exists() {
-		// File does not exist, create default configuration
-		cfg = DefaultConfig()
-		if err := SaveConfig(&cfg); err != nil {
-			return nil, err
-		}
+		cfg := DefaultConfig()
 		return &cfg, nil
 	}
 
-	data, err := os.ReadFile(configFilePath)
+	return loadConfigFile(configFilePath)
+}
+
+// loadConfigFile unmarshals the file over the default configuration, the
+// settings it lacks, e.g. added since it was written, keep their default.
+func loadConfigFile(fp string) (*Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(fp)
 	if err != nil {
 		return nil, fmt.Errorf("error reading configuration file: %w", err)
 	}
@@ -91,6 +115,28 @@ func DefaultConfig() Config {
 				Enabled: false,
 			},
 		},
+		Interpreter: InterpreterConfig{
+			Sandbox: SandboxConfig{
+				Enabled:    true,
+				Backend:    "auto",
+				Network:    false,
+				CPUTime:    60,
+				Memory:     1024,
+				OutputSize: 1024,
+			},
+			Timeouts: map[string]int{
+				"bash":   120,
+				"python": 300,
+			},
+			Approval: ApprovalConfig{
+				Policy:   "ask-on-risky",
+				Usecases: map[string]string{},
+				AuditLog: GetAuditLogPath(),
+			},
+		},
+		Prompts: PromptsConfig{
+			RegistryURL: DefaultRegistryURL,
+		},
 		PlaySound: false,
 	}
 }
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Parallel()
+
+	// A configuration written before the interpreter and prompts settings
+	fp := filepath.Join(t.TempDir(), configFileName)
+	err := os.WriteFile(fp, []byte(`input:
+  voice:
+    enabled: false
+    language: fr
+    keyCode: 58
+output:
+  sqlite:
+    enabled: false
+    path: ""
+  speech:
+    enabled: false
+dev_mode: true
+play_sound: false
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfigFile(fp)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+
+	if cfg.Input.Voice.Language != "fr" || !cfg.DevMode {
+		t.Errorf("settings of the file not applied: %+v", cfg)
+	}
+
+	defaults := DefaultConfig()
+	if !cfg.Interpreter.Sandbox.Enabled ||
+		cfg.Interpreter.Approval.Policy != defaults.Interpreter.Approval.Policy ||
+		cfg.Interpreter.Timeouts["python"] != defaults.Interpreter.Timeouts["python"] ||
+		cfg.Prompts.RegistryURL != defaults.Prompts.RegistryURL {
+		t.Errorf("missing settings did not take their default: %+v", cfg)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -1,15 +1,31 @@
 package config
 
+import "path/filepath"
+
 const (
-	conversationDir = "conversations"
-	promptDir       = "prompts"
-	knownledgeDir   = "knowledge"
+	conversationDir  = "conversations"
+	promptDir        = "prompts"
+	promptHistoryDir = "prompt_history"
+	knownledgeDir    = "knowledge"
+	usecaseDir       = "usecases"
+	workflowDir      = "workflows"
+	backupDir        = "backups"
+	registryDir      = "registry"
 
 	// configDir is the directory where the configuration file is stored.
 	configDir = ".nomi"
 
 	// configFileName is the name of the configuration file.
 	configFileName = "config.yml"
+
+	// auditLogFileName is the name of the interpreter audit log.
+	auditLogFileName = "audit.log"
+
+	// environmentFileName caches the probe of the environment.
+	environmentFileName = "environment.json"
+
+	// DefaultRegistryURL serves the prompts of the repository.
+	DefaultRegistryURL = "https://raw.githubusercontent.com/nullswan/nomi/refs/heads/main/prompts"
 )
 
 var configFilePath string
@@ -22,6 +38,17 @@ func GetPromptDirectory() string {
 	return GetModuleDirectory(promptDir)
 }
 
+// GetPromptHistoryDirectory returns the directory of the archived prompt
+// versions.
+func GetPromptHistoryDirectory() string {
+	return GetModuleDirectory(promptHistoryDir)
+}
+
+// GetRegistryDirectory returns the directory caching the prompt registry.
+func GetRegistryDirectory() string {
+	return GetModuleDirectory(registryDir)
+}
+
 func GetConversationDirectory() string {
 	return GetModuleDirectory(conversationDir)
 }
@@ -29,3 +56,23 @@ func GetConversationDirectory() string {
 func GetKnowledgeDirectory() string {
 	return GetModuleDirectory(knownledgeDir)
 }
+
+func GetUsecaseDirectory() string {
+	return GetModuleDirectory(usecaseDir)
+}
+
+func GetWorkflowDirectory() string {
+	return GetModuleDirectory(workflowDir)
+}
+
+func GetBackupDirectory() string {
+	return GetModuleDirectory(backupDir)
+}
+
+func GetEnvironmentCachePath() string {
+	return filepath.Join(GetProgramDirectory(), environmentFileName)
+}
+
+func GetAuditLogPath() string {
+	return filepath.Join(GetProgramDirectory(), auditLogFileName)
+}
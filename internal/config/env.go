@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// envPrefix prefixes the environment variables overriding the settings,
+// NOMI_INPUT_VOICE_LANGUAGE overrides input.voice.language.
+const envPrefix = "NOMI_"
+
+// envOverride is an environment variable overriding a setting.
+type envOverride struct {
+	Name  string
+	Key   string
+	Value string
+}
+
+// EnvName returns the environment variable overriding the dotted key.
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(
+		strings.NewReplacer(".", "_", "-", "_").Replace(key),
+	)
+}
+
+// lookupEnvironment lists the variables overriding a setting. A map is
+// overridden as a whole by its variable in YAML, or entry by entry by its
+// variable suffixed with _<KEY>.
+func lookupEnvironment() []envOverride {
+	environ := os.Environ()
+	sort.Strings(environ)
+
+	var overrides []envOverride
+	settingKeys(reflect.TypeOf(Config{}), "", func(key string, t reflect.Type) {
+		name := EnvName(key)
+		if value, ok := os.LookupEnv(name); ok {
+			overrides = append(overrides, envOverride{name, key, value})
+		}
+		if t.Kind() != reflect.Map {
+			return
+		}
+
+		for _, entry := range environ {
+			entryName, value, _ := strings.Cut(entry, "=")
+			suffix, ok := strings.CutPrefix(entryName, name+"_")
+			if !ok || suffix == "" {
+				continue
+			}
+			overrides = append(overrides, envOverride{
+				Name:  entryName,
+				Key:   key + "." + strings.ToLower(suffix),
+				Value: value,
+			})
+		}
+	})
+
+	return overrides
+}
+
+// settingKeys calls fn on each setting of the struct type, sections
+// excluded.
+func settingKeys(t reflect.Type, prefix string, fn func(string, reflect.Type)) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key := prefix + yamlName(field)
+		if field.Type.Kind() == reflect.Struct {
+			settingKeys(field.Type, key+".", fn)
+			continue
+		}
+		fn(key, field.Type)
+	}
+}
+
+// applyEnvironment applies the variables overriding a setting.
+func applyEnvironment(cfg *Config) error {
+	for _, o := range lookupEnvironment() {
+		if err := SetValue(cfg, o.Key, o.Value); err != nil {
+			return fmt.Errorf("invalid %s: %w", o.Name, err)
+		}
+	}
+	return nil
+}
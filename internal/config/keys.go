@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// GetValue returns the setting of the dotted key, as YAML for the
+// sections, the lists and the maps.
+func GetValue(cfg *Config, key string) (string, error) {
+	v, err := lookupKey(reflect.ValueOf(cfg).Elem(), splitKey(key))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		data, err := yaml.Marshal(v.Interface())
+		if err != nil {
+			return "", fmt.Errorf("error marshalling %s: %w", key, err)
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+}
+
+// SetValue parses the value into the setting of the dotted key. Lists of
+// strings are comma separated, the other lists and the maps are YAML.
+func SetValue(cfg *Config, key, value string) error {
+	if err := setKey(reflect.ValueOf(cfg).Elem(), splitKey(key), value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+func splitKey(key string) []string {
+	return strings.Split(strings.Trim(key, "."), ".")
+}
+
+func lookupKey(v reflect.Value, path []string) (reflect.Value, error) {
+	for i, name := range path {
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(v, name)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("unknown key %q", name)
+			}
+			v = field
+		case reflect.Map:
+			entry := v.MapIndex(reflect.ValueOf(name))
+			if !entry.IsValid() {
+				return reflect.Value{}, fmt.Errorf("no entry %q", name)
+			}
+			v = entry
+		default:
+			return reflect.Value{}, fmt.Errorf(
+				"%s is not a section",
+				strings.Join(path[:i], "."),
+			)
+		}
+	}
+	return v, nil
+}
+
+func setKey(v reflect.Value, path []string, value string) error {
+	for i, name := range path {
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(v, name)
+			if !ok {
+				return fmt.Errorf("unknown key %q", name)
+			}
+			v = field
+		case reflect.Map:
+			if i != len(path)-1 {
+				return fmt.Errorf("%s has no sections", strings.Join(path[:i], "."))
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			entry := reflect.New(v.Type().Elem()).Elem()
+			if err := parseValue(entry, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(name), entry)
+			return nil
+		default:
+			return fmt.Errorf("%s is not a section", strings.Join(path[:i], "."))
+		}
+	}
+
+	if v.Kind() == reflect.Struct {
+		return fmt.Errorf("is a section, set its keys")
+	}
+	return parseValue(v, value)
+}
+
+func parseValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return parseYAML(v, value)
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return parseYAML(v, value)
+	}
+	return nil
+}
+
+func parseYAML(v reflect.Value, value string) error {
+	ptr := reflect.New(v.Type())
+	if err := yaml.UnmarshalStrict([]byte(value), ptr.Interface()); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// fieldByTag returns the field of the struct named name in YAML.
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		if yamlName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// walkConfig calls fn on each node of the configuration, with its path
+// and the pattern of the path, where map keys are * and list indexes [*].
+func walkConfig(
+	v reflect.Value,
+	path, pattern string,
+	fn func(path, pattern string, v reflect.Value),
+) {
+	if path != "" {
+		fn(path, pattern, v)
+	}
+
+	join := func(prefix, name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			name := yamlName(t.Field(i))
+			walkConfig(v.Field(i), join(path, name), join(pattern, name), fn)
+		}
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkConfig(
+				v.MapIndex(reflect.ValueOf(k)),
+				join(path, k),
+				join(pattern, "*"),
+				fn,
+			)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			walkConfig(
+				v.Index(i),
+				fmt.Sprintf("%s[%d]", path, i),
+				pattern+"[*]",
+				fn,
+			)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// protectedKeys cannot be set by a project configuration, a repository
+// must not weaken the guards of the user, redirect the prompt updates or
+// choose where the data of the user is read and written.
+var protectedKeys = []string{
+	"interpreter.sandbox",
+	"interpreter.approval",
+	"interpreter.sql_database",
+	"interpreter.notebook.directory",
+	"output.sqlite.path",
+	"prompts.registry_url",
+	"prompts.trusted_keys",
+	"prompts.require_signature",
+}
+
+// Source is a file, or an environment variable, contributing to the
+// configuration.
+type Source struct {
+	// Path is the file, or $NAME for a variable
+	Path string
+	// Keys are the settings of the file, as dotted paths
+	Keys []string
+	// Ignored are the protected keys of a project file, not applied
+	Ignored []string
+}
+
+var projectDirectory = sync.OnceValue(func() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return FindProjectDirectory(wd)
+})
+
+// GetProjectDirectory returns the .nomi directory of the project of the
+// working directory, empty outside of a project.
+func GetProjectDirectory() string {
+	return projectDirectory()
+}
+
+// FindProjectDirectory returns the first .nomi directory found walking up
+// from the directory, the one of the user excluded.
+func FindProjectDirectory(dir string) string {
+	user := filepath.Join(GetHomeDir(), configDir)
+	for {
+		candidate := filepath.Join(dir, configDir)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() &&
+			candidate != user {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// GetProjectPromptDirectory returns the prompt directory of the project,
+// empty when there is none.
+func GetProjectPromptDirectory() string {
+	project := GetProjectDirectory()
+	if project == "" {
+		return ""
+	}
+
+	dir := filepath.Join(project, promptDir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// GetProjectConfigPath returns the configuration file of the project,
+// empty when there is none.
+func GetProjectConfigPath() string {
+	project := GetProjectDirectory()
+	if project == "" {
+		return ""
+	}
+
+	fp := filepath.Join(project, configFileName)
+	if _, err := os.Stat(fp); err != nil {
+		return ""
+	}
+	return fp
+}
+
+// GetConfigSources returns the files and the environment variables of the
+// configuration, in the order they are applied.
+func GetConfigSources() ([]Source, error) {
+	var sources []Source
+
+	if Exists() {
+		values, err := readConfigValues(configFilePath)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, Source{
+			Path: configFilePath,
+			Keys: flattenKeys(values, ""),
+		})
+	}
+
+	if fp := GetProjectConfigPath(); fp != "" {
+		values, err := readConfigValues(fp)
+		if err != nil {
+			return nil, err
+		}
+		values, ignored := removeProtectedKeys(values)
+		sources = append(sources, Source{
+			Path:    fp,
+			Keys:    flattenKeys(values, ""),
+			Ignored: ignored,
+		})
+	}
+
+	for _, o := range lookupEnvironment() {
+		sources = append(sources, Source{
+			Path: "$" + o.Name,
+			Keys: []string{o.Key},
+		})
+	}
+
+	return sources, nil
+}
+
+// applyProjectConfig merges the configuration of the project over the
+// configuration, but its protected keys.
+func applyProjectConfig(cfg *Config) error {
+	fp := GetProjectConfigPath()
+	if fp == "" {
+		return nil
+	}
+
+	return mergeProjectConfig(cfg, fp)
+}
+
+func mergeProjectConfig(cfg *Config, fp string) error {
+	values, err := readConfigValues(fp)
+	if err != nil {
+		return err
+	}
+	values, _ = removeProtectedKeys(values)
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("error marshalling project configuration: %w", err)
+	}
+
+	// Unmarshal over the configuration, keeping the settings it lacks
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf(
+			"error unmarshalling project configuration file: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+func readConfigValues(fp string) (yaml.MapSlice, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file: %w", err)
+	}
+
+	var values yaml.MapSlice
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf(
+			"error unmarshalling configuration file %s: %w",
+			fp,
+			err,
+		)
+	}
+
+	return values, nil
+}
+
+// removeProtectedKeys returns the values without their protected keys,
+// and the removed ones.
+func removeProtectedKeys(values yaml.MapSlice) (yaml.MapSlice, []string) {
+	var removed []string
+	for _, key := range protectedKeys {
+		var ok bool
+		values, ok = removeKey(values, strings.Split(key, "."))
+		if ok {
+			removed = append(removed, key)
+		}
+	}
+	return values, removed
+}
+
+func removeKey(values yaml.MapSlice, path []string) (yaml.MapSlice, bool) {
+	for i, item := range values {
+		if fmt.Sprint(item.Key) != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			return append(values[:i:i], values[i+1:]...), true
+		}
+
+		nested, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			return values, false
+		}
+		nested, removed := removeKey(nested, path[1:])
+		if removed && len(nested) == 0 {
+			// Drop the emptied section, it no longer sets anything
+			return append(values[:i:i], values[i+1:]...), true
+		}
+		values[i].Value = nested
+		return values, removed
+	}
+	return values, false
+}
+
+// Setting is a leaf of the configuration and the file which set it last,
+// empty for the defaults.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// GetSettings lists the leaves of the configuration with their source.
+func GetSettings(cfg *Config, sources []Source) ([]Setting, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling configuration: %w", err)
+	}
+
+	var values yaml.MapSlice
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("error unmarshalling configuration: %w", err)
+	}
+
+	settings := flattenValues(values, "")
+	for i := range settings {
+		for _, source := range sources {
+			for _, key := range source.Keys {
+				if key == settings[i].Key ||
+					strings.HasPrefix(settings[i].Key, key+".") {
+					settings[i].Source = source.Path
+				}
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+func flattenValues(values yaml.MapSlice, prefix string) []Setting {
+	var settings []Setting
+	for _, item := range values {
+		key := prefix + fmt.Sprint(item.Key)
+		if nested, ok := item.Value.(yaml.MapSlice); ok && len(nested) > 0 {
+			settings = append(settings, flattenValues(nested, key+".")...)
+			continue
+		}
+		settings = append(settings, Setting{Key: key, Value: fmt.Sprint(item.Value)})
+	}
+	return settings
+}
+
+// flattenKeys lists the leaf keys of the values as sorted dotted paths.
+func flattenKeys(values yaml.MapSlice, prefix string) []string {
+	var keys []string
+	for _, item := range values {
+		key := prefix + fmt.Sprint(item.Key)
+		if nested, ok := item.Value.(yaml.MapSlice); ok && len(nested) > 0 {
+			keys = append(keys, flattenKeys(nested, key+".")...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
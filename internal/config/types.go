@@ -1,10 +1,12 @@
 package config
 
 type Config struct {
-	Input     InputConfig  `yaml:"input"      json:"input"`
-	Output    OutputConfig `yaml:"output"     json:"output"`
-	DevMode   bool         `yaml:"dev_mode"   json:"dev_mode"`
-	PlaySound bool         `yaml:"play_sound" json:"play_sound"`
+	Input       InputConfig       `yaml:"input"       json:"input"`
+	Output      OutputConfig      `yaml:"output"      json:"output"`
+	Interpreter InterpreterConfig `yaml:"interpreter" json:"interpreter"`
+	Prompts     PromptsConfig     `yaml:"prompts"     json:"prompts"`
+	DevMode     bool              `yaml:"dev_mode"    json:"dev_mode"`
+	PlaySound   bool              `yaml:"play_sound"  json:"play_sound"`
 	// TODO(nullswan): Add memory configuration
 }
 
@@ -32,6 +34,64 @@ type SqliteConfig struct {
 	Path    string `yaml:"path"    json:"path"`
 }
 
+type PromptsConfig struct {
+	// RegistryURL serves the index.yml of the registry and its prompts
+	RegistryURL string `yaml:"registry_url" json:"registry_url"`
+	// TrustedKeys verify the signatures of the prompts added from a URL
+	TrustedKeys []TrustedKey `yaml:"trusted_keys" json:"trusted_keys"`
+	// RequireSignature refuses the prompts not signed by a trusted key
+	RequireSignature bool `yaml:"require_signature" json:"require_signature"`
+}
+
+// TrustedKey is the minisign public key of a prompt author.
+type TrustedKey struct {
+	Name string `yaml:"name" json:"name"`
+	// PublicKey is the base64 line of the minisign .pub file
+	PublicKey string `yaml:"public_key" json:"public_key"`
+}
+
 type SpeechConfig struct {
 	Enabled bool `yaml:"enabled" json:"enabled"`
 }
+
+// Manage the execution of model-generated code
+type InterpreterConfig struct {
+	Sandbox SandboxConfig `yaml:"sandbox" json:"sandbox"`
+	// Timeouts of the executions per language, in seconds
+	Timeouts map[string]int `yaml:"timeouts" json:"timeouts"`
+	Approval ApprovalConfig `yaml:"approval" json:"approval"`
+	// SQLDatabase is the SQLite file the sql code runs against, in memory
+	// when empty
+	SQLDatabase string         `yaml:"sql_database" json:"sql_database"`
+	Notebook    NotebookConfig `yaml:"notebook"     json:"notebook"`
+}
+
+type NotebookConfig struct {
+	// AutoExport saves each interpreter session as a Jupyter notebook
+	AutoExport bool `yaml:"auto_export" json:"auto_export"`
+	// Directory of the notebooks, the working directory when empty
+	Directory string `yaml:"directory" json:"directory"`
+}
+
+type ApprovalConfig struct {
+	// Policy is one of always-ask, ask-on-risky or auto
+	Policy string `yaml:"policy" json:"policy"`
+	// Usecases overrides the policy per usecase
+	Usecases map[string]string `yaml:"usecases" json:"usecases"`
+	// AuditLog records the approved and denied executions
+	AuditLog string `yaml:"audit_log" json:"audit_log"`
+}
+
+type SandboxConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Backend is one of auto, bwrap, namespaces or none
+	Backend string `yaml:"backend" json:"backend"`
+	Network bool   `yaml:"network" json:"network"`
+	// CPUTime in seconds, Memory in megabytes, OutputSize in kilobytes
+	CPUTime    int `yaml:"cpu_time"    json:"cpu_time"`
+	Memory     int `yaml:"memory"      json:"memory"`
+	OutputSize int `yaml:"output_size" json:"output_size"`
+	// Exclude lists the languages running on the host, the others run
+	// inside the sandbox
+	Exclude []string `yaml:"exclude" json:"exclude"`
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/minisign"
+	"github.com/nullswan/nomi/internal/sandbox"
+	"github.com/nullswan/nomi/internal/transcription/languages"
+)
+
+// FieldError is an invalid setting.
+type FieldError struct {
+	// Path is the dotted key of the setting, with the list indexes
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists the invalid settings of a configuration.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, "invalid configuration:")
+	for _, fe := range e {
+		lines = append(lines, "  "+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// check validates a setting, cfg is the configuration being validated.
+type check func(cfg *Config, v reflect.Value) error
+
+// approvalPolicies mirrors the policies of the approval package, which
+// depends on this one.
+var approvalPolicies = []string{"always-ask", "ask-on-risky", "auto"}
+
+// schema holds the checks of the settings, by the pattern of their path
+// where map keys are * and list indexes [*].
+var schema = map[string][]check{
+	"input.voice.language": {optional(validLanguage)},
+	"output.sqlite.path": {
+		when(sqliteEnabled, required),
+		when(sqliteEnabled, writableFile),
+	},
+	"interpreter.sandbox.backend": {optional(oneOf(
+		string(sandbox.BackendAuto),
+		string(sandbox.BackendBubblewrap),
+		string(sandbox.BackendNamespaces),
+		string(sandbox.BackendNone),
+	))},
+	"interpreter.sandbox.cpu_time":    {nonNegative},
+	"interpreter.sandbox.memory":      {nonNegative},
+	"interpreter.sandbox.output_size": {nonNegative},
+	"interpreter.sandbox.exclude[*]":  {required},
+	"interpreter.timeouts.*":          {positive},
+	"interpreter.approval.policy":     {optional(oneOf(approvalPolicies...))},
+	"interpreter.approval.usecases.*": {oneOf(approvalPolicies...)},
+	"interpreter.approval.audit_log":  {optional(writableFile)},
+	"interpreter.sql_database":        {optional(writableFile)},
+	"interpreter.notebook.directory":  {optional(directory)},
+	"prompts.registry_url":            {required, httpURL},
+	"prompts.trusted_keys[*].name":    {required},
+	"prompts.trusted_keys[*].public_key": {
+		required,
+		optional(minisignKey),
+	},
+}
+
+// Validate checks the settings against the schema, reporting all the
+// invalid ones.
+func (c *Config) Validate() error {
+	var errs ValidationError
+	walkConfig(
+		reflect.ValueOf(c).Elem(),
+		"",
+		"",
+		func(path, pattern string, v reflect.Value) {
+			for _, validate := range schema[pattern] {
+				if err := validate(c, v); err != nil {
+					errs = append(errs, FieldError{Path: path, Message: err.Error()})
+					break
+				}
+			}
+		},
+	)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func sqliteEnabled(cfg *Config) bool {
+	return cfg.Output.Sqlite.Enabled
+}
+
+// when applies the check only if the condition holds.
+func when(cond func(*Config) bool, c check) check {
+	return func(cfg *Config, v reflect.Value) error {
+		if !cond(cfg) {
+			return nil
+		}
+		return c(cfg, v)
+	}
+}
+
+// optional applies the check to the values which are set.
+func optional(c check) check {
+	return func(cfg *Config, v reflect.Value) error {
+		if v.IsZero() {
+			return nil
+		}
+		return c(cfg, v)
+	}
+}
+
+func required(_ *Config, v reflect.Value) error {
+	if v.IsZero() {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+func oneOf(values ...string) check {
+	return func(_ *Config, v reflect.Value) error {
+		for _, value := range values {
+			if v.String() == value {
+				return nil
+			}
+		}
+		return fmt.Errorf(
+			"invalid value %q, expected one of %s",
+			v.String(),
+			strings.Join(values, ", "),
+		)
+	}
+}
+
+func positive(_ *Config, v reflect.Value) error {
+	if v.Int() <= 0 {
+		return fmt.Errorf("must be positive, got %d", v.Int())
+	}
+	return nil
+}
+
+func nonNegative(_ *Config, v reflect.Value) error {
+	if v.Int() < 0 {
+		return fmt.Errorf("must not be negative, got %d", v.Int())
+	}
+	return nil
+}
+
+func validLanguage(_ *Config, v reflect.Value) error {
+	if _, err := languages.LoadLangFromValue(v.String()); err != nil {
+		return fmt.Errorf("unsupported voice language %q", v.String())
+	}
+	return nil
+}
+
+func httpURL(_ *Config, v reflect.Value) error {
+	u, err := url.Parse(v.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q, expected http or https", v.String())
+	}
+	return nil
+}
+
+func minisignKey(_ *Config, v reflect.Value) error {
+	_, err := minisign.ParsePublicKey(v.String())
+	return err
+}
+
+// writableFile checks the file can be written, or created in the nearest
+// existing directory.
+func writableFile(_ *Config, v reflect.Value) error {
+	path := v.String()
+
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", path)
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return fmt.Errorf("%s is not writable", path)
+		}
+		return f.Close()
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	return writableDirectory(nearestDirectory(filepath.Dir(path)))
+}
+
+// directory checks the directory can be written, or created.
+func directory(_ *Config, v reflect.Value) error {
+	path := v.String()
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return writableDirectory(nearestDirectory(path))
+}
+
+// nearestDirectory returns the path or its nearest existing parent.
+func nearestDirectory(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+func writableDirectory(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".nomi-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable", dir)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
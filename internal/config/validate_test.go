@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		settings map[string]string
+		expected []string
+	}{
+		{
+			name: "Valid",
+		},
+		{
+			name: "Invalid settings",
+			settings: map[string]string{
+				"input.voice.language":        "xx",
+				"output.sqlite.path":          dir,
+				"interpreter.timeouts.go":     "0",
+				"interpreter.sandbox.backend": "docker",
+				"prompts.trusted_keys":        "[{name: ci}]",
+			},
+			expected: []string{
+				"input.voice.language",
+				"output.sqlite.path",
+				"interpreter.sandbox.backend",
+				"interpreter.timeouts.go",
+				"prompts.trusted_keys[0].public_key",
+			},
+		},
+		{
+			name: "Disabled sqlite",
+			settings: map[string]string{
+				"output.sqlite.enabled": "false",
+				"output.sqlite.path":    "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{
+				Input: InputConfig{Voice: VoiceConfig{Language: "en"}},
+				Output: OutputConfig{Sqlite: SqliteConfig{
+					Enabled: true,
+					Path:    filepath.Join(dir, "conversations", "sqlite.db"),
+				}},
+				Prompts: PromptsConfig{RegistryURL: DefaultRegistryURL},
+			}
+			for key, value := range tt.settings {
+				if err := SetValue(&cfg, key, value); err != nil {
+					t.Fatalf("SetValue(%s) error = %v", key, err)
+				}
+			}
+
+			var paths []string
+			var validationErr ValidationError
+			if err := cfg.Validate(); errors.As(err, &validationErr) {
+				for _, fe := range validationErr {
+					paths = append(paths, fe.Path)
+				}
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("Validate() paths = %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Op int
+
+const (
+	OpEqual Op = iota
+	OpInsert
+	OpDelete
+)
+
+// Line of a line-based diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// maxCells bounds the size of the LCS table, bigger inputs are reported as
+// a full replacement.
+const maxCells = 4_000_000
+
+// Lines computes the line-based diff turning a into b.
+func Lines(a, b string) []Line {
+	return Compute(SplitLines(a), SplitLines(b))
+}
+
+// Compute returns the edit script turning a into b, based on their longest
+// common subsequence.
+func Compute(a, b []string) []Line {
+	// Trim the common prefix and suffix, most edits are local
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+	lines = append(lines, lcs(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+
+	return lines
+}
+
+func lcs(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n*m > maxCells {
+		lines := make([]Line, 0, n+m)
+		for _, text := range a {
+			lines = append(lines, Line{Op: OpDelete, Text: text})
+		}
+		for _, text := range b {
+			lines = append(lines, Line{Op: OpInsert, Text: text})
+		}
+		return lines
+	}
+
+	// table[i][j] is the LCS length of a[i:] and b[j:]
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			lines = append(lines, Line{Op: OpDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: OpInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, Line{Op: OpDelete, Text: a[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, Line{Op: OpInsert, Text: b[j]})
+	}
+
+	return lines
+}
+
+// Changed reports whether the diff contains any insertion or deletion.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != OpEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// Hunk is a group of changes with their surrounding context. OldStart and
+// NewStart are 1-based.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+}
+
+// Header of the hunk in a unified diff, such as "@@ -1,3 +1,4 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf(
+		"@@ -%d,%d +%d,%d @@",
+		h.OldStart,
+		h.OldLines,
+		h.NewStart,
+		h.NewLines,
+	)
+}
+
+// Hunks groups the changes of the diff, keeping up to context unchanged
+// lines around each of them.
+func Hunks(lines []Line, context int) []Hunk {
+	// Line numbers in a and b at each index of the diff
+	oldPos := make([]int, len(lines)+1)
+	newPos := make([]int, len(lines)+1)
+	oldPos[0], newPos[0] = 1, 1
+	for i, l := range lines {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if l.Op != OpInsert {
+			oldPos[i+1]++
+		}
+		if l.Op != OpDelete {
+			newPos[i+1]++
+		}
+	}
+
+	// Ranges of the diff to keep, merged when they overlap or touch
+	type span struct{ start, end int }
+	var spans []span
+	for i, l := range lines {
+		if l.Op == OpEqual {
+			continue
+		}
+		s := span{max(i-context, 0), min(i+context+1, len(lines))}
+		if n := len(spans); n > 0 && s.start <= spans[n-1].end {
+			spans[n-1].end = s.end
+			continue
+		}
+		spans = append(spans, s)
+	}
+
+	hunks := make([]Hunk, 0, len(spans))
+	for _, s := range spans {
+		h := Hunk{
+			OldStart: oldPos[s.start],
+			OldLines: oldPos[s.end] - oldPos[s.start],
+			NewStart: newPos[s.start],
+			NewLines: newPos[s.end] - newPos[s.start],
+			Lines:    lines[s.start:s.end],
+		}
+		// Empty ranges start at the line before, as in unified diffs
+		if h.OldLines == 0 {
+			h.OldStart--
+		}
+		if h.NewLines == 0 {
+			h.NewStart--
+		}
+		hunks = append(hunks, h)
+	}
+
+	return hunks
+}
+
+// Format renders the diff with the usual " ", "+" and "-" prefixes.
+func Format(lines []Line) string {
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(l.Op.Prefix())
+		sb.WriteString(l.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Prefix of the operation in a rendered diff.
+func (o Op) Prefix() string {
+	switch o {
+	case OpInsert:
+		return "+"
+	case OpDelete:
+		return "-"
+	default:
+		return " "
+	}
+}
+
+// SplitLines splits the text in lines, ignoring the final newline.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,125 @@
+package diff
+
+import "testing"
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name:     "Identical",
+			a:        "a\nb\n",
+			b:        "a\nb",
+			expected: " a\n b\n",
+		},
+		{
+			name:     "New file",
+			a:        "",
+			b:        "echo hello\nls",
+			expected: "+echo hello\n+ls\n",
+		},
+		{
+			name:     "Replaced line",
+			a:        "a\nb\nc",
+			b:        "a\nB\nc",
+			expected: " a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "Inserted and deleted lines",
+			a:        "a\nb\nc\nd",
+			b:        "x\na\nc\nd\ny",
+			expected: "+x\n a\n-b\n c\n d\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := Lines(tt.a, tt.b)
+			if result := Format(lines); result != tt.expected {
+				t.Errorf("Format(Lines()) = %q, want %q", result, tt.expected)
+			}
+			if changed := Changed(lines); changed != (tt.name != "Identical") {
+				t.Errorf("Changed() = %v", changed)
+			}
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		context  int
+		expected []string
+	}{
+		{
+			name:     "Identical",
+			a:        "a\nb",
+			b:        "a\nb",
+			context:  3,
+			expected: nil,
+		},
+		{
+			name:     "Single change",
+			a:        "1\n2\n3\n4\n5\n6\n7",
+			b:        "1\n2\n3\nfour\n5\n6\n7",
+			context:  1,
+			expected: []string{"@@ -3,3 +3,3 @@\n 3\n-4\n+four\n 5\n"},
+		},
+		{
+			name:    "Distant changes",
+			a:       "1\n2\n3\n4\n5\n6\n7",
+			b:       "one\n2\n3\n4\n5\n6",
+			context: 1,
+			expected: []string{
+				"@@ -1,2 +1,2 @@\n-1\n+one\n 2\n",
+				"@@ -6,2 +6,1 @@\n 6\n-7\n",
+			},
+		},
+		{
+			name:     "Merged changes",
+			a:        "1\n2\n3\n4",
+			b:        "one\n2\n3\nfour",
+			context:  1,
+			expected: []string{"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n"},
+		},
+		{
+			name:     "New file",
+			a:        "",
+			b:        "a\nb",
+			context:  3,
+			expected: []string{"@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hunks := Hunks(Lines(tt.a, tt.b), tt.context)
+
+			var result []string
+			for _, h := range hunks {
+				result = append(result, h.Header()+"\n"+Format(h.Lines))
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Hunks() = %q, want %q", result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("Hunks()[%d] = %q, want %q", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxEntries is the number of entries of the working directory listed.
+const maxEntries = 20
+
+// projectMarkers hint at the kind of project in a directory.
+var projectMarkers = []string{
+	".git", "go.mod", "package.json", "pyproject.toml", "requirements.txt",
+	"setup.py", "Cargo.toml", "Gemfile", "pom.xml", "build.gradle",
+	"composer.json", "Makefile", "Dockerfile", "docker-compose.yml",
+	"compose.yaml", ".venv", "node_modules",
+}
+
+// Directory summarizes the content of a directory.
+type Directory struct {
+	Path  string
+	Files int
+	Dirs  int
+	// Entries are the first visible entries, directories end with a slash
+	Entries []string
+	// Extensions are the most common file extensions, most common first
+	Extensions []string
+	// Markers are the project files found, such as go.mod
+	Markers []string
+}
+
+// SummarizeDirectory lists the top level of the directory, nil when it
+// cannot be read.
+func SummarizeDirectory(path string) *Directory {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+
+	d := &Directory{Path: path}
+	extensions := make(map[string]int)
+	for _, e := range entries {
+		name := e.Name()
+		for _, marker := range projectMarkers {
+			if name == marker {
+				d.Markers = append(d.Markers, name)
+			}
+		}
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if e.IsDir() {
+			d.Dirs++
+			name += "/"
+		} else {
+			d.Files++
+			if ext := filepath.Ext(name); ext != "" {
+				extensions[ext]++
+			}
+		}
+
+		if len(d.Entries) < maxEntries {
+			d.Entries = append(d.Entries, name)
+		}
+	}
+
+	for ext := range extensions {
+		d.Extensions = append(d.Extensions, ext)
+	}
+	sort.Slice(d.Extensions, func(i, j int) bool {
+		a, b := d.Extensions[i], d.Extensions[j]
+		if extensions[a] != extensions[b] {
+			return extensions[a] > extensions[b]
+		}
+		return a < b
+	})
+	if len(d.Extensions) > 5 {
+		d.Extensions = d.Extensions[:5]
+	}
+
+	return d
+}
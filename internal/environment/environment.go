@@ -0,0 +1,112 @@
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"time"
+)
+
+// cacheTTL is how long the probe of the system is reused, it is also
+// refreshed when the PATH changes.
+const cacheTTL = 24 * time.Hour
+
+// Environment describes the machine the generated code runs on.
+type Environment struct {
+	OS     string `json:"os"`
+	Arch   string `json:"arch"`
+	Distro string `json:"distro"`
+	Shell  string `json:"shell"`
+	// Interpreters are the installed interpreters with their version
+	Interpreters []Tool `json:"interpreters"`
+	// Tools are the installed common command line tools
+	Tools          []string `json:"tools"`
+	PythonPackages []string `json:"python_packages"`
+
+	// Path is the PATH the probe ran with
+	Path        string    `json:"path"`
+	CollectedAt time.Time `json:"collected_at"`
+
+	// Directory summarizes the working directory, it is not cached
+	Directory *Directory `json:"-"`
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Load returns the environment, probing the system when the cache at
+// cachePath is missing or stale, along with the working directory.
+func Load(ctx context.Context, cachePath string) (*Environment, error) {
+	env, err := readCache(cachePath)
+	if err != nil || !env.fresh() {
+		env, err = Refresh(ctx, cachePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		env.Directory = SummarizeDirectory(wd)
+	}
+
+	return env, nil
+}
+
+// Refresh probes the system and caches the result at cachePath.
+func Refresh(ctx context.Context, cachePath string) (*Environment, error) {
+	env := Probe(ctx)
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling environment: %w", err)
+	}
+	if err := os.WriteFile(cachePath, data, 0o600); err != nil {
+		return nil, fmt.Errorf("error writing environment cache: %w", err)
+	}
+
+	return env, nil
+}
+
+// Probe inspects the system, without the working directory.
+func Probe(ctx context.Context) *Environment {
+	return &Environment{
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		Distro:         probeDistro(ctx),
+		Shell:          probeShell(),
+		Interpreters:   probeInterpreters(ctx),
+		Tools:          probeTools(),
+		PythonPackages: probePythonPackages(ctx),
+		Path:           os.Getenv("PATH"),
+		CollectedAt:    time.Now().UTC(),
+	}
+}
+
+func readCache(path string) (*Environment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error reading environment cache: %w", err)
+	}
+
+	var env Environment
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("error unmarshalling environment cache: %w", err)
+	}
+
+	return &env, nil
+}
+
+func (e *Environment) fresh() bool {
+	return e.OS == runtime.GOOS &&
+		e.Path == os.Getenv("PATH") &&
+		time.Since(e.CollectedAt) < cacheTTL
+}
@@ -0,0 +1,77 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{output: "Python 3.11.7", expected: "3.11.7"},
+		{output: "go version go1.23.2 linux/amd64", expected: "1.23.2"},
+		{output: "v20.19.5\n", expected: "20.19.5"},
+		{output: "openjdk version \"21.0.1\" 2023-10-17", expected: "21.0.1"},
+		{output: "command not found", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if result := parseVersion(tt.output); result != tt.expected {
+			t.Errorf("parseVersion(%q) = %q, want %q", tt.output, result, tt.expected)
+		}
+	}
+}
+
+func TestSummarizeDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "main.go", "util.go", "README.md", ".env"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{".git", "cmd"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := SummarizeDirectory(dir)
+	expected := &Directory{
+		Path:       dir,
+		Files:      4,
+		Dirs:       1,
+		Entries:    []string{"README.md", "cmd/", "go.mod", "main.go", "util.go"},
+		Extensions: []string{".go", ".md", ".mod"},
+		Markers:    []string{".git", "go.mod"},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("SummarizeDirectory() = %+v, want %+v", d, expected)
+	}
+
+	env := &Environment{
+		OS:           "linux",
+		Arch:         "amd64",
+		Distro:       "Debian GNU/Linux 12",
+		Interpreters: []Tool{{Name: "python3", Version: "3.11.7"}, {Name: "rustc"}},
+		Directory:    d,
+	}
+	summary := env.Summary()
+	for _, want := range []string{
+		"- System: linux/amd64 (Debian GNU/Linux 12)\n",
+		"- Interpreters: python3 3.11.7, rustc\n",
+		"  - Project files: .git, go.mod\n",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, missing %q", summary, want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package environment
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds each command run by the probe.
+const probeTimeout = 3 * time.Second
+
+type interpreter struct {
+	name string
+	args []string
+}
+
+var interpreters = []interpreter{
+	{"python3", []string{"--version"}},
+	{"node", []string{"--version"}},
+	{"ruby", []string{"--version"}},
+	{"go", []string{"version"}},
+	{"perl", []string{"--version"}},
+	{"php", []string{"--version"}},
+	{"java", []string{"-version"}},
+	{"rustc", []string{"--version"}},
+	{"deno", []string{"--version"}},
+	{"bun", []string{"--version"}},
+	{"bash", []string{"--version"}},
+	{"zsh", []string{"--version"}},
+	{"pwsh", []string{"--version"}},
+	{"sqlite3", []string{"--version"}},
+}
+
+var tools = []string{
+	"git", "curl", "wget", "jq", "yq", "rg", "fd", "fzf", "tree", "make",
+	"gcc", "clang", "cmake", "docker", "podman", "kubectl", "helm",
+	"terraform", "aws", "gcloud", "az", "gh", "ssh", "rsync", "tar", "zip",
+	"unzip", "ffmpeg", "convert", "pandoc", "psql", "mysql", "redis-cli",
+	"npm", "pnpm", "yarn", "pip3", "pipx", "uv", "cargo", "brew", "apt",
+	"dnf", "yum", "pacman", "apk", "systemctl", "osascript", "powershell",
+}
+
+var versionPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+func probeInterpreters(ctx context.Context) []Tool {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		found []Tool
+	)
+
+	for _, i := range interpreters {
+		if _, err := exec.LookPath(i.name); err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			output, _ := run(ctx, i.name, i.args...)
+			tool := Tool{Name: i.name, Version: parseVersion(output)}
+
+			mu.Lock()
+			found = append(found, tool)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(a, b int) bool { return found[a].Name < found[b].Name })
+	return found
+}
+
+// parseVersion returns the first version number of the output.
+func parseVersion(output string) string {
+	return versionPattern.FindString(output)
+}
+
+func probeTools() []string {
+	var found []string
+	for _, name := range tools {
+		if _, err := exec.LookPath(name); err == nil {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// pythonPackagesScript lists the names of the installed distributions.
+const pythonPackagesScript = `import importlib.metadata as m
+print("\n".join(sorted({d.metadata["Name"] or "" for d in m.distributions()}, key=str.lower)))`
+
+func probePythonPackages(ctx context.Context) []string {
+	if _, err := exec.LookPath("python3"); err != nil {
+		return nil
+	}
+
+	output, err := run(ctx, "python3", "-c", pythonPackagesScript)
+	if err != nil {
+		return nil
+	}
+
+	var packages []string
+	for _, name := range strings.Split(output, "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			packages = append(packages, name)
+		}
+	}
+	return packages
+}
+
+func probeDistro(ctx context.Context) string {
+	switch runtime.GOOS {
+	case "linux":
+		return osRelease("/etc/os-release")
+	case "darwin":
+		version, err := run(ctx, "sw_vers", "-productVersion")
+		if err != nil {
+			return ""
+		}
+		return "macOS " + strings.TrimSpace(version)
+	case "windows":
+		version, err := run(ctx, "cmd", "/c", "ver")
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(version)
+	default:
+		return ""
+	}
+}
+
+// osRelease returns the PRETTY_NAME of an os-release file.
+func osRelease(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+func probeShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	if runtime.GOOS == "windows" {
+		if os.Getenv("PSModulePath") != "" {
+			return "powershell"
+		}
+		return "cmd"
+	}
+	return ""
+}
+
+// run returns the combined output of the command, some tools print their
+// version on stderr.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	return output.String(), err
+}
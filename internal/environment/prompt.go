@@ -0,0 +1,75 @@
+package environment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxPythonPackages is the number of Python packages listed in the prompt.
+const maxPythonPackages = 100
+
+// Summary describes the environment for a system prompt, as a Markdown
+// list.
+func (e *Environment) Summary() string {
+	var sb strings.Builder
+
+	system := e.OS + "/" + e.Arch
+	if e.Distro != "" {
+		system += " (" + e.Distro + ")"
+	}
+	sb.WriteString("- System: " + system + "\n")
+
+	if e.Shell != "" {
+		sb.WriteString("- Shell: " + e.Shell + "\n")
+	}
+
+	if len(e.Interpreters) > 0 {
+		names := make([]string, len(e.Interpreters))
+		for i, t := range e.Interpreters {
+			names[i] = strings.TrimSpace(t.Name + " " + t.Version)
+		}
+		sb.WriteString("- Interpreters: " + strings.Join(names, ", ") + "\n")
+	}
+
+	if len(e.Tools) > 0 {
+		sb.WriteString("- Tools: " + strings.Join(e.Tools, ", ") + "\n")
+	}
+
+	if len(e.PythonPackages) > 0 {
+		packages := e.PythonPackages
+		more := ""
+		if len(packages) > maxPythonPackages {
+			more = fmt.Sprintf(" and %d more", len(packages)-maxPythonPackages)
+			packages = packages[:maxPythonPackages]
+		}
+		sb.WriteString(
+			"- Python packages: " + strings.Join(packages, ", ") + more + "\n",
+		)
+	}
+
+	if d := e.Directory; d != nil {
+		sb.WriteString(
+			fmt.Sprintf(
+				"- Working directory: %s, %d files and %d directories\n",
+				d.Path,
+				d.Files,
+				d.Dirs,
+			),
+		)
+		if len(d.Markers) > 0 {
+			sb.WriteString("  - Project files: " + strings.Join(d.Markers, ", ") + "\n")
+		}
+		if len(d.Extensions) > 0 {
+			sb.WriteString("  - Main file types: " + strings.Join(d.Extensions, ", ") + "\n")
+		}
+		if len(d.Entries) > 0 {
+			more := ""
+			if n := d.Files + d.Dirs - len(d.Entries); n > 0 {
+				more = fmt.Sprintf(" and %d more", n)
+			}
+			sb.WriteString("  - Content: " + strings.Join(d.Entries, ", ") + more + "\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,257 @@
+// Package evaluation runs the test cases of the prompts against models and
+// checks their answers.
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nullswan/nomi/internal/chat"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+)
+
+// gradingPrompt is the system prompt of the rubric assertions.
+const gradingPrompt = `You grade the answer of an AI assistant against a rubric.
+You are given the input of the user, the answer, and the rubric.
+Reply with a JSON object only: {"pass": true or false, "reason": "<one sentence>"}.
+The answer passes only when it satisfies every point of the rubric.`
+
+// Completer generates the answer of a list of messages.
+type Completer interface {
+	DoMessages(ctx context.Context, messages []chat.Message) (string, error)
+}
+
+// Target is a model the test cases run against.
+type Target struct {
+	// Name identifies the target in the reports, e.g. openai:gpt-4o
+	Name      string
+	Completer Completer
+}
+
+// Runner runs the test cases of a prompt against each target.
+type Runner struct {
+	Targets []Target
+	// Grader grades the rubric assertions, which fail when it is nil
+	Grader Completer
+}
+
+type Report struct {
+	PromptID  string    `json:"prompt_id"`
+	Version   string    `json:"version"`
+	StartedAt time.Time `json:"started_at"`
+	Targets   []string  `json:"targets"`
+	Tests     []string  `json:"tests"`
+	Results   []Result  `json:"results"`
+}
+
+// Result of a test case against a target.
+type Result struct {
+	Test     string   `json:"test"`
+	Target   string   `json:"target"`
+	Passed   bool     `json:"passed"`
+	Output   string   `json:"output"`
+	Failures []string `json:"failures,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	// DurationMS is the time taken by the answer, in milliseconds
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Passed reports whether every test case passed against every target.
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Result returns the result of the test case against the target.
+func (r *Report) Result(test, target string) (Result, bool) {
+	for _, result := range r.Results {
+		if result.Test == test && result.Target == target {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// Run runs the test cases of the prompt, in order, against each target.
+func (r *Runner) Run(
+	ctx context.Context,
+	prompt *prompts.Prompt,
+) (*Report, error) {
+	if len(prompt.Tests) == 0 {
+		return nil, fmt.Errorf("prompt %s has no tests", prompt.ID)
+	}
+	if len(r.Targets) == 0 {
+		return nil, errors.New("no target to run the tests against")
+	}
+
+	report := &Report{
+		PromptID:  prompt.ID,
+		Version:   prompt.Metadata.Version,
+		StartedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	for _, target := range r.Targets {
+		report.Targets = append(report.Targets, target.Name)
+	}
+
+	for _, tc := range prompt.Tests {
+		report.Tests = append(report.Tests, tc.Name)
+
+		messages, err := testMessages(prompt, tc)
+		for _, target := range r.Targets {
+			result := Result{Test: tc.Name, Target: target.Name}
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				r.runTest(ctx, target, tc, messages, &result)
+			}
+			report.Results = append(report.Results, result)
+
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (r *Runner) runTest(
+	ctx context.Context,
+	target Target,
+	tc prompts.TestCase,
+	messages []chat.Message,
+	result *Result,
+) {
+	start := time.Now()
+	output, err := target.Completer.DoMessages(ctx, messages)
+	result.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.Output = output
+
+	for _, a := range tc.Assert {
+		passed, reason, err := r.check(ctx, a, tc.Input, output)
+		switch {
+		case err != nil:
+			result.Failures = append(
+				result.Failures,
+				fmt.Sprintf("%s: %v", a.Type, err),
+			)
+		case !passed:
+			result.Failures = append(
+				result.Failures,
+				fmt.Sprintf("%s: %s", a.Type, reason),
+			)
+		}
+	}
+
+	result.Passed = len(result.Failures) == 0
+}
+
+// testMessages returns the messages of the test case: the rendered prompt
+// followed by the input.
+func testMessages(
+	prompt *prompts.Prompt,
+	tc prompts.TestCase,
+) ([]chat.Message, error) {
+	rendered, err := prompt.Render(prompts.RenderOptions{Vars: tc.Vars})
+	if err != nil {
+		return nil, fmt.Errorf("error rendering prompt: %w", err)
+	}
+
+	messages := chat.PromptMessages(*rendered)
+	return append(messages, chat.NewMessage(chat.RoleUser, tc.Input)), nil
+}
+
+// check returns whether the answer satisfies the assertion, and the reason
+// when it does not.
+func (r *Runner) check(
+	ctx context.Context,
+	a prompts.Assertion,
+	input string,
+	output string,
+) (bool, string, error) {
+	switch a.Type {
+	case prompts.AssertContains:
+		if strings.Contains(output, a.Value) == a.Not {
+			if a.Not {
+				return false, fmt.Sprintf("answer contains %q", a.Value), nil
+			}
+			return false, fmt.Sprintf("answer does not contain %q", a.Value), nil
+		}
+		return true, "", nil
+	case prompts.AssertRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return false, "", err
+		}
+		if re.MatchString(output) == a.Not {
+			if a.Not {
+				return false, fmt.Sprintf("answer matches %q", a.Value), nil
+			}
+			return false, fmt.Sprintf("answer does not match %q", a.Value), nil
+		}
+		return true, "", nil
+	case prompts.AssertJSONSchema:
+		var value interface{}
+		if err := json.Unmarshal([]byte(stripCodeFence(output)), &value); err != nil {
+			return false, fmt.Sprintf("answer is not JSON: %v", err), nil
+		}
+
+		schema, _ := normalize(a.Schema).(map[string]interface{})
+		if violations := validateSchema(schema, value, "$"); len(violations) > 0 {
+			return false, strings.Join(violations, ", "), nil
+		}
+		return true, "", nil
+	case prompts.AssertRubric:
+		return r.grade(ctx, a.Value, input, output)
+	default:
+		return false, "", fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+}
+
+// grade asks the grader whether the answer satisfies the rubric.
+func (r *Runner) grade(
+	ctx context.Context,
+	rubric string,
+	input string,
+	output string,
+) (bool, string, error) {
+	if r.Grader == nil {
+		return false, "", errors.New("no grader model")
+	}
+
+	answer, err := r.Grader.DoMessages(ctx, []chat.Message{
+		chat.NewMessage(chat.RoleSystem, gradingPrompt),
+		chat.NewMessage(chat.RoleUser, fmt.Sprintf(
+			"Input:\n%s\n\nAnswer:\n%s\n\nRubric:\n%s",
+			input,
+			output,
+			rubric,
+		)),
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("error grading answer: %w", err)
+	}
+
+	var grade struct {
+		Pass   bool   `json:"pass"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(answer)), &grade); err != nil {
+		return false, "", fmt.Errorf("invalid grade %q: %w", answer, err)
+	}
+
+	return grade.Pass, grade.Reason, nil
+}
@@ -0,0 +1,144 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/nullswan/nomi/internal/chat"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+	"gopkg.in/yaml.v2"
+)
+
+type mockCompleter struct {
+	answer string
+}
+
+func (m *mockCompleter) DoMessages(
+	_ context.Context,
+	_ []chat.Message,
+) (string, error) {
+	return m.answer, nil
+}
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	var prompt prompts.Prompt
+	err := yaml.Unmarshal([]byte(`
+id: extract
+settings:
+  system_prompt: Extract the city of the text as JSON.
+tests:
+  - name: paris
+    input: I live in Paris.
+    assert:
+      - type: contains
+        value: Paris
+      - type: regex
+        value: (?i)london
+        not: true
+      - type: json_schema
+        schema:
+          type: object
+          required: [city]
+          properties:
+            city:
+              type: string
+      - type: rubric
+        value: The city is Paris.
+`), &prompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &Runner{
+		Targets: []Target{
+			{Name: "good", Completer: &mockCompleter{"```json\n{\"city\": \"Paris\"}\n```"}},
+			{Name: "bad", Completer: &mockCompleter{`{"city": "London", "note": "Paris"}`}},
+		},
+		Grader: &mockCompleter{`{"pass": true, "reason": "ok"}`},
+	}
+
+	report, err := runner.Run(context.Background(), &prompt)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Passed() {
+		t.Error("Passed() = true, want false")
+	}
+
+	if r, _ := report.Result("paris", "good"); !r.Passed {
+		t.Errorf("good target failed: %v", r.Failures)
+	}
+
+	r, _ := report.Result("paris", "bad")
+	expected := []string{`regex: answer matches "(?i)london"`}
+	if r.Passed || !reflect.DeepEqual(r.Failures, expected) {
+		t.Errorf("bad target failures = %q, want %q", r.Failures, expected)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"name", "tags"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string", "minLength": 1},
+			"age":  map[string]interface{}{"type": "integer", "minimum": 0},
+			"tags": map[string]interface{}{
+				"type":     "array",
+				"maxItems": 2,
+				"items":    map[string]interface{}{"enum": []interface{}{"a", "b"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{
+			name:  "Valid",
+			value: `{"name": "x", "age": 3, "tags": ["a"]}`,
+		},
+		{
+			name:  "Violations",
+			value: `{"name": "", "age": 1.5, "tags": ["a", "c", "b"], "extra": 1}`,
+			expected: []string{
+				"$.age: expected integer, got number",
+				"$: unexpected property extra",
+				"$.name: expected at least 1 characters, got 0",
+				"$.tags: expected at most 2 items, got 3",
+				"$.tags[1]: c is not one of [a b]",
+			},
+		},
+		{
+			name:     "Wrong type",
+			value:    `["x"]`,
+			expected: []string{"$: expected object, got array"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+
+			violations := validateSchema(schema, value, "$")
+			if !reflect.DeepEqual(violations, tt.expected) {
+				t.Errorf("validateSchema() = %q, want %q", violations, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package evaluation
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validateSchema checks the value against a subset of JSON Schema: type,
+// enum, const, properties, required, additionalProperties, items, the
+// length and item bounds, minimum, maximum and pattern. It returns the
+// violations, prefixed by their path in the value.
+func validateSchema(
+	schema map[string]interface{},
+	value interface{},
+	path string,
+) []string {
+	var violations []string
+	fail := func(format string, args ...interface{}) {
+		violations = append(
+			violations,
+			path+": "+fmt.Sprintf(format, args...),
+		)
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		fail("expected %v, got %s", t, jsonType(value))
+		return violations
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("%v is not one of %v", value, enum)
+		}
+	}
+
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		fail("expected %v, got %v", c, value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				name := fmt.Sprint(r)
+				if _, ok := v[name]; !ok {
+					fail("missing property %s", name)
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			sub, ok := properties[k].(map[string]interface{})
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok &&
+					!additional {
+					fail("unexpected property %s", k)
+				}
+				continue
+			}
+			violations = append(
+				violations,
+				validateSchema(sub, v[k], path+"."+k)...,
+			)
+		}
+	case []interface{}:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			fail("expected at least %v items, got %d", n, len(v))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail("expected at most %v items, got %d", n, len(v))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				violations = append(
+					violations,
+					validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...,
+				)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			fail("expected at least %v characters, got %v", n, length)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			fail("expected at most %v characters, got %v", n, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				fail("invalid pattern %q: %v", pattern, err)
+			} else if !re.MatchString(v) {
+				fail("%q does not match %q", v, pattern)
+			}
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && v < n {
+			fail("expected at least %v, got %v", n, v)
+		}
+		if n, ok := number(schema["maximum"]); ok && v > n {
+			fail("expected at most %v, got %v", n, v)
+		}
+	}
+
+	return violations
+}
+
+// matchesType reports whether the value is of the type, or of one of the
+// types when a list is given.
+func matchesType(t interface{}, value interface{}) bool {
+	types, ok := t.([]interface{})
+	if !ok {
+		types = []interface{}{t}
+	}
+
+	actual := jsonType(value)
+	for _, t := range types {
+		switch name := fmt.Sprint(t); {
+		case name == actual:
+			return true
+		case name == "number" && actual == "integer":
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func jsonEqual(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// normalize converts the maps decoded from YAML, keyed by interface{}, to
+// the maps decoded from JSON.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = normalize(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[k] = normalize(value)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			l[i] = normalize(value)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+// stripCodeFence returns the content of an answer wrapped in a Markdown
+// code block.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+
+	_, s, _ = strings.Cut(s, "\n")
+	s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,301 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// maxMessageSize bounds a single newline-delimited message.
+const maxMessageSize = 16 * 1024 * 1024
+
+var ErrConnClosed = errors.New("connection closed")
+
+// Handler processes a request and returns a JSON serializable result.
+// Returning an *Error allows to control the error code sent to the peer.
+type Handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Conn is a bidirectional JSON-RPC 2.0 connection over a stream of
+// newline-delimited messages (e.g. the stdio of a child process).
+// Both peers can serve requests and issue calls on the same stream.
+type Conn struct {
+	reader *bufio.Scanner
+	writer io.Writer
+
+	writeMu sync.Mutex
+
+	handlersMu sync.RWMutex
+	handlers   map[string]Handler
+
+	pendingMu sync.Mutex
+	pending   map[string]chan *message
+	nextID    int64
+	closed    bool
+
+	wg sync.WaitGroup
+}
+
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	return &Conn{
+		reader:   scanner,
+		writer:   w,
+		handlers: make(map[string]Handler),
+		pending:  make(map[string]chan *message),
+	}
+}
+
+// Handle registers the handler for the given method.
+func (c *Conn) Handle(method string, handler Handler) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	c.handlers[method] = handler
+}
+
+// Serve reads messages until the stream is closed or the context is done.
+// Requests are dispatched concurrently, responses are routed to the
+// pending calls.
+func (c *Conn) Serve(ctx context.Context) error {
+	defer c.wg.Wait()
+	defer c.closePending()
+
+	lines := make(chan []byte)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for c.reader.Scan() {
+			line := make([]byte, len(c.reader.Bytes()))
+			copy(line, c.reader.Bytes())
+
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errCh <- c.reader.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context done: %w", ctx.Err())
+		case line, ok := <-lines:
+			if !ok {
+				if err := <-errCh; err != nil {
+					return fmt.Errorf("error reading message: %w", err)
+				}
+				return nil
+			}
+
+			if len(line) == 0 {
+				continue
+			}
+
+			c.dispatch(ctx, line)
+		}
+	}
+}
+
+func (c *Conn) dispatch(ctx context.Context, line []byte) {
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		c.reply(nil, nil, NewError(CodeParseError, err.Error()))
+		return
+	}
+
+	if !msg.isRequest() {
+		if msg.ID == nil {
+			return
+		}
+
+		c.pendingMu.Lock()
+		ch, ok := c.pending[string(*msg.ID)]
+		delete(c.pending, string(*msg.ID))
+		c.pendingMu.Unlock()
+
+		if ok {
+			ch <- &msg
+		}
+		return
+	}
+
+	c.handlersMu.RLock()
+	handler, ok := c.handlers[msg.Method]
+	c.handlersMu.RUnlock()
+
+	if !ok {
+		if msg.ID != nil {
+			c.reply(
+				msg.ID,
+				nil,
+				NewError(CodeMethodNotFound, "method not found: "+msg.Method),
+			)
+		}
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		result, err := handler(ctx, msg.Params)
+		if msg.ID == nil {
+			// Notifications do not expect any response
+			return
+		}
+
+		if err != nil {
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = NewError(CodeInternalError, err.Error())
+			}
+			c.reply(msg.ID, nil, rpcErr)
+			return
+		}
+
+		c.reply(msg.ID, result, nil)
+	}()
+}
+
+func (c *Conn) reply(id *json.RawMessage, result interface{}, rpcErr *Error) {
+	msg := message{
+		JSONRPC: Version,
+		ID:      id,
+		Error:   rpcErr,
+	}
+
+	if id == nil {
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			msg.Error = NewError(CodeInternalError, err.Error())
+		} else {
+			msg.Result = data
+		}
+	}
+
+	_ = c.write(msg)
+}
+
+// Call sends a request to the peer and decodes its result into result,
+// which can be nil when the result is not needed.
+func (c *Conn) Call(
+	ctx context.Context,
+	method string,
+	params interface{},
+	result interface{},
+) error {
+	c.pendingMu.Lock()
+	if c.closed {
+		c.pendingMu.Unlock()
+		return ErrConnClosed
+	}
+	c.nextID++
+	id := json.RawMessage(strconv.FormatInt(c.nextID, 10))
+	ch := make(chan *message, 1)
+	c.pending[string(id)] = ch
+	c.pendingMu.Unlock()
+
+	msg, err := newRequest(&id, method, params)
+	if err != nil {
+		return err
+	}
+
+	if err := c.write(msg); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		c.pendingMu.Lock()
+		delete(c.pending, string(id))
+		c.pendingMu.Unlock()
+		return fmt.Errorf("context done: %w", ctx.Err())
+	case resp, ok := <-ch:
+		if !ok {
+			return ErrConnClosed
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("error decoding result: %w", err)
+		}
+		return nil
+	}
+}
+
+// Notify sends a notification, no response is expected from the peer.
+func (c *Conn) Notify(method string, params interface{}) error {
+	msg, err := newRequest(nil, method, params)
+	if err != nil {
+		return err
+	}
+
+	return c.write(msg)
+}
+
+func (c *Conn) write(msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if _, err := c.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing message: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Conn) closePending() {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	c.closed = true
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+func newRequest(
+	id *json.RawMessage,
+	method string,
+	params interface{},
+) (message, error) {
+	msg := message{
+		JSONRPC: Version,
+		ID:      id,
+		Method:  method,
+	}
+
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return msg, fmt.Errorf("error marshalling params: %w", err)
+		}
+		msg.Params = data
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const Version = "2.0"
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// message is the wire representation of any JSON-RPC message.
+// Requests carry a method, responses carry a result or an error,
+// notifications are requests without an ID.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
+func (m *message) isRequest() bool {
+	return m.Method != ""
+}
+
+// Error is a JSON-RPC error object, it can be returned by handlers to
+// control the error code sent to the peer.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+func NewError(code int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// ErrInvalidParams is a helper to report a parameter decoding failure.
+func ErrInvalidParams(err error) *Error {
+	return NewError(CodeInvalidParams, "invalid params: "+err.Error())
+}
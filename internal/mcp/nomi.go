@@ -0,0 +1,512 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/code"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+)
+
+const (
+	conversationURIPrefix = "nomi://conversations/"
+	promptURIPrefix       = "nomi://prompts/"
+	snippetURIPrefix      = "nomi://snippets/"
+	knowledgeURIPrefix    = "nomi://knowledge/"
+
+	defaultSearchLimit = 10
+)
+
+// NomiSources groups the nomi stores exposed through MCP.
+type NomiSources struct {
+	ChatRepo     chat.Repository
+	CodeRepo     code.Repository
+	KnowledgeDir string
+}
+
+// RegisterNomi exposes nomi conversations, prompts, code snippets and
+// knowledge base as MCP tools and resources.
+func RegisterNomi(s *Server, sources NomiSources) {
+	registerConversations(s, sources.ChatRepo)
+	registerPrompts(s)
+	registerSnippets(s, sources.CodeRepo)
+	registerKnowledge(s, sources.KnowledgeDir)
+}
+
+func registerConversations(s *Server, repo chat.Repository) {
+	s.AddTool(Tool{
+		Name:        "search_conversations",
+		Description: "Search stored conversations containing the given text.",
+		InputSchema: withPropertyType(
+			ObjectSchema(
+				map[string]string{
+					"query": "Text to look for in the conversation messages",
+					"limit": "Maximum number of conversations to return",
+				},
+				"query",
+			),
+			"limit",
+			"integer",
+		),
+		Handler: func(_ context.Context, args json.RawMessage) (string, error) {
+			var req struct {
+				Query string      `json:"query"`
+				Limit json.Number `json:"limit"`
+			}
+			if err := json.Unmarshal(args, &req); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+
+			limit := defaultSearchLimit
+			if l, err := req.Limit.Int64(); err == nil && l > 0 {
+				limit = int(l)
+			}
+
+			results, err := repo.SearchConversations(req.Query, limit)
+			if err != nil {
+				return "", fmt.Errorf("error searching: %w", err)
+			}
+
+			var sb strings.Builder
+			for _, result := range results {
+				sb.WriteString(fmt.Sprintf(
+					"%s (%s, %d messages): %s\n",
+					result.ID,
+					result.CreatedAt.Format(time.RFC3339),
+					result.Messages,
+					matchingExcerpt(result.Match, req.Query),
+				))
+			}
+
+			if sb.Len() == 0 {
+				return "No conversation found.", nil
+			}
+			return sb.String(), nil
+		},
+	})
+
+	s.AddTool(Tool{
+		Name:        "read_conversation",
+		Description: "Read all messages of a stored conversation.",
+		InputSchema: ObjectSchema(
+			map[string]string{"id": "Conversation ID"},
+			"id",
+		),
+		Handler: func(_ context.Context, args json.RawMessage) (string, error) {
+			var req struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(args, &req); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+
+			convo, err := repo.LoadConversation(req.ID)
+			if err != nil {
+				return "", fmt.Errorf("error loading conversation: %w", err)
+			}
+
+			return formatConversation(convo), nil
+		},
+	})
+
+	s.AddResourceSource(ResourceSource{
+		Prefix: conversationURIPrefix,
+		List: func() ([]Resource, error) {
+			convos, err := repo.GetConversations()
+			if err != nil {
+				return nil, fmt.Errorf("error listing conversations: %w", err)
+			}
+
+			resources := make([]Resource, 0, len(convos))
+			for _, convo := range convos {
+				resources = append(resources, Resource{
+					URI:  conversationURIPrefix + convo.GetID(),
+					Name: "Conversation " + convo.GetID(),
+					Description: "Created at " +
+						convo.GetCreatedAt().Format(time.RFC3339),
+					MimeType: "text/markdown",
+				})
+			}
+			return resources, nil
+		},
+		Read: func(uri string) (ResourceContent, error) {
+			id := strings.TrimPrefix(uri, conversationURIPrefix)
+			convo, err := repo.LoadConversation(id)
+			if err != nil {
+				return ResourceContent{}, fmt.Errorf(
+					"error loading conversation: %w",
+					err,
+				)
+			}
+
+			return ResourceContent{
+				URI:      uri,
+				MimeType: "text/markdown",
+				Text:     formatConversation(convo),
+			}, nil
+		},
+	})
+}
+
+func registerPrompts(s *Server) {
+	s.AddTool(Tool{
+		Name:        "list_prompts",
+		Description: "List the prompts installed in nomi.",
+		Handler: func(_ context.Context, _ json.RawMessage) (string, error) {
+			allPrompts, err := prompts.ListPrompts()
+			if err != nil {
+				return "", fmt.Errorf("error listing prompts: %w", err)
+			}
+
+			var sb strings.Builder
+			for _, prompt := range allPrompts {
+				sb.WriteString(fmt.Sprintf(
+					"%s - %s: %s\n",
+					prompt.ID,
+					prompt.Name,
+					prompt.Description,
+				))
+			}
+
+			if sb.Len() == 0 {
+				return "No prompt installed.", nil
+			}
+			return sb.String(), nil
+		},
+	})
+
+	s.AddResourceSource(ResourceSource{
+		Prefix: promptURIPrefix,
+		List: func() ([]Resource, error) {
+			allPrompts, err := prompts.ListPrompts()
+			if err != nil {
+				return nil, fmt.Errorf("error listing prompts: %w", err)
+			}
+
+			resources := make([]Resource, 0, len(allPrompts))
+			for _, prompt := range allPrompts {
+				resources = append(resources, Resource{
+					URI:         promptURIPrefix + prompt.ID,
+					Name:        prompt.Name,
+					Description: prompt.Description,
+					MimeType:    "application/yaml",
+				})
+			}
+			return resources, nil
+		},
+		Read: func(uri string) (ResourceContent, error) {
+			prompt, err := prompts.LoadPrompt(
+				strings.TrimPrefix(uri, promptURIPrefix),
+			)
+			if err != nil {
+				return ResourceContent{}, fmt.Errorf(
+					"error loading prompt: %w",
+					err,
+				)
+			}
+
+			data, err := yaml.Marshal(prompt)
+			if err != nil {
+				return ResourceContent{}, fmt.Errorf(
+					"error marshalling prompt: %w",
+					err,
+				)
+			}
+
+			return ResourceContent{
+				URI:      uri,
+				MimeType: "application/yaml",
+				Text:     string(data),
+			}, nil
+		},
+	})
+}
+
+func registerSnippets(s *Server, repo code.Repository) {
+	s.AddTool(Tool{
+		Name:        "list_snippets",
+		Description: "List the code snippets saved by the nomi interpreter.",
+		Handler: func(_ context.Context, _ json.RawMessage) (string, error) {
+			blocks, err := repo.LoadCodeBlocks()
+			if err != nil {
+				return "", fmt.Errorf("error listing snippets: %w", err)
+			}
+
+			var sb strings.Builder
+			for _, block := range blocks {
+				sb.WriteString(fmt.Sprintf(
+					"%s [%s]: %s\n",
+					block.ID,
+					block.Language,
+					block.Description,
+				))
+			}
+
+			if sb.Len() == 0 {
+				return "No snippet saved.", nil
+			}
+			return sb.String(), nil
+		},
+	})
+
+	s.AddTool(Tool{
+		Name:        "get_snippet",
+		Description: "Get the code of a saved snippet.",
+		InputSchema: ObjectSchema(
+			map[string]string{"id": "Snippet ID"},
+			"id",
+		),
+		Handler: func(_ context.Context, args json.RawMessage) (string, error) {
+			var req struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(args, &req); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+
+			block, err := repo.LoadCodeBlock(req.ID)
+			if err != nil {
+				return "", fmt.Errorf("error loading snippet: %w", err)
+			}
+
+			return formatCodeBlock(block), nil
+		},
+	})
+
+	s.AddResourceSource(ResourceSource{
+		Prefix: snippetURIPrefix,
+		List: func() ([]Resource, error) {
+			blocks, err := repo.LoadCodeBlocks()
+			if err != nil {
+				return nil, fmt.Errorf("error listing snippets: %w", err)
+			}
+
+			resources := make([]Resource, 0, len(blocks))
+			for _, block := range blocks {
+				resources = append(resources, Resource{
+					URI:         snippetURIPrefix + block.ID,
+					Name:        block.Language + " snippet " + block.ID,
+					Description: block.Description,
+					MimeType:    "text/markdown",
+				})
+			}
+			return resources, nil
+		},
+		Read: func(uri string) (ResourceContent, error) {
+			block, err := repo.LoadCodeBlock(
+				strings.TrimPrefix(uri, snippetURIPrefix),
+			)
+			if err != nil {
+				return ResourceContent{}, fmt.Errorf(
+					"error loading snippet: %w",
+					err,
+				)
+			}
+
+			return ResourceContent{
+				URI:      uri,
+				MimeType: "text/markdown",
+				Text:     formatCodeBlock(block),
+			}, nil
+		},
+	})
+}
+
+func registerKnowledge(s *Server, knowledgeDir string) {
+	s.AddTool(Tool{
+		Name:        "list_knowledge",
+		Description: "List the documents of the nomi knowledge base.",
+		Handler: func(_ context.Context, _ json.RawMessage) (string, error) {
+			files, err := listKnowledgeFiles(knowledgeDir)
+			if err != nil {
+				return "", err
+			}
+
+			if len(files) == 0 {
+				return "The knowledge base is empty.", nil
+			}
+			return strings.Join(files, "\n"), nil
+		},
+	})
+
+	s.AddTool(Tool{
+		Name:        "read_knowledge",
+		Description: "Read a document of the nomi knowledge base.",
+		InputSchema: ObjectSchema(
+			map[string]string{
+				"path": "Path of the document, relative to the knowledge base",
+			},
+			"path",
+		),
+		Handler: func(_ context.Context, args json.RawMessage) (string, error) {
+			var req struct {
+				Path string `json:"path"`
+			}
+			if err := json.Unmarshal(args, &req); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+
+			return readKnowledgeFile(knowledgeDir, req.Path)
+		},
+	})
+
+	s.AddResourceSource(ResourceSource{
+		Prefix: knowledgeURIPrefix,
+		List: func() ([]Resource, error) {
+			files, err := listKnowledgeFiles(knowledgeDir)
+			if err != nil {
+				return nil, err
+			}
+
+			resources := make([]Resource, 0, len(files))
+			for _, file := range files {
+				resources = append(resources, Resource{
+					URI:      knowledgeURIPrefix + file,
+					Name:     file,
+					MimeType: "text/plain",
+				})
+			}
+			return resources, nil
+		},
+		Read: func(uri string) (ResourceContent, error) {
+			text, err := readKnowledgeFile(
+				knowledgeDir,
+				strings.TrimPrefix(uri, knowledgeURIPrefix),
+			)
+			if err != nil {
+				return ResourceContent{}, err
+			}
+
+			return ResourceContent{
+				URI:      uri,
+				MimeType: "text/plain",
+				Text:     text,
+			}, nil
+		},
+	})
+}
+
+func listKnowledgeFiles(knowledgeDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(
+		knowledgeDir,
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Symlinks cannot be read, see readKnowledgeFile
+			if d.IsDir() || d.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+
+			rel, err := filepath.Rel(knowledgeDir, path)
+			if err != nil {
+				return fmt.Errorf("error computing relative path: %w", err)
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing knowledge base: %w", err)
+	}
+
+	return files, nil
+}
+
+func readKnowledgeFile(knowledgeDir, name string) (string, error) {
+	path := filepath.Join(knowledgeDir, filepath.FromSlash(name))
+
+	// Do not allow escaping the knowledge directory
+	rel, err := filepath.Rel(knowledgeDir, path)
+	if err != nil || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("invalid knowledge path: " + name)
+	}
+
+	// Nor through a symlink, in the path or in its directories
+	dir := knowledgeDir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return "", fmt.Errorf("error reading knowledge file: %w", err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", errors.New("invalid knowledge path, symlink: " + name)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading knowledge file: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func formatConversation(convo chat.Conversation) string {
+	var sb strings.Builder
+	for _, msg := range convo.GetMessages() {
+		sb.WriteString("## " + msg.Role.String() + "\n\n")
+		sb.WriteString(msg.Content + "\n\n")
+	}
+
+	return sb.String()
+}
+
+func formatCodeBlock(block code.CodeBlock) string {
+	return block.Description + "\n\n```" + block.Language + "\n" +
+		block.Code + "\n```\n"
+}
+
+func matchingExcerpt(content, query string) string {
+	const excerptRadius = 60
+
+	idx, n := indexFold(content, query)
+	if idx < 0 {
+		return ""
+	}
+
+	// Cut on rune boundaries
+	start := max(idx-excerptRadius, 0)
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	end := min(idx+n+excerptRadius, len(content))
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
+	}
+
+	excerpt := strings.ReplaceAll(content[start:end], "\n", " ")
+	return "..." + excerpt + "..."
+}
+
+// indexFold returns the byte offset and length of the first case-insensitive
+// match of query in s, -1 when there is none. The match can have a different
+// length than the query.
+func indexFold(s, query string) (int, int) {
+	runes := utf8.RuneCountInString(query)
+	for i := range s {
+		end := i
+		for n := 0; n < runes && end < len(s); n++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		if strings.EqualFold(s[i:end], query) {
+			return i, end - i
+		}
+	}
+	return -1, 0
+}
@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nullswan/nomi/internal/chat"
+)
+
+func TestMatchingExcerpt(t *testing.T) {
+	t.Parallel()
+
+	euros := strings.Repeat("€", 50)
+	tests := []struct {
+		name     string
+		content  string
+		query    string
+		expected string
+	}{
+		{name: "ascii", content: "Deploy the service", query: "DEPLOY", expected: "...Deploy the service..."},
+		{name: "no match", content: "Deploy the service", query: "build", expected: ""},
+		{name: "newlines", content: "first\nsecond", query: "second", expected: "...first second..."},
+		// The lowered İ is longer than the original
+		{name: "longer lowered", content: "İİİİ Straße", query: "straße", expected: "...İİİİ Straße..."},
+		{name: "folded query", content: "Ünïcode", query: "üNÏ", expected: "...Ünïcode..."},
+		{
+			name:     "rune boundaries",
+			content:  euros + "éx" + euros,
+			query:    "X",
+			expected: "..." + strings.Repeat("€", 20) + "éx" + strings.Repeat("€", 20) + "...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if excerpt := matchingExcerpt(tt.content, tt.query); excerpt != tt.expected {
+				t.Errorf("matchingExcerpt() = %q, want %q", excerpt, tt.expected)
+			}
+		})
+	}
+}
+
+// fakeChatRepository serves the conversations of a search.
+type fakeChatRepository struct {
+	chat.Repository
+
+	results []chat.SearchResult
+	query   string
+	limit   int
+}
+
+func (r *fakeChatRepository) SearchConversations(
+	query string,
+	limit int,
+) ([]chat.SearchResult, error) {
+	r.query, r.limit = query, limit
+	return r.results, nil
+}
+
+func TestSearchConversationsTool(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeChatRepository{
+		results: []chat.SearchResult{{
+			ID:        "sc_1",
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Messages:  3,
+			Match:     "How do I deploy the service?",
+		}},
+	}
+	s := NewServer("nomi", "test")
+	registerConversations(s, repo)
+
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_conversations","arguments":{"query":"DEPLOY","limit":5}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search_conversations","arguments":{"query":1}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	type response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	responses := make(map[int]response)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r response
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses[r.ID] = r
+	}
+
+	var list struct {
+		Tools []struct {
+			Name        string `json:"name"`
+			InputSchema struct {
+				Properties map[string]struct {
+					Type string `json:"type"`
+				} `json:"properties"`
+			} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[1].Result, &list); err != nil {
+		t.Fatalf("invalid tools/list result: %v", err)
+	}
+	for _, tool := range list.Tools {
+		if tool.Name != "search_conversations" {
+			continue
+		}
+		if typ := tool.InputSchema.Properties["limit"].Type; typ != "integer" {
+			t.Errorf("limit type = %q, want integer", typ)
+		}
+		if typ := tool.InputSchema.Properties["query"].Type; typ != "string" {
+			t.Errorf("query type = %q, want string", typ)
+		}
+	}
+
+	var call callToolResult
+	if err := json.Unmarshal(responses[2].Result, &call); err != nil {
+		t.Fatalf("invalid tools/call result: %v", err)
+	}
+	if call.IsError || len(call.Content) != 1 ||
+		call.Content[0].Text != "sc_1 (2024-01-02T03:04:05Z, 3 messages): ...How do I deploy the service?...\n" {
+		t.Errorf("search_conversations = %+v", call)
+	}
+	if repo.query != "DEPLOY" || repo.limit != 5 {
+		t.Errorf("SearchConversations(%q, %d), want (%q, 5)", repo.query, repo.limit, "DEPLOY")
+	}
+
+	// Tool errors are reported in the result
+	if err := json.Unmarshal(responses[3].Result, &call); err != nil || !call.IsError {
+		t.Errorf("search_conversations with invalid arguments = %+v, %v", call, err)
+	}
+
+	if responses[4].Error == nil ||
+		!strings.Contains(responses[4].Error.Message, "unknown tool") {
+		t.Errorf("unknown tool error = %+v", responses[4].Error)
+	}
+}
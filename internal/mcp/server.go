@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/jsonrpc"
+)
+
+// Server is a Model Context Protocol server exposing tools and resources
+// over a newline-delimited JSON-RPC stream (usually stdio).
+type Server struct {
+	name    string
+	version string
+
+	tools   map[string]Tool
+	sources []ResourceSource
+}
+
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:    name,
+		version: version,
+		tools:   make(map[string]Tool),
+	}
+}
+
+func (s *Server) AddTool(tool Tool) {
+	if tool.InputSchema == nil {
+		tool.InputSchema = ObjectSchema(nil)
+	}
+
+	s.tools[tool.Name] = tool
+}
+
+func (s *Server) AddResourceSource(source ResourceSource) {
+	s.sources = append(s.sources, source)
+}
+
+// Serve handles requests until the input stream is closed.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	conn := jsonrpc.NewConn(r, w)
+
+	conn.Handle("initialize", s.handleInitialize)
+	conn.Handle("notifications/initialized", noop)
+	conn.Handle("notifications/cancelled", noop)
+	conn.Handle("ping", func(
+		_ context.Context,
+		_ json.RawMessage,
+	) (interface{}, error) {
+		return struct{}{}, nil
+	})
+	conn.Handle("tools/list", s.handleListTools)
+	conn.Handle("tools/call", s.handleCallTool)
+	conn.Handle("resources/list", s.handleListResources)
+	conn.Handle("resources/read", s.handleReadResource)
+
+	if err := conn.Serve(ctx); err != nil {
+		return fmt.Errorf("error serving mcp: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) handleInitialize(
+	_ context.Context,
+	_ json.RawMessage,
+) (interface{}, error) {
+	return initializeResult{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities: map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		ServerInfo: implementation{
+			Name:    s.name,
+			Version: s.version,
+		},
+	}, nil
+}
+
+func (s *Server) handleListTools(
+	_ context.Context,
+	_ json.RawMessage,
+) (interface{}, error) {
+	tools := make([]Tool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		tools = append(tools, tool)
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+
+	return listToolsResult{Tools: tools}, nil
+}
+
+func (s *Server) handleCallTool(
+	ctx context.Context,
+	params json.RawMessage,
+) (interface{}, error) {
+	var req callToolParams
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	tool, ok := s.tools[req.Name]
+	if !ok {
+		return nil, jsonrpc.NewError(
+			jsonrpc.CodeInvalidParams,
+			"unknown tool: "+req.Name,
+		)
+	}
+
+	if len(req.Arguments) == 0 {
+		req.Arguments = json.RawMessage("{}")
+	}
+
+	// Tool errors are reported in the result so the model can see them
+	text, err := tool.Handler(ctx, req.Arguments)
+	if err != nil {
+		return callToolResult{
+			Content: []textContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	return callToolResult{
+		Content: []textContent{{Type: "text", Text: text}},
+	}, nil
+}
+
+func (s *Server) handleListResources(
+	_ context.Context,
+	_ json.RawMessage,
+) (interface{}, error) {
+	resources := make([]Resource, 0)
+	for _, source := range s.sources {
+		list, err := source.List()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error listing %s resources: %w",
+				source.Prefix,
+				err,
+			)
+		}
+
+		resources = append(resources, list...)
+	}
+
+	return listResourcesResult{Resources: resources}, nil
+}
+
+func (s *Server) handleReadResource(
+	_ context.Context,
+	params json.RawMessage,
+) (interface{}, error) {
+	var req readResourceParams
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	for _, source := range s.sources {
+		if !strings.HasPrefix(req.URI, source.Prefix) {
+			continue
+		}
+
+		content, err := source.Read(req.URI)
+		if err != nil {
+			return nil, fmt.Errorf("error reading resource: %w", err)
+		}
+
+		return readResourceResult{
+			Contents: []ResourceContent{content},
+		}, nil
+	}
+
+	return nil, jsonrpc.NewError(
+		jsonrpc.CodeInvalidParams,
+		"unknown resource: "+req.URI,
+	)
+}
+
+func noop(_ context.Context, _ json.RawMessage) (interface{}, error) {
+	return nil, nil // nolint: nilnil
+}
+
+// ObjectSchema builds a JSON schema for an object whose properties are all
+// strings, keyed by name with their description.
+func ObjectSchema(
+	properties map[string]string,
+	required ...string,
+) map[string]interface{} {
+	props := make(map[string]interface{}, len(properties))
+	for name, description := range properties {
+		props[name] = map[string]interface{}{
+			"type":        "string",
+			"description": description,
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// withPropertyType sets the type of a property of an object schema.
+func withPropertyType(
+	schema map[string]interface{},
+	name, typ string,
+) map[string]interface{} {
+	props, _ := schema["properties"].(map[string]interface{})
+	if prop, ok := props[name].(map[string]interface{}); ok {
+		prop["type"] = typ
+	}
+
+	return schema
+}
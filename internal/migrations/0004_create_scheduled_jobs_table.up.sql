@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP,
+  name TEXT,
+  cron TEXT,
+  kind VARCHAR(50),
+  target TEXT,
+  input TEXT,
+  vars TEXT,
+  enabled BOOLEAN,
+  next_run_at TIMESTAMP,
+  last_run_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_runs (
+  id UUID PRIMARY KEY,
+  job_id UUID,
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP,
+  status VARCHAR(50),
+  conversation_id UUID,
+  error TEXT,
+  FOREIGN KEY(job_id) REFERENCES scheduled_jobs(id) ON DELETE CASCADE
+);
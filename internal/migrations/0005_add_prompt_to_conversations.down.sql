@@ -0,0 +1,2 @@
+ALTER TABLE conversations DROP COLUMN prompt_version;
+ALTER TABLE conversations DROP COLUMN prompt_id;
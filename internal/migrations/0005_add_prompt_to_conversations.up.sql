@@ -0,0 +1,2 @@
+ALTER TABLE conversations ADD COLUMN prompt_id TEXT;
+ALTER TABLE conversations ADD COLUMN prompt_version TEXT;
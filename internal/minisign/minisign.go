@@ -0,0 +1,153 @@
+// Package minisign verifies the signatures of minisign
+// (https://jedisct1.github.io/minisign/), Ed25519 signatures of a file or
+// of its BLAKE2b-512 hash, with a trusted comment.
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// algorithmLegacy signs the file itself, minisign -l
+	algorithmLegacy = "Ed"
+	// algorithmHashed signs the BLAKE2b-512 hash of the file, the default
+	algorithmHashed = "ED"
+
+	untrustedCommentPrefix = "untrusted comment:"
+	trustedCommentPrefix   = "trusted comment: "
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUnknownKey       = errors.New("signature of an unknown key")
+)
+
+// PublicKey is an Ed25519 key and its minisign key ID.
+type PublicKey struct {
+	ID  [8]byte
+	Key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a public key, given as the base64 line of a
+// minisign .pub file or as the whole file.
+func ParsePublicKey(s string) (PublicKey, error) {
+	data, err := decodeLine(lastLine(s))
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(data) != 2+8+ed25519.PublicKeySize ||
+		string(data[:2]) != algorithmLegacy {
+		return PublicKey{}, errors.New("invalid public key: not a minisign key")
+	}
+
+	var pk PublicKey
+	copy(pk.ID[:], data[2:10])
+	pk.Key = ed25519.PublicKey(data[10:])
+
+	return pk, nil
+}
+
+// KeyID formats the ID as minisign does.
+func (pk PublicKey) KeyID() string {
+	return formatKeyID(pk.ID)
+}
+
+// Verify checks the signature of the message and of its trusted comment.
+func (pk PublicKey) Verify(message []byte, sig Signature) error {
+	if sig.KeyID != pk.ID {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, formatKeyID(sig.KeyID))
+	}
+
+	signed := message
+	if sig.Algorithm == algorithmHashed {
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	}
+	if !ed25519.Verify(pk.Key, signed, sig.Signature) {
+		return ErrInvalidSignature
+	}
+
+	global := append(bytes.Clone(sig.Signature), sig.TrustedComment...)
+	if !ed25519.Verify(pk.Key, global, sig.GlobalSignature) {
+		return fmt.Errorf("%w: trusted comment", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// Signature is the content of a .minisig file.
+type Signature struct {
+	Algorithm       string
+	KeyID           [8]byte
+	Signature       []byte
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+// ParseSignature parses a .minisig file.
+func ParseSignature(data []byte) (Signature, error) {
+	lines := strings.Split(
+		strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n")),
+		"\n",
+	)
+	if len(lines) != 4 ||
+		!strings.HasPrefix(lines[0], untrustedCommentPrefix) ||
+		!strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return Signature{}, fmt.Errorf("%w: malformed file", ErrInvalidSignature)
+	}
+
+	data, err := decodeLine(lines[1])
+	if err != nil || len(data) != 2+8+ed25519.SignatureSize {
+		return Signature{}, fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	var sig Signature
+	sig.Algorithm = string(data[:2])
+	if sig.Algorithm != algorithmLegacy && sig.Algorithm != algorithmHashed {
+		return Signature{}, fmt.Errorf(
+			"%w: unsupported algorithm %q",
+			ErrInvalidSignature,
+			sig.Algorithm,
+		)
+	}
+	copy(sig.KeyID[:], data[2:10])
+	sig.Signature = data[10:]
+	sig.TrustedComment = strings.TrimPrefix(lines[2], trustedCommentPrefix)
+
+	sig.GlobalSignature, err = decodeLine(lines[3])
+	if err != nil || len(sig.GlobalSignature) != ed25519.SignatureSize {
+		return Signature{}, fmt.Errorf(
+			"%w: malformed global signature",
+			ErrInvalidSignature,
+		)
+	}
+
+	return sig, nil
+}
+
+// KeyIDString formats the ID of the signing key as minisign does.
+func (s Signature) KeyIDString() string {
+	return formatKeyID(s.KeyID)
+}
+
+// formatKeyID prints the little-endian key ID as an hexadecimal number.
+func formatKeyID(id [8]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+func decodeLine(line string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
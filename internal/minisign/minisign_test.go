@@ -0,0 +1,101 @@
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	message := []byte("id: demo\n")
+
+	encodedKey := base64.StdEncoding.EncodeToString(
+		append(append([]byte("Ed"), keyID...), pub...),
+	)
+	pk, err := ParsePublicKey("untrusted comment: minisign public key\n" + encodedKey)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	if pk.KeyID() != "0807060504030201" {
+		t.Errorf("KeyID() = %s", pk.KeyID())
+	}
+
+	sign := func(algorithm string, message []byte, comment string) []byte {
+		signed := message
+		if algorithm == algorithmHashed {
+			sum := blake2b.Sum512(message)
+			signed = sum[:]
+		}
+		sig := ed25519.Sign(priv, signed)
+		global := ed25519.Sign(priv, append(bytes.Clone(sig), comment...))
+
+		return []byte("untrusted comment: signature\n" +
+			base64.StdEncoding.EncodeToString(
+				append(append([]byte(algorithm), keyID...), sig...),
+			) + "\n" +
+			trustedCommentPrefix + comment + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n")
+	}
+
+	tests := []struct {
+		name    string
+		file    []byte
+		message []byte
+		wantErr error
+	}{
+		{
+			name:    "Hashed",
+			file:    sign(algorithmHashed, message, "timestamp:1"),
+			message: message,
+		},
+		{
+			name:    "Legacy",
+			file:    sign(algorithmLegacy, message, "timestamp:1"),
+			message: message,
+		},
+		{
+			name:    "Tampered",
+			file:    sign(algorithmHashed, message, "timestamp:1"),
+			message: []byte("id: evil\n"),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "Tampered comment",
+			file: bytes.Replace(
+				sign(algorithmHashed, message, "timestamp:1"),
+				[]byte("timestamp:1"),
+				[]byte("timestamp:2"),
+				1,
+			),
+			message: message,
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sig, err := ParseSignature(tt.file)
+			if err != nil {
+				t.Fatalf("ParseSignature() error = %v", err)
+			}
+
+			err = pk.Verify(tt.message, sig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package notebook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Notebook in the nbformat v4 format, with a Python kernel. The code of the
+// other languages runs through cell magics when IPython has one.
+type Notebook struct {
+	Cells         []Cell         `json:"cells"`
+	Metadata      map[string]any `json:"metadata"`
+	NBFormat      int            `json:"nbformat"`
+	NBFormatMinor int            `json:"nbformat_minor"`
+}
+
+type CellType string
+
+const (
+	CellTypeMarkdown CellType = "markdown"
+	CellTypeCode     CellType = "code"
+)
+
+type Cell struct {
+	ID       string
+	Type     CellType
+	Metadata map[string]any
+	Source   string
+	// ExecutionCount and Outputs are only set for code cells
+	ExecutionCount int
+	Outputs        []Output
+}
+
+// Output of a code cell, a stream or an error.
+type Output struct {
+	OutputType string   `json:"output_type"`
+	Name       string   `json:"name,omitempty"`
+	Text       []string `json:"text,omitempty"`
+	EName      string   `json:"ename,omitempty"`
+	EValue     string   `json:"evalue,omitempty"`
+	Traceback  []string `json:"traceback,omitempty"`
+}
+
+// cellMagics run the code of the other languages in a Python kernel.
+var cellMagics = map[string]string{
+	"bash":       "%%bash",
+	"sh":         "%%sh",
+	"ruby":       "%%ruby",
+	"perl":       "%%perl",
+	"javascript": "%%script node",
+}
+
+func New() *Notebook {
+	return &Notebook{
+		Cells: []Cell{},
+		Metadata: map[string]any{
+			"kernelspec": map[string]string{
+				"name":         "python3",
+				"display_name": "Python 3",
+				"language":     "python",
+			},
+			"language_info": map[string]string{
+				"name": "python",
+			},
+		},
+		NBFormat:      4,
+		NBFormatMinor: 5,
+	}
+}
+
+func (nb *Notebook) AddMarkdown(source string) {
+	nb.Cells = append(nb.Cells, Cell{
+		ID:     nb.nextID(),
+		Type:   CellTypeMarkdown,
+		Source: source,
+	})
+}
+
+// AddCode adds a code cell and returns its index, the code of languages
+// other than Python is prefixed by their cell magic.
+func (nb *Notebook) AddCode(language, source string) int {
+	cell := Cell{
+		ID:       nb.nextID(),
+		Type:     CellTypeCode,
+		Metadata: map[string]any{},
+		Source:   source,
+	}
+
+	if language != "" && language != "python" {
+		cell.Metadata["language"] = language
+		if magic, ok := cellMagics[language]; ok {
+			cell.Source = magic + "\n" + source
+		}
+	}
+
+	nb.Cells = append(nb.Cells, cell)
+	return len(nb.Cells) - 1
+}
+
+// SetOutputs sets the outputs of the code cell at index, which is counted
+// as executed.
+func (nb *Notebook) SetOutputs(index int, outputs ...Output) {
+	count := 0
+	for _, c := range nb.Cells {
+		count = max(count, c.ExecutionCount)
+	}
+
+	nb.Cells[index].ExecutionCount = count + 1
+	nb.Cells[index].Outputs = outputs
+}
+
+// StreamOutput is the text written to stdout or stderr.
+func StreamOutput(name, text string) Output {
+	return Output{OutputType: "stream", Name: name, Text: splitSource(text)}
+}
+
+// ErrorOutput is a failed execution.
+func ErrorOutput(name, value string) Output {
+	return Output{
+		OutputType: "error",
+		EName:      name,
+		EValue:     value,
+		Traceback:  []string{name + ": " + value},
+	}
+}
+
+// WriteFile writes the notebook as JSON.
+func (nb *Notebook) WriteFile(path string) error {
+	data, err := marshal(nb)
+	if err != nil {
+		return fmt.Errorf("error marshalling notebook: %w", err)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", " "); err != nil {
+		return fmt.Errorf("error marshalling notebook: %w", err)
+	}
+	indented.WriteByte('\n')
+
+	if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing notebook: %w", err)
+	}
+
+	return nil
+}
+
+func (nb *Notebook) nextID() string {
+	return fmt.Sprintf("cell-%d", len(nb.Cells)+1)
+}
+
+func (c Cell) MarshalJSON() ([]byte, error) {
+	metadata := c.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	cell := map[string]any{
+		"id":        c.ID,
+		"cell_type": c.Type,
+		"metadata":  metadata,
+		"source":    splitSource(c.Source),
+	}
+
+	// Code cells always have an execution count, null when not executed,
+	// and outputs
+	if c.Type == CellTypeCode {
+		cell["execution_count"] = nil
+		if c.ExecutionCount > 0 {
+			cell["execution_count"] = c.ExecutionCount
+		}
+
+		outputs := c.Outputs
+		if outputs == nil {
+			outputs = []Output{}
+		}
+		cell["outputs"] = outputs
+	}
+
+	return marshal(cell)
+}
+
+// marshal encodes v as JSON without escaping the HTML characters, which
+// are common in Markdown.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// splitSource splits the text in lines keeping their newline, as nbformat
+// stores multiline strings.
+func splitSource(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
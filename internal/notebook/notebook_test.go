@@ -0,0 +1,71 @@
+package notebook
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	nb := New()
+	nb.AddMarkdown("> List the files")
+	python := nb.AddCode("python", "import os\nprint(os.listdir())")
+	bash := nb.AddCode("bash", "ls")
+	nb.AddCode("go", "fmt.Println(1)")
+	nb.SetOutputs(python, StreamOutput("stdout", "a\nb\n"))
+	nb.SetOutputs(bash, StreamOutput("stderr", "oops"), ErrorOutput("ExitCode", "1"))
+
+	path := filepath.Join(t.TempDir(), "session.ipynb")
+	if err := nb.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written struct {
+		NBFormat int `json:"nbformat"`
+		Cells    []map[string]any
+	}
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if written.NBFormat != 4 || len(written.Cells) != 4 {
+		t.Fatalf("unexpected notebook: %s", data)
+	}
+
+	tests := []struct {
+		cell     int
+		source   []any
+		count    any
+		outputs  int
+		hasCount bool
+	}{
+		{cell: 0, source: []any{"> List the files"}},
+		{cell: 1, source: []any{"import os\n", "print(os.listdir())"}, count: 1.0, hasCount: true, outputs: 1},
+		{cell: 2, source: []any{"%%bash\n", "ls"}, count: 2.0, hasCount: true, outputs: 2},
+		{cell: 3, source: []any{"fmt.Println(1)"}, count: nil, hasCount: true},
+	}
+	for _, tt := range tests {
+		cell := written.Cells[tt.cell]
+		if !reflect.DeepEqual(cell["source"], tt.source) {
+			t.Errorf("cell %d source = %v, want %v", tt.cell, cell["source"], tt.source)
+		}
+
+		count, hasCount := cell["execution_count"]
+		if hasCount != tt.hasCount || count != tt.count {
+			t.Errorf("cell %d execution_count = %v, want %v", tt.cell, count, tt.count)
+		}
+
+		outputs, _ := cell["outputs"].([]any)
+		if len(outputs) != tt.outputs {
+			t.Errorf("cell %d outputs = %v, want %d", tt.cell, outputs, tt.outputs)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/diff"
+)
+
+// ErrOutsideRoot is returned for the edits of files outside of the root.
+var ErrOutsideRoot = errors.New("path is outside of the working directory")
+
+// Change is the planned update of a file.
+type Change struct {
+	// Path as named by the edit
+	Path   string
+	Old    string
+	New    string
+	Exists bool
+	Delete bool
+
+	abs  string
+	mode fs.FileMode
+}
+
+// Diff between the current and the new content of the file.
+func (c Change) Diff() []diff.Line {
+	return diff.Lines(c.Old, c.New)
+}
+
+// Plan resolves the edits against the files under root, the edits of the
+// same file are applied in order. Changes leaving a file as is are dropped.
+func Plan(root string, edits []Edit) ([]Change, error) {
+	var changes []*Change
+	byPath := make(map[string]*Change)
+
+	for _, e := range edits {
+		abs, err := resolve(root, e.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Path, err)
+		}
+
+		c, ok := byPath[abs]
+		if !ok {
+			c = &Change{Path: e.Path, abs: abs, mode: 0o644}
+
+			content, err := os.ReadFile(abs)
+			switch {
+			case err == nil:
+				info, err := os.Stat(abs)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", e.Path, err)
+				}
+				c.Old, c.Exists, c.mode = string(content), true, info.Mode().Perm()
+			case !errors.Is(err, fs.ErrNotExist):
+				return nil, fmt.Errorf("error reading %s: %w", e.Path, err)
+			}
+			c.New = c.Old
+
+			byPath[abs] = c
+			changes = append(changes, c)
+		}
+
+		switch {
+		case e.Delete:
+			if !c.Exists {
+				return nil, fmt.Errorf("%s: %w", e.Path, fs.ErrNotExist)
+			}
+			c.New, c.Delete = "", true
+		case len(e.Hunks) > 0:
+			c.New, err = applyHunks(c.New, e.Hunks)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Path, err)
+			}
+			c.Delete = false
+		default:
+			c.New, c.Delete = e.Content, false
+		}
+	}
+
+	planned := make([]Change, 0, len(changes))
+	for _, c := range changes {
+		if c.Delete || !c.Exists || c.New != c.Old {
+			planned = append(planned, *c)
+		}
+	}
+
+	return planned, nil
+}
+
+func resolve(root, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("empty path")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || !isLocal(rel) {
+		return "", ErrOutsideRoot
+	}
+
+	// Symbolic links under root must not lead outside of it
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving %s: %w", root, err)
+	}
+	existing := path
+	for {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			rel, err := filepath.Rel(realRoot, real)
+			if err != nil || (existing == path && rel == ".") || !isLocal(rel) {
+				return "", ErrOutsideRoot
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("error resolving %s: %w", existing, err)
+		}
+		// A dangling link could be created outside of root
+		if _, err := os.Lstat(existing); err == nil {
+			return "", ErrOutsideRoot
+		}
+		existing = filepath.Dir(existing)
+	}
+
+	return path, nil
+}
+
+// isLocal reports whether the relative path stays under its base.
+func isLocal(rel string) bool {
+	return rel != ".." &&
+		!strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Backup of the files changed by an apply, used to revert it.
+type Backup struct {
+	Dir     string
+	entries []backupEntry
+}
+
+type backupEntry struct {
+	path string
+	// copy of the previous content, empty for new files
+	copy    string
+	existed bool
+	mode    fs.FileMode
+	// applied content, nil for deleted files
+	applied []byte
+}
+
+// Apply backs up the files to dir and writes the changes. The new contents
+// are written next to the files before replacing them, and the files are
+// restored if any of them cannot be replaced.
+func Apply(changes []Change, dir string) (*Backup, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	backup := &Backup{Dir: dir}
+	for i, c := range changes {
+		entry := backupEntry{path: c.abs, existed: c.Exists, mode: c.mode}
+		if c.Exists {
+			entry.copy = filepath.Join(dir, strconv.Itoa(i)+"-"+filepath.Base(c.abs))
+			if err := os.WriteFile(entry.copy, []byte(c.Old), 0o600); err != nil {
+				return nil, fmt.Errorf("error backing up %s: %w", c.Path, err)
+			}
+		}
+		if !c.Delete {
+			entry.applied = []byte(c.New)
+		}
+		backup.entries = append(backup.entries, entry)
+	}
+
+	temps := make([]string, len(changes))
+	removeTemps := func() {
+		for _, t := range temps {
+			if t != "" {
+				os.Remove(t)
+			}
+		}
+	}
+	for i, c := range changes {
+		if c.Delete {
+			continue
+		}
+		tmp, err := writeTemp(c.abs, []byte(c.New), c.mode)
+		if err != nil {
+			removeTemps()
+			return nil, fmt.Errorf("error writing %s: %w", c.Path, err)
+		}
+		temps[i] = tmp
+	}
+
+	for i, c := range changes {
+		var err error
+		if c.Delete {
+			err = os.Remove(c.abs)
+		} else {
+			err = os.Rename(temps[i], c.abs)
+			temps[i] = ""
+		}
+		if err != nil {
+			removeTemps()
+			if rerr := backup.restore(i); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+			return nil, fmt.Errorf("error applying %s: %w", c.Path, err)
+		}
+	}
+
+	return backup, nil
+}
+
+// Files changed by the apply.
+func (b *Backup) Files() []string {
+	files := make([]string, len(b.entries))
+	for i, e := range b.entries {
+		files[i] = e.path
+	}
+	return files
+}
+
+// Revert restores the files as they were before the apply. Nothing is
+// restored when a file was modified since.
+func (b *Backup) Revert() error {
+	for _, e := range b.entries {
+		content, err := os.ReadFile(e.path)
+		switch {
+		case e.applied == nil && err == nil,
+			e.applied != nil && err != nil,
+			e.applied != nil && !bytes.Equal(content, e.applied):
+			return fmt.Errorf("%s was modified since the apply", e.path)
+		case err != nil && !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("error reading %s: %w", e.path, err)
+		}
+	}
+
+	return b.restore(len(b.entries))
+}
+
+// restore restores the first n files.
+func (b *Backup) restore(n int) error {
+	var errs []error
+	for _, e := range b.entries[:n] {
+		if !e.existed {
+			if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		content, err := os.ReadFile(e.copy)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tmp, err := writeTemp(e.path, content, e.mode)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Rename(tmp, e.path); err != nil {
+			os.Remove(tmp)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeTemp writes the content to a temporary file in the directory of
+// path, creating it if needed, so it can be renamed over path.
+func writeTemp(path string, content []byte, mode fs.FileMode) (string, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, ".nomi-apply-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = f.Write(content)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,100 @@
+package patch
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/diff"
+)
+
+// Edit targets a file, either with its whole new content or with the hunks
+// of a unified diff.
+type Edit struct {
+	Path string
+	// Content replaces the content of the file when there are no hunks
+	Content string
+	Hunks   []Hunk
+	Delete  bool
+}
+
+// Hunk of a unified diff. OldStart is the 1-based line the hunk is expected
+// at, 0 when unknown.
+type Hunk struct {
+	OldStart int
+	Lines    []diff.Line
+}
+
+// pathAttributes hold the target of a fenced block, as in ```go path=main.go
+var pathAttributes = []string{"path", "file", "filename", "title"}
+
+// pathComment matches a first line naming the file, as in "// file: main.go"
+var pathComment = regexp.MustCompile(
+	`(?i)^\s*(?://|#|--|;|/\*|<!--)\s*(?:file|filename|path)\s*:\s*(\S+?)\s*(?:\*/|-->)?\s*$`,
+)
+
+// Parse returns the file edits of a message: the unified diffs and the
+// fenced blocks naming their target file.
+func Parse(message string) []Edit {
+	var edits []Edit
+	for _, block := range code.ParseCodeBlocks(message) {
+		if isUnifiedDiff(block) {
+			edits = append(edits, parseUnified(block.Code)...)
+			continue
+		}
+
+		path, content := blockTarget(block)
+		if path == "" {
+			continue
+		}
+		edits = append(edits, Edit{Path: path, Content: content + "\n"})
+	}
+	return edits
+}
+
+func isUnifiedDiff(block code.CodeBlock) bool {
+	lines := strings.Split(block.Code, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "--- ") &&
+			strings.HasPrefix(lines[i+1], "+++ ") {
+			return true
+		}
+	}
+	return false
+}
+
+// blockTarget returns the path a fenced block is written to, and its
+// content without the line naming the path.
+func blockTarget(block code.CodeBlock) (string, string) {
+	for _, key := range pathAttributes {
+		if path := block.Attributes[key]; path != "" && path != "true" {
+			return path, block.Code
+		}
+	}
+
+	// ```go:main.go or ```main.go
+	if _, path, found := strings.Cut(block.Language, ":"); found && path != "" {
+		return path, block.Code
+	}
+	if looksLikePath(block.Language) {
+		return block.Language, block.Code
+	}
+
+	// ```go main.go
+	for key, value := range block.Attributes {
+		if value == "true" && looksLikePath(key) {
+			return key, block.Code
+		}
+	}
+
+	first, rest, _ := strings.Cut(block.Code, "\n")
+	if m := pathComment.FindStringSubmatch(first); m != nil {
+		return m[1], rest
+	}
+
+	return "", block.Code
+}
+
+func looksLikePath(s string) bool {
+	return strings.ContainsAny(s, "./") && !strings.ContainsAny(s, "=\"'")
+}
@@ -0,0 +1,280 @@
+package patch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		paths   []string
+	}{
+		{
+			name:    "Attribute",
+			message: "```go path=main.go\npackage main\n```",
+			paths:   []string{"main.go"},
+		},
+		{
+			name:    "Language and path",
+			message: "```go:cmd/main.go\npackage main\n```\n```python\nprint(1)\n```",
+			paths:   []string{"cmd/main.go"},
+		},
+		{
+			name:    "Bare path",
+			message: "```python app.py\nprint(1)\n```",
+			paths:   []string{"app.py"},
+		},
+		{
+			name:    "Path comment",
+			message: "```js\n// File: src/index.js\nconsole.log(1)\n```",
+			paths:   []string{"src/index.js"},
+		},
+		{
+			name: "Unified diff",
+			message: "```diff\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n" +
+				"--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n```",
+			paths: []string{"main.go", "old.txt"},
+		},
+		{
+			name:    "No target",
+			message: "```bash\nls\n```",
+			paths:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			edits := Parse(tt.message)
+			if len(edits) != len(tt.paths) {
+				t.Fatalf("Parse() = %+v, want paths %v", edits, tt.paths)
+			}
+			for i, e := range edits {
+				if e.Path != tt.paths[i] {
+					t.Errorf("Parse()[%d].Path = %q, want %q", i, e.Path, tt.paths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyHunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		diff     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Exact",
+			content:  "a\nb\nc\n",
+			diff:     "@@ -2,1 +2,1 @@\n-b\n+B",
+			expected: "a\nB\nc\n",
+		},
+		{
+			name:     "Wrong line numbers",
+			content:  "a\nb\nc\nd\n",
+			diff:     "@@ -1,2 +1,3 @@\n c\n+x\n d",
+			expected: "a\nb\nc\nx\nd\n",
+		},
+		{
+			name:     "Indentation kept",
+			content:  "func f() {\n\treturn 1\n}\n",
+			diff:     "@@ @@\n func f() {\n-    return 1\n+    return 2\n }",
+			expected: "func f() {\n    return 2\n}\n",
+		},
+		{
+			name:     "Closest match",
+			content:  "x\ny\nx\ny\n",
+			diff:     "@@ -3,2 +3,2 @@\n x\n-y\n+z",
+			expected: "x\ny\nx\nz\n",
+		},
+		{
+			name:     "New file",
+			content:  "",
+			diff:     "@@ -0,0 +1,2 @@\n+a\n+b",
+			expected: "a\nb\n",
+		},
+		{
+			name:    "Mismatch",
+			content: "a\nb\n",
+			diff:    "@@ -1 +1 @@\n-c\n+d",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			edits := parseUnified("--- a/f\n+++ b/f\n" + tt.diff)
+			if len(edits) != 1 {
+				t.Fatalf("parseUnified() = %+v", edits)
+			}
+
+			result, err := applyHunks(tt.content, edits[0].Hunks)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyHunks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("applyHunks() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyRevert(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	write := func(name, content string) {
+		err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return "<missing>"
+		}
+		return string(content)
+	}
+
+	write("edited.txt", "a\nb\n")
+	write("deleted.txt", "x\n")
+
+	edits := Parse("```diff\n--- a/edited.txt\n+++ b/edited.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+B\n" +
+		"--- a/deleted.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n```\n" +
+		"```text path=dir/new.txt\nnew\n```")
+
+	if _, err := Plan(root, []Edit{{Path: "../escape.txt"}}); err == nil {
+		t.Error("Plan() outside of the root succeeded")
+	}
+
+	changes, err := Plan(root, edits)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("Plan() = %+v", changes)
+	}
+
+	backup, err := Apply(changes, filepath.Join(t.TempDir(), "backup"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	applied := map[string]string{
+		"edited.txt":  "a\nB\n",
+		"deleted.txt": "<missing>",
+		"dir/new.txt": "new\n",
+	}
+	for name, want := range applied {
+		if got := read(name); got != want {
+			t.Errorf("%s after Apply() = %q, want %q", name, got, want)
+		}
+	}
+
+	if err := backup.Revert(); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	reverted := map[string]string{
+		"edited.txt":  "a\nb\n",
+		"deleted.txt": "x\n",
+		"dir/new.txt": "<missing>",
+	}
+	for name, want := range reverted {
+		if got := read(name); got != want {
+			t.Errorf("%s after Revert() = %q, want %q", name, got, want)
+		}
+	}
+
+	// A file modified since the apply is not overwritten
+	backup, err = Apply(changes, filepath.Join(t.TempDir(), "backup"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	write("edited.txt", "changed\n")
+	if err := backup.Revert(); err == nil {
+		t.Error("Revert() of a modified file succeeded")
+	}
+	if got := read("edited.txt"); got != "changed\n" {
+		t.Errorf("edited.txt after Revert() = %q", got)
+	}
+}
+
+func TestPlanSymlinks(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links need privileges on windows")
+	}
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"link":        outside,
+		"file.txt":    filepath.Join(outside, "secret.txt"),
+		"inside":      filepath.Join(root, "dir"),
+		"dir/up":      "..",
+		"dir/parent":  "../..",
+		"self":        ".",
+		"dir/broken":  filepath.Join(outside, "missing", "dir"),
+		"dir/escaped": filepath.Join("..", "link"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		outside bool
+	}{
+		{path: "link/file.txt", outside: true},
+		{path: "link/new/file.txt", outside: true},
+		{path: "file.txt", outside: true},
+		{path: "dir/parent/file.txt", outside: true},
+		{path: "dir/escaped/file.txt", outside: true},
+		{path: "dir/broken/file.txt", outside: true},
+		{path: "self", outside: true},
+		{path: "inside/file.txt"},
+		{path: "dir/up/new/file.txt"},
+		{path: "self/new.txt"},
+		{path: "self/file.txt", outside: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Plan(root, []Edit{{Path: tt.path, Content: "x\n"}})
+			if got := errors.Is(err, ErrOutsideRoot); got != tt.outside {
+				t.Errorf("Plan(%q) error = %v, want outside %v", tt.path, err, tt.outside)
+			}
+		})
+	}
+
+	content, err := os.ReadFile(filepath.Join(outside, "secret.txt"))
+	if err != nil || string(content) != "secret\n" {
+		t.Errorf("secret.txt = %q, %v", content, err)
+	}
+}
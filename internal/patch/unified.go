@@ -0,0 +1,204 @@
+package patch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/diff"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// parseUnified returns an edit per file of the unified diff. The line
+// counts of the hunk headers are ignored, models often get them wrong.
+func parseUnified(text string) []Edit {
+	var (
+		edits   []Edit
+		current *Edit
+	)
+
+	flush := func() {
+		if current != nil && (len(current.Hunks) > 0 || current.Delete) {
+			for i := range current.Hunks {
+				current.Hunks[i].trim()
+			}
+			edits = append(edits, *current)
+		}
+		current = nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) &&
+			strings.HasPrefix(lines[i+1], "+++ "):
+			flush()
+
+			oldPath := diffPath(line[4:])
+			newPath := diffPath(lines[i+1][4:])
+			current = &Edit{Path: newPath}
+			if newPath == "" {
+				current = &Edit{Path: oldPath, Delete: true}
+			}
+			i++
+		case current == nil:
+		case strings.HasPrefix(line, "@@"):
+			h := Hunk{}
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				h.OldStart, _ = strconv.Atoi(m[1])
+			}
+			current.Hunks = append(current.Hunks, h)
+		case len(current.Hunks) == 0:
+		default:
+			h := &current.Hunks[len(current.Hunks)-1]
+			switch {
+			case line == "":
+				// Blank context lines often lose their leading space
+				h.Lines = append(h.Lines, diff.Line{Op: diff.OpEqual})
+			case line[0] == ' ':
+				h.Lines = append(h.Lines, diff.Line{Op: diff.OpEqual, Text: line[1:]})
+			case line[0] == '+':
+				h.Lines = append(h.Lines, diff.Line{Op: diff.OpInsert, Text: line[1:]})
+			case line[0] == '-':
+				h.Lines = append(h.Lines, diff.Line{Op: diff.OpDelete, Text: line[1:]})
+			}
+		}
+	}
+	flush()
+
+	return edits
+}
+
+// diffPath returns the path of a ---/+++ line, without the a/ and b/
+// prefixes, empty for /dev/null.
+func diffPath(s string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// trim removes the trailing blank context lines, which are usually the end
+// of the message rather than part of the hunk.
+func (h *Hunk) trim() {
+	for n := len(h.Lines); n > 0; n-- {
+		l := h.Lines[n-1]
+		if l.Op != diff.OpEqual || l.Text != "" {
+			break
+		}
+		h.Lines = h.Lines[:n-1]
+	}
+}
+
+// applyHunks applies the hunks in order to the content. Each hunk is
+// searched from the end of the previous one, preferring the closest match
+// to its line, and with the surrounding whitespace ignored if needed.
+func applyHunks(content string, hunks []Hunk) (string, error) {
+	lines := diff.SplitLines(content)
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+
+	from, offset := 0, 0
+	for n, h := range hunks {
+		var old []string
+		for _, l := range h.Lines {
+			if l.Op != diff.OpInsert {
+				old = append(old, l.Text)
+			}
+		}
+
+		hint := max(h.OldStart-1+offset, from)
+		at := findLines(lines, old, from, hint, exactMatch)
+		if at < 0 {
+			at = findLines(lines, old, from, hint, trimmedMatch)
+		}
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d does not apply", n+1)
+		}
+
+		// Keep the lines of the file for the context, in case they only
+		// matched when trimmed
+		var replacement []string
+		i := at
+		for _, l := range h.Lines {
+			switch l.Op {
+			case diff.OpEqual:
+				replacement = append(replacement, lines[i])
+				i++
+			case diff.OpDelete:
+				i++
+			case diff.OpInsert:
+				replacement = append(replacement, l.Text)
+			}
+		}
+
+		tail := append(replacement, lines[at+len(old):]...)
+		lines = append(lines[:at:at], tail...)
+
+		from = at + len(replacement)
+		offset += len(replacement) - len(old)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	result := strings.Join(lines, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result, nil
+}
+
+func exactMatch(a, b string) bool {
+	return a == b
+}
+
+func trimmedMatch(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+// findLines returns the position of needle in lines at or after from, the
+// closest to hint, or -1.
+func findLines(
+	lines, needle []string,
+	from, hint int,
+	match func(a, b string) bool,
+) int {
+	if len(needle) == 0 {
+		return min(hint, len(lines))
+	}
+
+	best := -1
+	for at := from; at+len(needle) <= len(lines); at++ {
+		if best >= 0 && at-hint > hint-best {
+			break
+		}
+
+		matched := true
+		for i, text := range needle {
+			if !match(lines[at+i], text) {
+				matched = false
+				break
+			}
+		}
+		if matched && (best < 0 || abs(at-hint) < abs(best-hint)) {
+			best = at
+		}
+	}
+
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,254 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/jsonrpc"
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+// Host exposes the nomi tools to an external usecase process.
+// Backends that are not required by the usecase capabilities can be nil.
+type Host struct {
+	TextToJSON   *tools.TextToJSONBackend
+	TextToText   *tools.TextToTextBackend
+	InputHandler tools.InputHandler
+	Selector     tools.Selector
+	Console      tools.Console
+	Logger       tools.Logger
+}
+
+// Run starts the usecase process and serves its requests until it exits.
+func (h *Host) Run(
+	ctx context.Context,
+	manifest *Manifest,
+	args []string,
+) error {
+	if manifest.HasCapability(CapabilityJSON) && h.TextToJSON == nil {
+		return errors.New("host has no text-to-json backend")
+	}
+	if manifest.HasCapability(CapabilityText) && h.TextToText == nil {
+		return errors.New("host has no text-to-text backend")
+	}
+
+	cmdArgs := append(append([]string{}, manifest.Args...), args...)
+	cmd := exec.CommandContext(ctx, manifest.EntrypointPath(), cmdArgs...)
+	cmd.Dir = manifest.Dir()
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(
+		os.Environ(),
+		"NOMI_USECASE_ID="+manifest.ID,
+		"NOMI_PROTOCOL_VERSION="+ProtocolVersion,
+	)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("error creating stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error creating stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting usecase: %w", err)
+	}
+
+	conn := jsonrpc.NewConn(stdout, stdin)
+	h.register(conn, manifest)
+
+	serveErr := conn.Serve(ctx)
+	stdin.Close()
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("usecase %s failed: %w", manifest.ID, err)
+	}
+
+	if serveErr != nil {
+		return fmt.Errorf("error serving usecase: %w", serveErr)
+	}
+
+	return nil
+}
+
+func (h *Host) register(conn *jsonrpc.Conn, manifest *Manifest) {
+	conn.Handle(MethodHostInfo, func(
+		_ context.Context,
+		_ json.RawMessage,
+	) (interface{}, error) {
+		return HostInfoResult{
+			UsecaseID:       manifest.ID,
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    manifest.Capabilities,
+		}, nil
+	})
+
+	if manifest.HasCapability(CapabilityJSON) {
+		conn.Handle(MethodLLMJSON, h.handleLLM(h.TextToJSON.DoMessages))
+	}
+
+	if manifest.HasCapability(CapabilityText) {
+		conn.Handle(MethodLLMText, h.handleLLM(h.TextToText.DoMessages))
+	}
+
+	if manifest.HasCapability(CapabilityInput) {
+		conn.Handle(MethodInputRead, h.handleInputRead)
+	}
+
+	if manifest.HasCapability(CapabilitySelector) {
+		conn.Handle(MethodSelectorBool, h.handleSelectorBool)
+		conn.Handle(MethodSelectorString, h.handleSelectorString)
+	}
+
+	if manifest.HasCapability(CapabilityConsole) {
+		conn.Handle(MethodConsoleExec, h.handleConsoleExec)
+	}
+
+	if manifest.HasCapability(CapabilityLogger) {
+		conn.Handle(MethodLoggerLog, h.handleLoggerLog)
+	}
+}
+
+func (h *Host) handleLLM(
+	do func(context.Context, []chat.Message) (string, error),
+) jsonrpc.Handler {
+	return func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		var params LLMParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, jsonrpc.ErrInvalidParams(err)
+		}
+
+		messages := make([]chat.Message, 0, len(params.Messages))
+		for _, m := range params.Messages {
+			role := chat.Role(m.Role)
+			switch role {
+			case chat.RoleSystem, chat.RoleUser, chat.RoleAssistant:
+			default:
+				return nil, jsonrpc.NewError(
+					jsonrpc.CodeInvalidParams,
+					"invalid message role: "+m.Role,
+				)
+			}
+
+			messages = append(messages, chat.NewMessage(role, m.Content))
+		}
+
+		content, err := do(ctx, messages)
+		if err != nil {
+			return nil, fmt.Errorf("error generating completion: %w", err)
+		}
+
+		return LLMResult{Content: content}, nil
+	}
+}
+
+func (h *Host) handleInputRead(
+	ctx context.Context,
+	raw json.RawMessage,
+) (interface{}, error) {
+	var params InputReadParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	if params.Prompt == "" {
+		params.Prompt = ">>> "
+	}
+
+	text, err := h.InputHandler.Read(ctx, params.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("error reading input: %w", err)
+	}
+
+	return InputReadResult{Text: text}, nil
+}
+
+func (h *Host) handleSelectorBool(
+	_ context.Context,
+	raw json.RawMessage,
+) (interface{}, error) {
+	var params SelectorBoolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	return SelectorBoolResult{
+		Value: h.Selector.SelectBool(params.Title, params.Default),
+	}, nil
+}
+
+func (h *Host) handleSelectorString(
+	_ context.Context,
+	raw json.RawMessage,
+) (interface{}, error) {
+	var params SelectorStringParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	if len(params.Items) == 0 {
+		return nil, jsonrpc.NewError(
+			jsonrpc.CodeInvalidParams,
+			"items must not be empty",
+		)
+	}
+
+	return SelectorStringResult{
+		Value: h.Selector.SelectString(params.Title, params.Items),
+	}, nil
+}
+
+func (h *Host) handleConsoleExec(
+	ctx context.Context,
+	raw json.RawMessage,
+) (interface{}, error) {
+	var params ConsoleExecParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	cmd := tools.NewCommand(params.Command, params.Args...).
+		WithInput(params.Input)
+
+	result, err := h.Console.Exec(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error executing command: %w", err)
+	}
+
+	return ConsoleExecResult{
+		ExitCode: result.ExitCode,
+		Output:   result.Output,
+		Error:    result.Error,
+	}, nil
+}
+
+func (h *Host) handleLoggerLog(
+	_ context.Context,
+	raw json.RawMessage,
+) (interface{}, error) {
+	var params LoggerLogParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, jsonrpc.ErrInvalidParams(err)
+	}
+
+	switch params.Level {
+	case LogLevelDebug:
+		h.Logger.Debug(params.Message)
+	case LogLevelError:
+		h.Logger.Error(params.Message)
+	case LogLevelPrint:
+		h.Logger.Println(params.Message)
+	case LogLevelInfo:
+		h.Logger.Info(params.Message)
+	default:
+		h.Logger.Info(params.Message)
+	}
+
+	return struct{}{}, nil
+}
@@ -0,0 +1,142 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ManifestFileName is the name of the manifest file of an external usecase.
+const ManifestFileName = "manifest.yml"
+
+type Capability string
+
+const (
+	// CapabilityJSON grants access to the host text-to-json backend.
+	CapabilityJSON Capability = "json"
+	// CapabilityText grants access to the host text-to-text backend.
+	CapabilityText Capability = "text"
+	// CapabilityInput grants access to the user input handler.
+	CapabilityInput Capability = "input"
+	// CapabilitySelector grants access to the interactive selector.
+	CapabilitySelector Capability = "selector"
+	// CapabilityConsole grants access to command execution on the host.
+	CapabilityConsole Capability = "console"
+	// CapabilityLogger grants access to the host logger.
+	CapabilityLogger Capability = "logger"
+)
+
+var knownCapabilities = map[Capability]struct{}{
+	CapabilityJSON:     {},
+	CapabilityText:     {},
+	CapabilityInput:    {},
+	CapabilitySelector: {},
+	CapabilityConsole:  {},
+	CapabilityLogger:   {},
+}
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Manifest describes an external usecase installed under the usecase
+// directory.
+type Manifest struct {
+	ID           string       `yaml:"id"`
+	Description  string       `yaml:"description"`
+	Entrypoint   string       `yaml:"entrypoint"`
+	Args         []string     `yaml:"args,omitempty"`
+	Capabilities []Capability `yaml:"capabilities"`
+	Version      string       `yaml:"version,omitempty"`
+	Author       string       `yaml:"author,omitempty"`
+
+	// dir is the directory the manifest was loaded from.
+	dir string
+}
+
+func (m *Manifest) Validate() error {
+	if m.ID == "" {
+		return errors.New("Usecase ID is required")
+	}
+
+	if !validID.MatchString(m.ID) {
+		return fmt.Errorf(
+			"Usecase ID %q must only contain lowercase letters, digits, '-' and '_'",
+			m.ID,
+		)
+	}
+
+	if m.Entrypoint == "" {
+		return errors.New("Usecase Entrypoint is required")
+	}
+
+	for _, c := range m.Capabilities {
+		if _, ok := knownCapabilities[c]; !ok {
+			return fmt.Errorf("Usecase capability %q is unknown", c)
+		}
+	}
+
+	return nil
+}
+
+// HasCapability reports whether the manifest requires the capability.
+func (m *Manifest) HasCapability(capability Capability) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir returns the directory the manifest was loaded from.
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// EntrypointPath resolves the entrypoint, relative entrypoints are
+// relative to the manifest directory, bare names are looked up in PATH.
+func (m *Manifest) EntrypointPath() string {
+	if filepath.IsAbs(m.Entrypoint) {
+		return m.Entrypoint
+	}
+
+	local := filepath.Join(m.dir, m.Entrypoint)
+	if _, err := os.Stat(local); err == nil {
+		return local
+	}
+
+	return m.Entrypoint
+}
+
+// LoadManifest loads the manifest from a usecase directory or from the
+// manifest file itself.
+func LoadManifest(path string) (*Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+
+	if info.IsDir() {
+		path = filepath.Join(path, ManifestFileName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error unmarshalling manifest: %w", err)
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("error validating manifest: %w", err)
+	}
+
+	manifest.dir = filepath.Dir(path)
+	return &manifest, nil
+}
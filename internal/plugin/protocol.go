@@ -0,0 +1,96 @@
+package plugin
+
+// The protocol between nomi and an external usecase is JSON-RPC 2.0 over
+// the stdio of the usecase process, one message per line. The usecase
+// drives the interaction and calls the host methods below, each method
+// being only available when the matching capability is declared in the
+// manifest. The usecase terminates by exiting, a non-zero exit code is
+// reported as a failure. Stderr is forwarded to the user terminal.
+//
+// The environment of the process contains NOMI_USECASE_ID and
+// NOMI_PROTOCOL_VERSION.
+
+// ProtocolVersion is bumped on breaking changes of the host methods.
+const ProtocolVersion = "1"
+
+const (
+	MethodHostInfo       = "host.info"
+	MethodLLMJSON        = "llm.json"
+	MethodLLMText        = "llm.text"
+	MethodInputRead      = "input.read"
+	MethodSelectorBool   = "selector.bool"
+	MethodSelectorString = "selector.string"
+	MethodConsoleExec    = "console.exec"
+	MethodLoggerLog      = "logger.log"
+)
+
+type HostInfoResult struct {
+	UsecaseID       string       `json:"usecaseId"`
+	ProtocolVersion string       `json:"protocolVersion"`
+	Capabilities    []Capability `json:"capabilities"`
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type LLMParams struct {
+	Messages []Message `json:"messages"`
+}
+
+type LLMResult struct {
+	Content string `json:"content"`
+}
+
+type InputReadParams struct {
+	Prompt string `json:"prompt"`
+}
+
+type InputReadResult struct {
+	Text string `json:"text"`
+}
+
+type SelectorBoolParams struct {
+	Title   string `json:"title"`
+	Default bool   `json:"default"`
+}
+
+type SelectorBoolResult struct {
+	Value bool `json:"value"`
+}
+
+type SelectorStringParams struct {
+	Title string   `json:"title"`
+	Items []string `json:"items"`
+}
+
+type SelectorStringResult struct {
+	Value string `json:"value"`
+}
+
+type ConsoleExecParams struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Input   string   `json:"input"`
+}
+
+type ConsoleExecResult struct {
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output"`
+	Error    string `json:"error"`
+}
+
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelError LogLevel = "error"
+	LogLevelPrint LogLevel = "print"
+)
+
+type LoggerLogParams struct {
+	Level   LogLevel `json:"level"`
+	Message string   `json:"message"`
+}
@@ -0,0 +1,237 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/config"
+)
+
+var (
+	ErrUsecaseNotFound = errors.New("usecase not found")
+	ErrUsecaseExists   = errors.New("usecase already installed")
+)
+
+// Install copies the usecase located at path (a directory containing a
+// manifest, or a manifest file) into the usecase directory. The copy is
+// moved into place once complete, so a failed install keeps the previous
+// version.
+func Install(path string, overwrite bool) (*Manifest, error) {
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := config.GetUsecaseDirectory()
+	target := filepath.Join(dir, manifest.ID)
+	_, err = os.Stat(target)
+	exists := err == nil
+	if exists && !overwrite {
+		return nil, fmt.Errorf("%w: %s", ErrUsecaseExists, manifest.ID)
+	}
+
+	// Installing the usecase from its installed directory, or the other
+	// way around, would copy the usecase into itself
+	if overlaps(manifest.Dir(), target) {
+		return nil, fmt.Errorf(
+			"cannot install %s from %s, the directories overlap",
+			manifest.ID,
+			manifest.Dir(),
+		)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating usecase directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(dir, "."+manifest.ID+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := copyDir(manifest.Dir(), tmp); err != nil {
+		return nil, fmt.Errorf("error copying usecase: %w", err)
+	}
+
+	if exists {
+		previous := tmp + ".previous"
+		if err := os.Rename(target, previous); err != nil {
+			return nil, fmt.Errorf("error moving previous usecase: %w", err)
+		}
+		defer os.RemoveAll(previous)
+
+		if err := os.Rename(tmp, target); err != nil {
+			// Restore the previous version
+			_ = os.Rename(previous, target)
+			return nil, fmt.Errorf("error installing usecase: %w", err)
+		}
+	} else if err := os.Rename(tmp, target); err != nil {
+		return nil, fmt.Errorf("error installing usecase: %w", err)
+	}
+
+	return Load(manifest.ID)
+}
+
+// overlaps reports whether one of the directories is inside the other.
+func overlaps(a, b string) bool {
+	a, b = resolvePath(a), resolvePath(b)
+	return isWithin(a, b) || isWithin(b, a)
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." &&
+		!strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolvePath returns the absolute path with its symlinks resolved, as far
+// as it exists.
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	if parent := filepath.Dir(path); parent != path {
+		return filepath.Join(resolvePath(parent), filepath.Base(path))
+	}
+	return path
+}
+
+// Remove deletes an installed usecase, even when its manifest is invalid.
+func Remove(id string) error {
+	dir, err := installDir(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("error removing usecase: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the manifest of an installed usecase.
+func Load(id string) (*Manifest, error) {
+	dir, err := installDir(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(
+		filepath.Join(dir, ManifestFileName),
+	); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrUsecaseNotFound, id)
+	}
+
+	return LoadManifest(dir)
+}
+
+// installDir returns the directory of an installed usecase, without
+// reading its manifest.
+func installDir(id string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("%w: %s", ErrUsecaseNotFound, id)
+	}
+
+	dir := filepath.Join(config.GetUsecaseDirectory(), id)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: %s", ErrUsecaseNotFound, id)
+	}
+
+	return dir, nil
+}
+
+// List returns the manifests of all installed usecases.
+func List() ([]Manifest, error) {
+	entries, err := os.ReadDir(config.GetUsecaseDirectory())
+	if err != nil {
+		return nil, fmt.Errorf("error reading usecase directory: %w", err)
+	}
+
+	var manifests []Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifest, err := Load(entry.Name())
+		if err != nil {
+			// Ignore directories that are not usecases
+			if errors.Is(err, ErrUsecaseNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf(
+				"error loading usecase %s: %w",
+				entry.Name(),
+				err,
+			)
+		}
+
+		manifests = append(manifests, *manifest)
+	}
+
+	return manifests, nil
+}
+
+func copyDir(src, dst string) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("error computing relative path: %w", err)
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("error reading file info: %w", err)
+		}
+
+		return copyFile(path, target, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("error walking directory: %w", err)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("error copying file: %w", err)
+	}
+
+	return nil
+}
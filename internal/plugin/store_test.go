@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nullswan/nomi/internal/config"
+)
+
+// setupHome points the home directory, holding the installed usecases, to
+// a temporary directory.
+func setupHome(t *testing.T) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+}
+
+// writeUsecase writes a usecase directory with its manifest and its
+// entrypoint.
+func writeUsecase(t *testing.T, dir, manifest, entrypoint string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		ManifestFileName: manifest,
+		"run.sh":         entrypoint,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const testManifest = "id: test\nentrypoint: run.sh\ncapabilities: [logger]\n"
+
+func TestInstall(t *testing.T) {
+	setupHome(t)
+
+	v1 := filepath.Join(t.TempDir(), "v1")
+	writeUsecase(t, v1, testManifest, "echo v1")
+	v2 := filepath.Join(t.TempDir(), "v2")
+	writeUsecase(t, v2, testManifest, "echo v2")
+	invalid := filepath.Join(t.TempDir(), "invalid")
+	writeUsecase(t, invalid, "id: test\n", "echo invalid")
+
+	installed := filepath.Join(config.GetUsecaseDirectory(), "test")
+	// A usecase at the root of the usecase directory contains its target
+	parent := config.GetUsecaseDirectory()
+	writeUsecase(t, parent, testManifest, "echo parent")
+
+	tests := []struct {
+		name       string
+		path       string
+		overwrite  bool
+		entrypoint string
+		wantErr    bool
+		errIs      error
+	}{
+		{name: "new", path: v1, entrypoint: "echo v1"},
+		{name: "installed", path: v2, entrypoint: "echo v1", wantErr: true, errIs: ErrUsecaseExists},
+		{name: "overwrite", path: v2, overwrite: true, entrypoint: "echo v2"},
+		{name: "manifest file", path: filepath.Join(v1, ManifestFileName), overwrite: true, entrypoint: "echo v1"},
+		{name: "invalid manifest", path: invalid, overwrite: true, entrypoint: "echo v1", wantErr: true},
+		{name: "from itself", path: installed, overwrite: true, entrypoint: "echo v1", wantErr: true},
+		{name: "from a parent", path: parent, overwrite: true, entrypoint: "echo v1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := Install(tt.path, tt.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Install() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("Install() error = %v, want %v", err, tt.errIs)
+			}
+			if err == nil && manifest.Dir() != installed {
+				t.Errorf("Install() directory = %s, want %s", manifest.Dir(), installed)
+			}
+
+			// A refused install keeps the installed version
+			content, err := os.ReadFile(filepath.Join(installed, "run.sh"))
+			if err != nil || string(content) != tt.entrypoint {
+				t.Errorf("installed entrypoint = %q, %v, want %q", content, err, tt.entrypoint)
+			}
+
+			// The temporary directories are removed
+			entries, _ := os.ReadDir(config.GetUsecaseDirectory())
+			for _, entry := range entries {
+				if strings.HasPrefix(entry.Name(), ".") {
+					t.Errorf("temporary directory %s left", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	setupHome(t)
+
+	dir := config.GetUsecaseDirectory()
+	writeUsecase(t, filepath.Join(dir, "valid"), strings.Replace(testManifest, "test", "valid", 1), "echo valid")
+	// A manifest written for a capability removed since then
+	writeUsecase(t, filepath.Join(dir, "outdated"), "id: outdated\nentrypoint: run.sh\ncapabilities: [removed]\n", "echo outdated")
+	writeUsecase(t, filepath.Join(dir, "broken"), "id: [broken", "echo broken")
+
+	tests := []struct {
+		id      string
+		wantErr error
+	}{
+		{id: "valid"},
+		{id: "outdated"},
+		{id: "broken"},
+		{id: "valid", wantErr: ErrUsecaseNotFound},
+		{id: "missing", wantErr: ErrUsecaseNotFound},
+		{id: "../valid", wantErr: ErrUsecaseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if err := Remove(tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Remove() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, tt.id)); !os.IsNotExist(err) {
+				t.Errorf("usecase %s kept: %v", tt.id, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package proc
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// DefaultOutputLimit bounds the output of each stream of a command kept in
+// memory.
+const DefaultOutputLimit = 1 << 20
+
+// OutputBuffer keeps the head of the written bytes and a ring buffer of
+// their tail, so a command cannot exhaust the memory with its output.
+type OutputBuffer struct {
+	head    []byte
+	tail    []byte
+	next    int // next write position in tail once it is full
+	headMax int
+	tailMax int
+	written int
+}
+
+// NewOutputBuffer returns a buffer keeping limit bytes, DefaultOutputLimit
+// when limit is not positive.
+func NewOutputBuffer(limit int) *OutputBuffer {
+	if limit <= 0 {
+		limit = DefaultOutputLimit
+	}
+	return &OutputBuffer{headMax: limit / 2, tailMax: limit - limit/2}
+}
+
+func (b *OutputBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.written += n
+
+	if size := min(b.headMax-len(b.head), len(p)); size > 0 {
+		b.head = append(b.head, p[:size]...)
+		p = p[size:]
+	}
+
+	if len(p) >= b.tailMax {
+		b.tail = append(b.tail[:0], p[len(p)-b.tailMax:]...)
+		b.next = 0
+		return n, nil
+	}
+
+	for len(p) > 0 {
+		if len(b.tail) < b.tailMax {
+			size := min(b.tailMax-len(b.tail), len(p))
+			b.tail = append(b.tail, p[:size]...)
+			p = p[size:]
+			continue
+		}
+		size := copy(b.tail[b.next:], p)
+		b.next = (b.next + size) % b.tailMax
+		p = p[size:]
+	}
+
+	return n, nil
+}
+
+// Truncated reports whether bytes were dropped between the head and the
+// tail.
+func (b *OutputBuffer) Truncated() bool {
+	return b.written > len(b.head)+len(b.tail)
+}
+
+// String returns the output, the dropped bytes replaced by a marker.
+func (b *OutputBuffer) String() string {
+	tail := append(append([]byte{}, b.tail[b.next:]...), b.tail[:b.next]...)
+	if !b.Truncated() {
+		return string(b.head) + string(tail)
+	}
+
+	// Cut on rune boundaries, the dropped bytes may have split a rune
+	head := b.head
+	for i := len(head) - 1; i >= 0 && i >= len(head)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(head[i]) {
+			if !utf8.FullRune(head[i:]) {
+				head = head[:i]
+			}
+			break
+		}
+	}
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+
+	return string(head) +
+		TruncationMarker(b.written-len(head)-len(tail)) +
+		string(tail)
+}
+
+// TruncationMarker replaces the n bytes dropped from an output.
+func TruncationMarker(n int) string {
+	return fmt.Sprintf("\n... [%d bytes truncated] ...\n", n)
+}
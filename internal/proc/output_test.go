@@ -0,0 +1,62 @@
+package proc
+
+import "testing"
+
+func TestOutputBuffer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		writes    []string
+		limit     int
+		expected  string
+		truncated bool
+	}{
+		{
+			name:     "Short output",
+			writes:   []string{"hel", "lo"},
+			limit:    10,
+			expected: "hello",
+		},
+		{
+			name:      "Head and tail",
+			writes:    []string{"0123", "456", "789"},
+			limit:     4,
+			expected:  "01\n... [6 bytes truncated] ...\n89",
+			truncated: true,
+		},
+		{
+			name:      "Long write",
+			writes:    []string{"0123456789"},
+			limit:     4,
+			expected:  "01\n... [6 bytes truncated] ...\n89",
+			truncated: true,
+		},
+		{
+			name:      "Rune boundaries",
+			writes:    []string{"ééééé"},
+			limit:     5,
+			expected:  "é\n... [6 bytes truncated] ...\né",
+			truncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := NewOutputBuffer(tt.limit)
+			for _, w := range tt.writes {
+				if n, err := b.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write() = %d, %v, want %d", n, err, len(w))
+				}
+			}
+			if result := b.String(); result != tt.expected {
+				t.Errorf("String() = %q, want %q", result, tt.expected)
+			}
+			if b.Truncated() != tt.truncated {
+				t.Errorf("Truncated() = %v, want %v", b.Truncated(), tt.truncated)
+			}
+		})
+	}
+}
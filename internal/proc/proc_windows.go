@@ -0,0 +1,17 @@
+//go:build windows
+
+package proc
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// SetProcessGroup is a no-op on Windows, only the process itself is killed
+// when the context of the command is done.
+func SetProcessGroup(_ *exec.Cmd) {}
+
+// Interrupt is not supported on Windows.
+func Interrupt(_ *exec.Cmd) error {
+	return errors.ErrUnsupported
+}
@@ -1,47 +1,220 @@
 package prompts
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
-	"gopkg.in/yaml.v2"
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/minisign"
 )
 
-func AddPromptFromURL(url string) (*Prompt, error) {
-	resp, err := http.Get(url)
+// signatureExtension is appended to the URL of a prompt to fetch its
+// minisign signature.
+const signatureExtension = ".minisig"
+
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrUnsigned         = errors.New("prompt is not signed by a trusted key")
+)
+
+// Trust verifies the signatures of the remote prompts.
+type Trust struct {
+	// TrustedKeys verify the minisign signature served next to the prompt,
+	// at <url>.minisig
+	TrustedKeys []config.TrustedKey
+	// RequireSignature refuses the prompts without a trusted signature
+	RequireSignature bool
+}
+
+// NewTrust returns the trust of the prompts configuration.
+func NewTrust(cfg config.PromptsConfig) Trust {
+	return Trust{
+		TrustedKeys:      cfg.TrustedKeys,
+		RequireSignature: cfg.RequireSignature,
+	}
+}
+
+// enabled reports whether the signatures are checked.
+func (t Trust) enabled() bool {
+	return len(t.TrustedKeys) > 0 || t.RequireSignature
+}
+
+// verify returns the name of the trusted key which signed the data with
+// the .minisig file, nil when the data is not signed. The signatures of
+// unknown keys are ignored, the ones of a trusted key which do not match
+// are errors.
+func (t Trust) verify(data, file []byte) (string, error) {
+	signer, err := checkSignature(data, file, t.TrustedKeys)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching the URL: %v", err)
+		return "", err
 	}
-	defer resp.Body.Close()
+	if signer == "" && t.RequireSignature {
+		return "", ErrUnsigned
+	}
+	return signer, nil
+}
+
+// AddOptions guard the prompts added from a URL.
+type AddOptions struct {
+	Trust
+	// Confirm allows the prompt to replace the installed one of the same
+	// ID, such prompts are refused when nil
+	Confirm func(installed, prompt *Prompt) bool
+}
+
+// Verification describes how a prompt added from a URL was verified.
+type Verification struct {
+	// Checksum is the SHA-256 pinned in the URL, empty when not pinned
+	Checksum string
+	// Signer is the name of the trusted key of the signature, empty when
+	// the prompt is not signed
+	Signer string
+}
+
+// AddPromptFromURL downloads a prompt and saves it. The URL can pin the
+// SHA-256 of the file with a #sha256=<hex> fragment.
+func AddPromptFromURL(
+	ctx context.Context,
+	rawURL string,
+	opts AddOptions,
+) (*Prompt, Verification, error) {
+	var verification Verification
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"error fetching the URL: received status code %d",
-			resp.StatusCode,
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, verification, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, verification, fmt.Errorf(
+			"invalid URL %s: expected http or https",
+			rawURL,
 		)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	if u.Fragment != "" {
+		checksum, ok := strings.CutPrefix(u.Fragment, "sha256=")
+		if !ok {
+			return nil, verification, fmt.Errorf(
+				"invalid URL fragment %q, expected sha256=<hex>",
+				u.Fragment,
+			)
+		}
+		verification.Checksum = strings.ToLower(checksum)
+		u.Fragment = ""
+	}
+
+	client := &http.Client{Timeout: fetchTimeout}
+	data, err := fetchFile(ctx, client, u.String())
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
+		return nil, verification, err
+	}
+
+	if verification.Checksum != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); actual != verification.Checksum {
+			return nil, verification, fmt.Errorf(
+				"%w: expected %s, got %s",
+				ErrChecksumMismatch,
+				verification.Checksum,
+				actual,
+			)
+		}
+	}
+
+	if opts.enabled() {
+		file, err := fetchSignature(ctx, client, u.String()+signatureExtension)
+		if err != nil {
+			return nil, verification, err
+		}
+		verification.Signer, err = opts.verify(data, file)
+		if err != nil {
+			return nil, verification, err
+		}
 	}
 
-	var prompt Prompt
-	err = yaml.Unmarshal(data, &prompt)
+	prompt, err := ParsePrompt(data)
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling YAML: %v", err)
+		return nil, verification, err
 	}
 
-	// Validate the prompt
-	if err := prompt.Validate(); err != nil {
-		return nil, fmt.Errorf("error validating prompt: %v", err)
+	installed, err := LoadRawPrompt(prompt.ID)
+	switch {
+	case errors.Is(err, ErrPromptNotFound):
+	case err != nil:
+		return nil, verification, err
+	case opts.Confirm == nil:
+		return nil, verification, fmt.Errorf("%w: %s", ErrPromptExists, prompt.ID)
+	case !opts.Confirm(installed, prompt):
+		return nil, verification, fmt.Errorf(
+			"%w: %s, not replaced",
+			ErrPromptExists,
+			prompt.ID,
+		)
 	}
 
-	// Save the prompt
 	if err := prompt.Save(); err != nil {
-		return nil, fmt.Errorf("error saving prompt: %v", err)
+		return nil, verification, fmt.Errorf("error saving prompt: %v", err)
+	}
+
+	return prompt, verification, nil
+}
+
+// fetchSignature downloads the .minisig file, nil when there is none.
+func fetchSignature(
+	ctx context.Context,
+	client *http.Client,
+	signatureURL string,
+) ([]byte, error) {
+	file, err := fetchFile(ctx, client, signatureURL)
+	if err != nil {
+		if errors.Is(err, errRemoteNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching signature: %w", err)
+	}
+	return file, nil
+}
+
+// checkSignature returns the name of the trusted key which signed the
+// data, empty when there is no signature or it is of an unknown key. A
+// signature of a trusted key which does not match is an error.
+func checkSignature(
+	data, file []byte,
+	trustedKeys []config.TrustedKey,
+) (string, error) {
+	if file == nil {
+		return "", nil
+	}
+
+	sig, err := minisign.ParseSignature(file)
+	if err != nil {
+		return "", err
+	}
+
+	for _, trusted := range trustedKeys {
+		pk, err := minisign.ParsePublicKey(trusted.PublicKey)
+		if err != nil {
+			return "", fmt.Errorf("invalid trusted key %s: %w", trusted.Name, err)
+		}
+		if pk.ID != sig.KeyID {
+			continue
+		}
+
+		if err := pk.Verify(data, sig); err != nil {
+			return "", fmt.Errorf(
+				"error verifying signature of %s: %w",
+				trusted.Name,
+				err,
+			)
+		}
+		return trusted.Name, nil
 	}
 
-	return &prompt, nil
+	return "", nil
 }
@@ -0,0 +1,186 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// fragmentDirectory holds the fragments, within the prompt directory.
+const fragmentDirectory = "fragments"
+
+// fragmentExtension is the extension of the fragments named without one.
+const fragmentExtension = ".md"
+
+// ErrPromptCycle is returned when prompts extend each other.
+var ErrPromptCycle = errors.New("prompt extends cycle")
+
+// resolver loads the extended prompts and the fragments.
+type resolver struct {
+	loadPrompt   func(id string) (*Prompt, error)
+	loadFragment func(name string) (string, error)
+}
+
+// Resolve returns a copy of the prompt composed with the prompt it extends
+// and its included fragments:
+//   - the system prompt of the extended prompt comes first, then the system
+//     prompt and the fragments of the prompt
+//   - the variables and the preferences are inherited, unless overridden
+//   - the examples of the extended prompt come first
+//
+// The tests are not inherited.
+func (p *Prompt) Resolve() (*Prompt, error) {
+	r := resolver{loadPrompt: LoadRawPrompt, loadFragment: loadFragment}
+	return r.resolve(p, []string{p.ID})
+}
+
+func (r resolver) resolve(p *Prompt, chain []string) (*Prompt, error) {
+	resolved := *p
+	resolved.Extends = ""
+	resolved.Settings.Include = nil
+
+	if len(p.Settings.Include) > 0 {
+		parts := []string{p.Settings.SystemPrompt}
+		for _, name := range p.Settings.Include {
+			fragment, err := r.loadFragment(name)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"error including %s in prompt %s: %w",
+					name,
+					p.ID,
+					err,
+				)
+			}
+			parts = append(parts, fragment)
+		}
+		resolved.Settings.SystemPrompt = joinParagraphs(parts...)
+	}
+
+	if p.Extends == "" {
+		return &resolved, nil
+	}
+
+	chain = append(slices.Clone(chain), p.Extends)
+	if slices.Contains(chain[:len(chain)-1], p.Extends) {
+		return nil, fmt.Errorf(
+			"%w: %s",
+			ErrPromptCycle,
+			strings.Join(chain, " -> "),
+		)
+	}
+
+	parent, err := r.loadPrompt(p.Extends)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error loading prompt %s extended by %s: %w",
+			p.Extends,
+			p.ID,
+			err,
+		)
+	}
+
+	base, err := r.resolve(parent, chain)
+	if err != nil {
+		return nil, err
+	}
+	resolved.inherit(base)
+
+	return &resolved, nil
+}
+
+// inherit completes the prompt with the settings of the extended prompt.
+func (p *Prompt) inherit(base *Prompt) {
+	if p.Description == "" {
+		p.Description = base.Description
+	}
+
+	p.Settings.SystemPrompt = joinParagraphs(
+		base.Settings.SystemPrompt,
+		p.Settings.SystemPrompt,
+	)
+	if p.Settings.PrePrompt == nil {
+		p.Settings.PrePrompt = base.Settings.PrePrompt
+	}
+
+	var variables []Variable
+	for _, v := range base.Settings.Variables {
+		if !slices.ContainsFunc(p.Settings.Variables, func(o Variable) bool {
+			return o.Name == v.Name
+		}) {
+			variables = append(variables, v)
+		}
+	}
+	p.Settings.Variables = append(variables, p.Settings.Variables...)
+
+	p.Settings.Examples = append(
+		slices.Clone(base.Settings.Examples),
+		p.Settings.Examples...,
+	)
+
+	p.Preferences.inherit(base.Preferences)
+}
+
+// inherit sets the preferences left unset from the base ones.
+func (p *Preferences) inherit(base Preferences) {
+	p.Reasoning = p.Reasoning || base.Reasoning
+	p.JSON = p.JSON || base.JSON
+
+	if p.Temperature == nil {
+		p.Temperature = base.Temperature
+	}
+	if p.TopP == nil {
+		p.TopP = base.TopP
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = base.MaxTokens
+	}
+	if p.Stop == nil {
+		p.Stop = base.Stop
+	}
+	if p.Seed == nil {
+		p.Seed = base.Seed
+	}
+	if p.Models == nil {
+		p.Models = base.Models
+	}
+}
+
+// loadFragment reads a fragment of the fragment directory, named with or
+// without its extension.
+func loadFragment(name string) (string, error) {
+	if !validID(name) {
+		return "", fmt.Errorf("invalid fragment name %q", name)
+	}
+	if filepath.Ext(name) == "" {
+		name += fragmentExtension
+	}
+
+	// The fragments of the project take precedence
+	for _, dir := range promptDirectories() {
+		fp := filepath.Join(dir, fragmentDirectory, filepath.FromSlash(name))
+		data, err := os.ReadFile(fp)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading fragment: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	return "", fmt.Errorf("fragment not found: %s", name)
+}
+
+// joinParagraphs joins the non-empty texts with a blank line.
+func joinParagraphs(texts ...string) string {
+	var paragraphs []string
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
@@ -0,0 +1,118 @@
+package prompts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	temperature := float32(0.2)
+	stored := map[string]*Prompt{
+		"base": {
+			ID:          "base",
+			Description: "Base prompt",
+			Settings: Settings{
+				SystemPrompt: "Be concise.",
+				Include:      []string{"tone"},
+				Variables:    []Variable{{Name: "lang", Default: "en"}},
+				Examples:     []Example{{User: "hi", Assistant: "hello"}},
+			},
+			Preferences: Preferences{Temperature: &temperature},
+		},
+		"loop-a": {ID: "loop-a", Extends: "loop-b"},
+		"loop-b": {ID: "loop-b", Extends: "loop-a"},
+	}
+	fragments := map[string]string{"tone": "Be friendly.", "format": "Use lists."}
+
+	r := resolver{
+		loadPrompt: func(id string) (*Prompt, error) {
+			if p, ok := stored[id]; ok {
+				return p, nil
+			}
+			return nil, ErrPromptNotFound
+		},
+		loadFragment: func(name string) (string, error) {
+			if f, ok := fragments[name]; ok {
+				return f, nil
+			}
+			return "", errors.New("fragment not found")
+		},
+	}
+
+	tests := []struct {
+		name     string
+		prompt   Prompt
+		expected Settings
+		wantErr  error
+	}{
+		{
+			name: "Standalone",
+			prompt: Prompt{
+				ID:       "standalone",
+				Settings: Settings{SystemPrompt: "Answer.\n"},
+			},
+			expected: Settings{SystemPrompt: "Answer.\n"},
+		},
+		{
+			name: "Extends and include",
+			prompt: Prompt{
+				ID:      "child",
+				Extends: "base",
+				Settings: Settings{
+					SystemPrompt: "Review the code.",
+					Include:      []string{"format"},
+					Variables:    []Variable{{Name: "lang", Default: "fr"}},
+					Examples:     []Example{{User: "x", Assistant: "y"}},
+				},
+			},
+			expected: Settings{
+				SystemPrompt: "Be concise.\n\nBe friendly.\n\nReview the code.\n\nUse lists.",
+				Variables:    []Variable{{Name: "lang", Default: "fr"}},
+				Examples: []Example{
+					{User: "hi", Assistant: "hello"},
+					{User: "x", Assistant: "y"},
+				},
+			},
+		},
+		{
+			name:    "Cycle",
+			prompt:  Prompt{ID: "loop-a", Extends: "loop-b"},
+			wantErr: ErrPromptCycle,
+		},
+		{
+			name:    "Missing",
+			prompt:  Prompt{ID: "orphan", Extends: "nope"},
+			wantErr: ErrPromptNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolved, err := r.resolve(&tt.prompt, []string{tt.prompt.ID})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("resolve() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(resolved.Settings, tt.expected) {
+				t.Errorf("resolve() settings = %+v, want %+v", resolved.Settings, tt.expected)
+			}
+
+			if tt.prompt.Extends != "" &&
+				(resolved.Preferences.Temperature != &temperature ||
+					resolved.Description != "Base prompt") {
+				t.Errorf("resolve() did not inherit the base prompt: %+v", resolved)
+			}
+		})
+	}
+}
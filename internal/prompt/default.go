@@ -7,7 +7,6 @@ var DefaultPrompt = Prompt{
 	Name:        "Native Default Prompt",
 	Description: "Facilitates asking questions to the assistant.",
 	Preferences: Preferences{
-		Fast:      false,
 		Reasoning: false,
 	},
 	Settings: Settings{
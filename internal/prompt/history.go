@@ -0,0 +1,171 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/nullswan/nomi/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+var ErrVersionNotFound = errors.New("prompt version not found")
+
+func historyDirectory(id string) string {
+	return filepath.Join(config.GetPromptHistoryDirectory(), id)
+}
+
+// archive stores the prompt in the history, under its version.
+func (p *Prompt) archive() error {
+	if !validVersion(p.Metadata.Version) {
+		return fmt.Errorf("invalid version %q", p.Metadata.Version)
+	}
+
+	dir := historyDirectory(p.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("error marshalling prompt: %w", err)
+	}
+
+	fp := filepath.Join(dir, p.Metadata.Version+".yml")
+	if err := os.WriteFile(fp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing history file: %w", err)
+	}
+
+	return nil
+}
+
+// History returns the versions of the prompt, oldest first, the last one
+// is the current version.
+func History(id string) ([]Prompt, error) {
+	current, err := LoadRawPrompt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(historyDirectory(id))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading history directory: %w", err)
+	}
+
+	var versions []Prompt
+	for _, file := range files {
+		if file.IsDir() || !isValidFilename(file.Name()) {
+			continue
+		}
+
+		prompt, err := loadPromptFile(
+			filepath.Join(historyDirectory(id), file.Name()),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if prompt.Metadata.Version == current.Metadata.Version {
+			continue
+		}
+
+		versions = append(versions, *prompt)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(
+			versions[i].Metadata.Version,
+			versions[j].Metadata.Version,
+		) < 0
+	})
+
+	return append(versions, *current), nil
+}
+
+// LoadPromptVersion returns a version of the prompt, current or archived.
+func LoadPromptVersion(id, version string) (*Prompt, error) {
+	versions, err := History(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prompt := range versions {
+		if compareVersions(prompt.Metadata.Version, version) == 0 {
+			return &prompt, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrVersionNotFound, version)
+}
+
+// Rollback saves the content of a previous version as a new version.
+func Rollback(id, version string) (*Prompt, error) {
+	target, err := LoadPromptVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := LoadRawPrompt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Metadata.Version == current.Metadata.Version {
+		return nil, fmt.Errorf("%s is the current version", version)
+	}
+
+	// Save bumps the version from the current one
+	target.Metadata.Version = current.Metadata.Version
+	target.Metadata.CreatedAt = current.Metadata.CreatedAt
+	if err := target.Save(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// nextVersion returns the version of the prompt replacing previous, the
+// patch of previous is bumped unless the version was raised.
+func nextVersion(version, previous string) (string, error) {
+	v, vErr := semver.ParseTolerant(version)
+	prev, err := semver.ParseTolerant(previous)
+	if err != nil {
+		if version != previous {
+			return version, nil
+		}
+		return "", fmt.Errorf(
+			"cannot bump version %q, it is not a semantic version",
+			previous,
+		)
+	}
+
+	if vErr == nil && v.GT(prev) {
+		return version, nil
+	}
+
+	prev.Pre = nil
+	prev.Build = nil
+	prev.Patch++
+
+	return prev.String(), nil
+}
+
+// compareVersions compares semantic versions, and other versions as text.
+func compareVersions(a, b string) int {
+	va, errA := semver.ParseTolerant(a)
+	vb, errB := semver.ParseTolerant(b)
+	if errA != nil || errB != nil {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return va.Compare(vb)
+}
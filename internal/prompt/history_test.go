@@ -0,0 +1,171 @@
+package prompts
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version  string
+		previous string
+		expected string
+		wantErr  bool
+	}{
+		{version: "0.1.0", previous: "0.1.0", expected: "0.1.1"},
+		{version: "v1.2.3", previous: "v1.2.3", expected: "1.2.4"},
+		{version: "0.2.0", previous: "0.1.5", expected: "0.2.0"},
+		{version: "0.1.0", previous: "0.1.5", expected: "0.1.6"},
+		{version: "latest", previous: "0.1.0", expected: "0.1.1"},
+		{version: "1.0.0", previous: "latest", expected: "1.0.0"},
+		{version: "latest", previous: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+"_"+tt.previous, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := nextVersion(tt.version, tt.previous)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nextVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if version != tt.expected {
+				t.Errorf("nextVersion() = %q, want %q", version, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{version: "0.1.0", expected: true},
+		{version: "1.0.0-rc.1+build.5", expected: true},
+		{version: "latest", expected: true},
+		{version: "../../config", expected: false},
+		{version: "..", expected: false},
+		{version: "1.0/evil", expected: false},
+		{version: `1.0\evil`, expected: false},
+		{version: "C:evil", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			t.Parallel()
+
+			if got := validVersion(tt.version); got != tt.expected {
+				t.Errorf("validVersion(%q) = %v, want %v", tt.version, got, tt.expected)
+			}
+		})
+	}
+}
+
+// setupHome points the home directory, holding the prompts and their
+// history, to a temporary directory.
+func setupHome(t *testing.T) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+}
+
+func newTestPrompt(id, systemPrompt string) *Prompt {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &Prompt{
+		ID:       id,
+		Name:     "Test",
+		Settings: Settings{SystemPrompt: systemPrompt},
+		Metadata: Metadata{
+			CreatedAt: now,
+			UpdatedAt: now,
+			Version:   initialVersion,
+			Author:    "test",
+		},
+	}
+}
+
+func TestHistory(t *testing.T) {
+	setupHome(t)
+
+	const id = "test/history"
+	p := newTestPrompt(id, "v1")
+	for _, systemPrompt := range []string{"v1", "v2", "v3"} {
+		p.Settings.SystemPrompt = systemPrompt
+		if err := p.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	expected := []struct{ version, systemPrompt string }{
+		{"0.1.0", "v1"},
+		{"0.1.1", "v2"},
+		{"0.1.2", "v3"},
+	}
+	versions, err := History(id)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(versions) != len(expected) {
+		t.Fatalf("History() returned %d versions, want %d", len(versions), len(expected))
+	}
+	for i, e := range expected {
+		if versions[i].Metadata.Version != e.version ||
+			versions[i].Settings.SystemPrompt != e.systemPrompt {
+			t.Errorf(
+				"History()[%d] = %s %q, want %s %q",
+				i,
+				versions[i].Metadata.Version,
+				versions[i].Settings.SystemPrompt,
+				e.version,
+				e.systemPrompt,
+			)
+		}
+	}
+
+	version, err := LoadPromptVersion(id, "v0.1.1")
+	if err != nil {
+		t.Fatalf("LoadPromptVersion() error = %v", err)
+	}
+	if version.Settings.SystemPrompt != "v2" {
+		t.Errorf("LoadPromptVersion() = %q, want %q", version.Settings.SystemPrompt, "v2")
+	}
+	if _, err := LoadPromptVersion(id, "9.9.9"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("LoadPromptVersion() error = %v, want %v", err, ErrVersionNotFound)
+	}
+
+	// Rolling back saves the old content as a new version
+	rolledBack, err := Rollback(id, "0.1.0")
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if rolledBack.Metadata.Version != "0.1.3" || rolledBack.Settings.SystemPrompt != "v1" {
+		t.Errorf(
+			"Rollback() = %s %q, want 0.1.3 %q",
+			rolledBack.Metadata.Version,
+			rolledBack.Settings.SystemPrompt,
+			"v1",
+		)
+	}
+	current, err := LoadRawPrompt(id)
+	if err != nil {
+		t.Fatalf("LoadRawPrompt() error = %v", err)
+	}
+	if current.Settings.SystemPrompt != "v1" {
+		t.Errorf("current prompt = %q, want %q", current.Settings.SystemPrompt, "v1")
+	}
+	if versions, _ := History(id); len(versions) != 4 {
+		t.Errorf("History() returned %d versions after the rollback, want 4", len(versions))
+	}
+
+	if _, err := Rollback(id, "0.1.3"); err == nil {
+		t.Error("Rollback() to the current version succeeded")
+	}
+}
@@ -0,0 +1,115 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+// ParsePrompt reads and validates a YAML prompt.
+func ParsePrompt(data []byte) (*Prompt, error) {
+	var prompt Prompt
+	if err := yaml.Unmarshal(data, &prompt); err != nil {
+		return nil, fmt.Errorf("error unmarshalling YAML: %w", err)
+	}
+
+	if err := prompt.Validate(); err != nil {
+		return nil, fmt.Errorf("error validating prompt: %w", err)
+	}
+
+	return &prompt, nil
+}
+
+// ImportPrompt saves a YAML prompt, an installed prompt of the same ID is
+// only replaced when overwrite is set.
+func ImportPrompt(data []byte, overwrite bool) (*Prompt, error) {
+	prompt, err := ParsePrompt(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkNotInstalled(prompt.ID); err != nil {
+		if !overwrite || !errors.Is(err, ErrPromptExists) {
+			return nil, err
+		}
+	}
+
+	if err := prompt.Save(); err != nil {
+		return nil, err
+	}
+
+	return prompt, nil
+}
+
+// CopyPrompt saves a copy of the prompt under a new ID, as a first version.
+func CopyPrompt(id, newID string) (*Prompt, error) {
+	prompt, err := LoadRawPrompt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkNotInstalled(newID); err != nil {
+		return nil, err
+	}
+
+	author := prompt.Metadata.Author
+	prompt.ID = newID
+	prompt.Metadata = NewPrompt(newID, prompt.Name).Metadata
+	prompt.Metadata.Author = author
+
+	if err := prompt.Validate(); err != nil {
+		return nil, fmt.Errorf("error validating prompt: %w", err)
+	}
+
+	if err := prompt.Save(); err != nil {
+		return nil, err
+	}
+
+	return prompt, nil
+}
+
+// DeletePrompt removes the prompt of the user and its history, a prompt of
+// the project with the same ID is left as is.
+func DeletePrompt(id string) error {
+	if !validID(id) {
+		return fmt.Errorf("%w: %s", ErrPromptNotFound, id)
+	}
+
+	dir := config.GetPromptDirectory()
+	fp := filepath.Join(dir, filepath.FromSlash(id+".yml"))
+	if err := os.Remove(fp); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s in %s", ErrPromptNotFound, id, dir)
+		}
+		return fmt.Errorf("error deleting prompt: %w", err)
+	}
+
+	if err := os.RemoveAll(historyDirectory(id)); err != nil {
+		return fmt.Errorf("error deleting prompt history: %w", err)
+	}
+
+	// Remove the directory of the namespace once empty
+	if strings.Contains(id, "/") {
+		_ = os.Remove(filepath.Dir(fp))
+	}
+
+	return nil
+}
+
+// checkNotInstalled returns ErrPromptExists when the prompt is installed.
+func checkNotInstalled(id string) error {
+	_, err := LoadRawPrompt(id)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: %s", ErrPromptExists, id)
+	case errors.Is(err, ErrPromptNotFound):
+		return nil
+	default:
+		return err
+	}
+}
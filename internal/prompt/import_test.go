@@ -0,0 +1,199 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nullswan/nomi/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+func marshalPrompt(t *testing.T, p *Prompt) []byte {
+	t.Helper()
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestImportPrompt(t *testing.T) {
+	setupHome(t)
+
+	const id = "test/imported"
+	tests := []struct {
+		name         string
+		data         []byte
+		overwrite    bool
+		systemPrompt string
+		wantErr      bool
+		errIs        error
+	}{
+		{
+			name:         "new",
+			data:         marshalPrompt(t, newTestPrompt(id, "first")),
+			systemPrompt: "first",
+		},
+		{
+			name:         "installed",
+			data:         marshalPrompt(t, newTestPrompt(id, "second")),
+			systemPrompt: "first",
+			wantErr:      true,
+			errIs:        ErrPromptExists,
+		},
+		{
+			name:         "overwrite",
+			data:         marshalPrompt(t, newTestPrompt(id, "second")),
+			overwrite:    true,
+			systemPrompt: "second",
+		},
+		{
+			name:         "invalid",
+			data:         marshalPrompt(t, newTestPrompt(id, "")),
+			overwrite:    true,
+			systemPrompt: "second",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportPrompt(tt.data, tt.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportPrompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Fatalf("ImportPrompt() error = %v, want %v", err, tt.errIs)
+			}
+
+			installed, err := LoadRawPrompt(id)
+			if err != nil {
+				t.Fatalf("LoadRawPrompt() error = %v", err)
+			}
+			if installed.Settings.SystemPrompt != tt.systemPrompt {
+				t.Errorf(
+					"installed prompt = %q, want %q",
+					installed.Settings.SystemPrompt,
+					tt.systemPrompt,
+				)
+			}
+		})
+	}
+}
+
+func TestCopyPrompt(t *testing.T) {
+	setupHome(t)
+
+	const id = "test/original"
+	original := newTestPrompt(id, "v1")
+	original.Metadata.CreatedAt = original.Metadata.CreatedAt.Add(-time.Hour)
+	for _, systemPrompt := range []string{"v1", "v2"} {
+		original.Settings.SystemPrompt = systemPrompt
+		if err := original.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := newTestPrompt("test/taken", "taken").Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		newID   string
+		wantErr bool
+		errIs   error
+	}{
+		{name: "copy", id: id, newID: "test/copy"},
+		{name: "installed", id: id, newID: "test/taken", wantErr: true, errIs: ErrPromptExists},
+		{name: "missing", id: "test/missing", newID: "test/other", wantErr: true, errIs: ErrPromptNotFound},
+		{name: "invalid", id: id, newID: "test/a/b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied, err := CopyPrompt(tt.id, tt.newID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CopyPrompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("CopyPrompt() error = %v, want %v", err, tt.errIs)
+			}
+			if err != nil {
+				return
+			}
+
+			// The copy starts a new history, keeping the author
+			if copied.ID != tt.newID ||
+				copied.Settings.SystemPrompt != "v2" ||
+				copied.Metadata.Version != initialVersion ||
+				copied.Metadata.Author != "test" ||
+				!copied.Metadata.CreatedAt.After(original.Metadata.CreatedAt) {
+				t.Errorf("CopyPrompt() = %+v", copied)
+			}
+			if versions, _ := History(tt.newID); len(versions) != 1 {
+				t.Errorf("History() of the copy returned %d versions, want 1", len(versions))
+			}
+			if versions, _ := History(tt.id); len(versions) != 2 {
+				t.Errorf("History() of the original returned %d versions, want 2", len(versions))
+			}
+		})
+	}
+}
+
+func TestDeletePrompt(t *testing.T) {
+	setupHome(t)
+
+	for _, id := range []string{"testdelete/only", "testdelete/kept", "testdelete/other", "testdelete-flat"} {
+		p := newTestPrompt(id, "v1")
+		for _, systemPrompt := range []string{"v1", "v2"} {
+			p.Settings.SystemPrompt = systemPrompt
+			if err := p.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+	}
+	namespace := filepath.Join(config.GetPromptDirectory(), "testdelete")
+
+	tests := []struct {
+		id        string
+		namespace bool
+		wantErr   error
+	}{
+		{id: "testdelete/only", namespace: true},
+		{id: "testdelete/only", namespace: true, wantErr: ErrPromptNotFound},
+		{id: "testdelete/kept", namespace: true},
+		{id: "testdelete-flat", namespace: true},
+		{id: "testdelete/other", namespace: false},
+		{id: "../testdelete", wantErr: ErrPromptNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			err := DeletePrompt(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeletePrompt() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if _, err := LoadRawPrompt(tt.id); !errors.Is(err, ErrPromptNotFound) {
+				t.Errorf("LoadRawPrompt() after DeletePrompt() error = %v", err)
+			}
+			if _, err := os.Stat(historyDirectory(tt.id)); !os.IsNotExist(err) {
+				t.Errorf("history of %s kept: %v", tt.id, err)
+			}
+
+			// The namespace directory is removed with its last prompt
+			_, err = os.Stat(namespace)
+			if exists := err == nil; exists != tt.namespace {
+				t.Errorf("namespace directory exists = %v, want %v", exists, tt.namespace)
+			}
+		})
+	}
+}
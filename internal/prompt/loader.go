@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/nullswan/nomi/internal/config"
@@ -13,16 +14,33 @@ import (
 
 var ErrPromptNotFound = errors.New("prompt not found")
 
+// LoadPrompt returns the prompt with its extended prompt and its included
+// fragments resolved.
 func LoadPrompt(filename string) (*Prompt, error) {
+	prompt, err := LoadRawPrompt(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return prompt.Resolve()
+}
+
+// LoadRawPrompt returns the prompt as stored, with extends and include
+// left unresolved, e.g. to edit it.
+func LoadRawPrompt(filename string) (*Prompt, error) {
 	if !strings.HasSuffix(filename, ".yml") {
 		filename += ".yml"
 	}
 
-	fp := filepath.Join(config.GetPromptDirectory(), filename)
+	fp := promptFile(filename)
 	if _, err := os.Stat(fp); os.IsNotExist(err) {
 		return nil, ErrPromptNotFound
 	}
 
+	return loadPromptFile(fp)
+}
+
+func loadPromptFile(fp string) (*Prompt, error) {
 	data, err := os.ReadFile(fp)
 	if err != nil {
 		return nil, fmt.Errorf("error reading prompt file: %w", err)
@@ -37,24 +55,56 @@ func LoadPrompt(filename string) (*Prompt, error) {
 	return &prompt, nil
 }
 
-func ListPrompts() ([]Prompt, error) {
-	files, err := os.ReadDir(config.GetPromptDirectory())
-	if err != nil {
-		return nil, fmt.Errorf("error reading data directory: %w", err)
+// promptDirectories returns the directories of the prompts by precedence,
+// the one of the project first.
+func promptDirectories() []string {
+	var dirs []string
+	if dir := config.GetProjectPromptDirectory(); dir != "" {
+		dirs = append(dirs, dir)
 	}
+	return append(dirs, config.GetPromptDirectory())
+}
 
-	var prompts []Prompt
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+// promptFile returns the path of the prompt file in the first directory
+// holding it, in the directory of the user otherwise.
+func promptFile(filename string) string {
+	for _, dir := range promptDirectories() {
+		fp := filepath.Join(dir, filepath.FromSlash(filename))
+		if _, err := os.Stat(fp); err == nil {
+			return fp
+		}
+	}
+	return filepath.Join(config.GetPromptDirectory(), filepath.FromSlash(filename))
+}
+
+// ListPrompts returns the prompts of the project and of the user, the
+// prompts of the project hiding the ones of the user with the same ID.
+func ListPrompts() ([]Prompt, error) {
+	seen := make(map[string]bool)
+	for _, dir := range promptDirectories() {
+		dirNames, err := listPromptFiles(dir)
+		if err != nil {
+			return nil, err
 		}
+		for _, name := range dirNames {
+			seen[name] = true
+		}
+	}
 
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var prompts []Prompt
+	for _, name := range names {
 		// Prompt files are YAML files
-		if !isValidFilename(file.Name()) {
+		if !isValidFilename(name) {
 			continue
 		}
 
-		prompt, err := LoadPrompt(file.Name())
+		prompt, err := LoadRawPrompt(name)
 		if err != nil {
 			return nil, fmt.Errorf("error loading prompt: %w", err)
 		}
@@ -69,6 +119,36 @@ func ListPrompts() ([]Prompt, error) {
 	return prompts, nil
 }
 
+// listPromptFiles returns the names of the files of the directory and of
+// its namespace directories.
+func listPromptFiles(dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading data directory: %w", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() {
+			names = append(names, file.Name())
+			continue
+		}
+
+		// Namespaced prompts are stored in the directory of their namespace
+		nested, err := os.ReadDir(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading data directory: %w", err)
+		}
+		for _, n := range nested {
+			if !n.IsDir() {
+				names = append(names, file.Name()+"/"+n.Name())
+			}
+		}
+	}
+
+	return names, nil
+}
+
 func isValidFilename(filename string) bool {
 	return filepath.Ext(filename) == ".yml"
 }
@@ -1,24 +1,98 @@
 package prompts
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nullswan/nomi/internal/completion"
+)
 
 type Prompt struct {
 	ID          string      `yaml:"id"`
 	Name        string      `yaml:"name"`
 	Description string      `yaml:"description"`
+	Extends     string      `yaml:"extends,omitempty"`
 	Settings    Settings    `yaml:"settings"`
 	Metadata    Metadata    `yaml:"metadata"`
 	Preferences Preferences `yaml:"preferences"`
+	// Tests are run with nomi prompt test
+	Tests []TestCase `yaml:"tests,omitempty"`
 }
 
 type Settings struct {
 	SystemPrompt string  `yaml:"system_prompt"`
 	PrePrompt    *string `yaml:"pre_prompt"`
+	// Variables of the templates of the system prompt and the pre-prompt
+	Variables []Variable `yaml:"variables,omitempty"`
+	// Include are the fragments appended to the system prompt
+	Include []string `yaml:"include,omitempty"`
+	// Examples are few-shot exchanges sent before the conversation
+	Examples []Example `yaml:"examples,omitempty"`
+}
+
+// Example is a few-shot exchange of a user message and the expected answer.
+type Example struct {
+	User      string `yaml:"user"`
+	Assistant string `yaml:"assistant"`
 }
 
 type Preferences struct {
+	// Deprecated: Fast is not used, list the preferred Models instead.
 	Fast      bool `yaml:"fast"`
 	Reasoning bool `yaml:"reasoning"`
+
+	Temperature *float32 `yaml:"temperature,omitempty"`
+	TopP        *float32 `yaml:"top_p,omitempty"`
+	MaxTokens   int      `yaml:"max_tokens,omitempty"`
+	Stop        []string `yaml:"stop,omitempty"`
+	Seed        *int     `yaml:"seed,omitempty"`
+	// JSON asks the model to answer with a JSON object
+	JSON bool `yaml:"json,omitempty"`
+	// Models are tried in order, the first one whose provider is
+	// available is used
+	Models []ModelPreference `yaml:"models,omitempty"`
+}
+
+// ModelPreference is a model of a provider, an empty provider matches the
+// provider in use.
+type ModelPreference struct {
+	Provider string `yaml:"provider,omitempty"`
+	Model    string `yaml:"model"`
+}
+
+// RequestOptions returns the parameters of the completion requests.
+func (p Preferences) RequestOptions() completion.RequestOptions {
+	return completion.RequestOptions{
+		Temperature: p.Temperature,
+		TopP:        p.TopP,
+		MaxTokens:   p.MaxTokens,
+		Stop:        p.Stop,
+		Seed:        p.Seed,
+		JSON:        p.JSON,
+	}
+}
+
+func (p Preferences) validate() error {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return errors.New("Prompt temperature must be between 0 and 2")
+	}
+
+	if p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1) {
+		return errors.New("Prompt top_p must be between 0 and 1")
+	}
+
+	if p.MaxTokens < 0 {
+		return errors.New("Prompt max_tokens cannot be negative")
+	}
+
+	for i, m := range p.Models {
+		if m.Model == "" {
+			return fmt.Errorf("Prompt model %d has no name", i+1)
+		}
+	}
+
+	return nil
 }
 
 type Metadata struct {
@@ -27,3 +101,21 @@ type Metadata struct {
 	Version   string    `yaml:"version"`
 	Author    string    `yaml:"author"`
 }
+
+// initialVersion is the version of the new prompts.
+const initialVersion = "0.1.0"
+
+// NewPrompt returns a first version of a prompt, created now.
+func NewPrompt(id, name string) Prompt {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return Prompt{
+		ID:   id,
+		Name: name,
+		Metadata: Metadata{
+			CreatedAt: now,
+			UpdatedAt: now,
+			Version:   initialVersion,
+		},
+	}
+}
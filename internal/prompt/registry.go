@@ -0,0 +1,295 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nullswan/nomi/internal/config"
+	embedded "github.com/nullswan/nomi/prompts"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// registryIndex lists the prompt files of the registry.
+	registryIndex = "index.yml"
+
+	// fetchTimeout bounds the download of each remote file.
+	fetchTimeout = 30 * time.Second
+
+	// maxFetchSize bounds the size of each remote file.
+	maxFetchSize = 1 << 20
+)
+
+var ErrPromptExists = errors.New("prompt already installed")
+
+// errRemoteNotFound is returned when a remote file does not exist.
+var errRemoteNotFound = errors.New("not found")
+
+type registryIndexFile struct {
+	Prompts []string `yaml:"prompts"`
+}
+
+// Registry lists the prompts available to install, their IDs are
+// namespaced by the directory of their author.
+type Registry struct {
+	Prompts []Prompt
+}
+
+// LoadRegistry returns the registry downloaded by UpdateRegistry, without
+// the prompts the trust refuses, or the one embedded in the binary.
+func LoadRegistry(trust Trust) (*Registry, error) {
+	cache := config.GetRegistryDirectory()
+	if _, err := os.Stat(filepath.Join(cache, registryIndex)); err == nil {
+		return loadRegistry(os.DirFS(cache), trust)
+	}
+
+	// The embedded registry ships with the binary
+	return loadRegistry(embedded.FS, Trust{})
+}
+
+// UpdateRegistry downloads the registry served at the URL and caches it
+// for LoadRegistry. The signature of each prompt is served next to it, at
+// <name>.minisig, the update fails when the trust refuses one of them.
+func UpdateRegistry(
+	ctx context.Context,
+	url string,
+	trust Trust,
+) (*Registry, error) {
+	url = strings.TrimSuffix(url, "/")
+	client := &http.Client{Timeout: fetchTimeout}
+
+	data, err := fetchFile(ctx, client, url+"/"+registryIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var index registryIndexFile
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("error unmarshalling registry index: %w", err)
+	}
+
+	files := map[string][]byte{registryIndex: data}
+	for _, name := range index.Prompts {
+		if !fs.ValidPath(name) || !isValidFilename(name) {
+			return nil, fmt.Errorf("invalid registry file: %s", name)
+		}
+
+		data, err := fetchFile(ctx, client, url+"/"+name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+
+		if !trust.enabled() {
+			continue
+		}
+		file, err := fetchSignature(ctx, client, url+"/"+name+signatureExtension)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := trust.verify(data, file); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if file != nil {
+			files[name+signatureExtension] = file
+		}
+	}
+
+	// Replace the cache once every file is downloaded
+	cache := config.GetRegistryDirectory()
+	if err := os.RemoveAll(cache); err != nil {
+		return nil, fmt.Errorf("error clearing registry cache: %w", err)
+	}
+	for name, data := range files {
+		fp := filepath.Join(cache, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			return nil, fmt.Errorf("error creating registry cache: %w", err)
+		}
+		if err := os.WriteFile(fp, data, 0o644); err != nil {
+			return nil, fmt.Errorf("error writing registry cache: %w", err)
+		}
+	}
+
+	return loadRegistry(os.DirFS(cache), trust)
+}
+
+// fetchFile downloads a remote file, bounded by maxFetchSize.
+func fetchFile(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("error fetching %s: %w", url, errRemoteNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"error fetching %s: received status code %d",
+			url,
+			resp.StatusCode,
+		)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", url, err)
+	}
+	if len(data) > maxFetchSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, maxFetchSize)
+	}
+
+	return data, nil
+}
+
+// loadRegistry reads the prompts listed by the index, the invalid ones and
+// the ones the trust refuses are skipped.
+func loadRegistry(fsys fs.FS, trust Trust) (*Registry, error) {
+	data, err := fs.ReadFile(fsys, registryIndex)
+	if err != nil {
+		return nil, fmt.Errorf("error reading registry index: %w", err)
+	}
+
+	var index registryIndexFile
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("error unmarshalling registry index: %w", err)
+	}
+
+	registry := &Registry{}
+	for _, name := range index.Prompts {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+
+		if trust.enabled() {
+			file, _ := fs.ReadFile(fsys, name+signatureExtension)
+			if _, err := trust.verify(data, file); err != nil {
+				continue
+			}
+		}
+
+		var prompt Prompt
+		if err := yaml.Unmarshal(data, &prompt); err != nil {
+			continue
+		}
+		if dir := path.Dir(name); dir != "." {
+			prompt.ID = dir + "/" + prompt.ID
+		}
+		if err := prompt.Validate(); err != nil {
+			continue
+		}
+
+		registry.Prompts = append(registry.Prompts, prompt)
+	}
+
+	sort.Slice(registry.Prompts, func(i, j int) bool {
+		return registry.Prompts[i].ID < registry.Prompts[j].ID
+	})
+
+	return registry, nil
+}
+
+// Get returns the prompt of the registry.
+func (r *Registry) Get(id string) (*Prompt, error) {
+	for _, prompt := range r.Prompts {
+		if prompt.ID == id {
+			return &prompt, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w in the registry: %s", ErrPromptNotFound, id)
+}
+
+// Search returns the prompts matching every word of the query in their
+// ID, name, description or author.
+func (r *Registry) Search(query string) []Prompt {
+	words := strings.Fields(strings.ToLower(query))
+
+	var matches []Prompt
+	for _, prompt := range r.Prompts {
+		text := strings.ToLower(strings.Join([]string{
+			prompt.ID,
+			prompt.Name,
+			prompt.Description,
+			prompt.Metadata.Author,
+		}, " "))
+
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, prompt)
+		}
+	}
+
+	return matches
+}
+
+// Install saves the prompt of the registry.
+func (r *Registry) Install(id string) (*Prompt, error) {
+	prompt, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkNotInstalled(id); err != nil {
+		return nil, err
+	}
+
+	if err := prompt.Save(); err != nil {
+		return nil, err
+	}
+
+	return prompt, nil
+}
+
+// Upgrade saves the prompts of the registry newer than the installed
+// ones, and returns them.
+func (r *Registry) Upgrade() ([]Prompt, error) {
+	var upgraded []Prompt
+	for _, prompt := range r.Prompts {
+		installed, err := LoadRawPrompt(prompt.ID)
+		if err != nil {
+			continue
+		}
+
+		if compareVersions(
+			prompt.Metadata.Version,
+			installed.Metadata.Version,
+		) <= 0 {
+			continue
+		}
+
+		if err := prompt.Save(); err != nil {
+			return upgraded, err
+		}
+		upgraded = append(upgraded, prompt)
+	}
+
+	return upgraded, nil
+}
@@ -0,0 +1,197 @@
+package prompts
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nullswan/nomi/internal/config"
+	embedded "github.com/nullswan/nomi/prompts"
+	"golang.org/x/crypto/blake2b"
+	"gopkg.in/yaml.v2"
+)
+
+func TestEmbeddedRegistry(t *testing.T) {
+	t.Parallel()
+
+	data, err := fs.ReadFile(embedded.FS, registryIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index registryIndexFile
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every prompt of the repository is listed, and valid
+	files, err := fs.Glob(embedded.FS, "*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := fs.Glob(embedded.FS, "*/*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, nested...)
+
+	registry, err := loadRegistry(embedded.FS, Trust{})
+	if err != nil {
+		t.Fatalf("loadRegistry() error = %v", err)
+	}
+	if len(registry.Prompts) != len(index.Prompts) ||
+		len(index.Prompts) != len(files)-1 {
+		t.Errorf(
+			"%d files, %d indexed, %d loaded",
+			len(files)-1,
+			len(index.Prompts),
+			len(registry.Prompts),
+		)
+	}
+
+	seen := make(map[string]bool)
+	for _, prompt := range registry.Prompts {
+		if seen[prompt.ID] {
+			t.Errorf("duplicate prompt ID %s", prompt.ID)
+		}
+		seen[prompt.ID] = true
+	}
+
+	for _, id := range []string{"ask", "ethan0905/sam", "Mah/react", "Numedios/react"} {
+		if _, err := registry.Get(id); err != nil {
+			t.Errorf("Get(%q) error = %v", id, err)
+		}
+	}
+}
+
+func TestRegistrySearch(t *testing.T) {
+	t.Parallel()
+
+	registry := &Registry{Prompts: []Prompt{
+		{ID: "ask", Name: "Ask", Description: "Ask questions"},
+		{ID: "alice/react", Name: "React expert", Metadata: Metadata{Author: "alice"}},
+		{ID: "bob/react", Name: "React teacher", Metadata: Metadata{Author: "bob"}},
+	}}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "", expected: []string{"ask", "alice/react", "bob/react"}},
+		{query: "REACT", expected: []string{"alice/react", "bob/react"}},
+		{query: "react bob", expected: []string{"bob/react"}},
+		{query: "vue", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			matches := registry.Search(tt.query)
+			if len(matches) != len(tt.expected) {
+				t.Fatalf("Search(%q) = %d prompts, want %v", tt.query, len(matches), tt.expected)
+			}
+			for i, prompt := range matches {
+				if prompt.ID != tt.expected[i] {
+					t.Errorf("Search(%q)[%d] = %s, want %s", tt.query, i, prompt.ID, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryTrust(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	trusted := []config.TrustedKey{{
+		Name: "ci",
+		PublicKey: base64.StdEncoding.EncodeToString(
+			append(append([]byte("Ed"), keyID...), pub...),
+		),
+	}}
+
+	// sign returns the minisign signature of the data, prehashed
+	sign := func(data []byte) []byte {
+		sum := blake2b.Sum512(data)
+		sig := ed25519.Sign(priv, sum[:])
+		comment := "timestamp:1"
+		global := ed25519.Sign(priv, append(bytes.Clone(sig), comment...))
+		return []byte("untrusted comment: signature\n" +
+			base64.StdEncoding.EncodeToString(
+				append(append([]byte("ED"), keyID...), sig...),
+			) + "\n" +
+			"trusted comment: " + comment + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n")
+	}
+
+	prompt := func(id string) []byte {
+		return []byte("id: " + id + "\nname: " + id + `
+settings:
+  system_prompt: hi
+metadata:
+  created_at: "2024-10-02T00:00:00Z"
+  updated_at: "2024-10-02T00:00:00Z"
+  version: "0.1.0"
+  author: ci
+`)
+	}
+
+	fsys := fstest.MapFS{
+		registryIndex: {Data: []byte(
+			"prompts: [signed.yml, unsigned.yml, tampered.yml]\n",
+		)},
+		"signed.yml":           {Data: prompt("signed")},
+		"signed.yml.minisig":   {Data: sign(prompt("signed"))},
+		"unsigned.yml":         {Data: prompt("unsigned")},
+		"tampered.yml":         {Data: prompt("tampered")},
+		"tampered.yml.minisig": {Data: sign(prompt("other"))},
+	}
+
+	tests := []struct {
+		name     string
+		trust    Trust
+		expected []string
+	}{
+		{
+			name:     "No trust",
+			expected: []string{"signed", "tampered", "unsigned"},
+		},
+		{
+			name:     "Trusted keys",
+			trust:    Trust{TrustedKeys: trusted},
+			expected: []string{"signed", "unsigned"},
+		},
+		{
+			name:     "Required signature",
+			trust:    Trust{TrustedKeys: trusted, RequireSignature: true},
+			expected: []string{"signed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry, err := loadRegistry(fsys, tt.trust)
+			if err != nil {
+				t.Fatalf("loadRegistry() error = %v", err)
+			}
+
+			var ids []string
+			for _, p := range registry.Prompts {
+				ids = append(ids, p.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("loadRegistry() = %v, want %v", ids, tt.expected)
+			}
+		})
+	}
+}
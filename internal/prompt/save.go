@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
-	"github.com/nullswan/nomi/internal/config"
 	"gopkg.in/yaml.v2"
 )
 
@@ -15,18 +17,54 @@ func (p *Prompt) Validate() error {
 		return errors.New("Prompt ID is required")
 	}
 
+	if !validID(p.ID) {
+		return fmt.Errorf(
+			"Prompt ID %q is invalid, expected <id> or <namespace>/<id>",
+			p.ID,
+		)
+	}
+
 	if p.Name == "" {
 		return errors.New("Prompt Name is required")
 	}
 
-	if p.Settings.SystemPrompt == "" {
+	// The system prompt can come from the extended prompt or the fragments
+	if p.Settings.SystemPrompt == "" && p.Extends == "" &&
+		len(p.Settings.Include) == 0 {
 		return errors.New("Prompt SystemPrompt is required")
 	}
 
+	if p.Extends != "" && !validID(p.Extends) {
+		return fmt.Errorf("Prompt extends %q is not a valid ID", p.Extends)
+	}
+
+	if p.Extends == p.ID {
+		return errors.New("Prompt cannot extend itself")
+	}
+
+	for _, name := range p.Settings.Include {
+		if !validID(name) {
+			return fmt.Errorf("Prompt include %q is not a valid fragment", name)
+		}
+	}
+
+	for i, e := range p.Settings.Examples {
+		if e.User == "" || e.Assistant == "" {
+			return fmt.Errorf(
+				"Prompt example %d requires a user and an assistant message",
+				i+1,
+			)
+		}
+	}
+
 	if p.Metadata.Version == "" {
 		return errors.New("Prompt Version is required")
 	}
 
+	if !validVersion(p.Metadata.Version) {
+		return fmt.Errorf("Prompt Version %q is invalid", p.Metadata.Version)
+	}
+
 	if p.Metadata.Author == "" {
 		return errors.New("Prompt Author is required")
 	}
@@ -39,19 +77,80 @@ func (p *Prompt) Validate() error {
 		return errors.New("Prompt UpdatedAt is required")
 	}
 
-	return nil
+	if err := p.Preferences.validate(); err != nil {
+		return err
+	}
+
+	if err := validateTests(p.Tests); err != nil {
+		return err
+	}
+
+	return p.validateTemplates()
+}
+
+// validID reports whether the ID is a name, optionally namespaced, usable
+// as a file path.
+func validID(id string) bool {
+	parts := strings.Split(id, "/")
+	if len(parts) > 2 {
+		return false
+	}
+
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." ||
+			strings.ContainsAny(part, `\:`) {
+			return false
+		}
+	}
+
+	return true
 }
 
-// Save a prompt to the disk
+// validVersion reports whether the version is usable as the name of its
+// history file.
+func validVersion(version string) bool {
+	return !strings.Contains(version, "..") &&
+		!strings.ContainsAny(version, `/\:`)
+}
+
+// Save a prompt to the disk, archiving the previous version. The patch of
+// the version is bumped when a change keeps the same version.
 // TODO(nullswan): Use a store ID instead of the prompt ID
 func (p *Prompt) Save() error {
-	promptFile := filepath.Join(config.GetPromptDirectory(), p.ID+".yml")
+	fp := promptFile(p.ID + ".yml")
+	if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+		return fmt.Errorf("Error creating prompt directory: %v", err)
+	}
+
+	previous, err := LoadRawPrompt(p.ID)
+	switch {
+	case errors.Is(err, ErrPromptNotFound):
+	case err != nil:
+		return fmt.Errorf("Error loading previous prompt: %v", err)
+	case reflect.DeepEqual(p, previous):
+		return nil
+	default:
+		version, err := nextVersion(
+			p.Metadata.Version,
+			previous.Metadata.Version,
+		)
+		if err != nil {
+			return fmt.Errorf("Error versioning prompt: %v", err)
+		}
+		p.Metadata.Version = version
+		p.Metadata.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+		if err := previous.archive(); err != nil {
+			return fmt.Errorf("Error archiving previous prompt: %v", err)
+		}
+	}
+
 	outData, err := yaml.Marshal(p)
 	if err != nil {
 		return fmt.Errorf("Error marshalling prompt to YAML: %v", err)
 	}
 
-	err = os.WriteFile(promptFile, outData, 0o644)
+	err = os.WriteFile(fp, outData, 0o644)
 	if err != nil {
 		return fmt.Errorf("Error writing prompt file: %v", err)
 	}
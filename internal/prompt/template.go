@@ -0,0 +1,274 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// maxIncludeSize bounds the files included with the file function.
+	maxIncludeSize = 1 << 20
+
+	// shellTimeout bounds the commands run with the shell function.
+	shellTimeout = 30 * time.Second
+)
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Variable of a templated prompt, referenced as {{ .name }}.
+type Variable struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// RenderOptions resolve the variables and guard the functions of the
+// templates.
+type RenderOptions struct {
+	// Vars are the values given for the variables, e.g. with -v key=value
+	Vars map[string]string
+	// Ask returns the value of a required variable which was not given,
+	// such variables are an error when nil
+	Ask func(v Variable) (string, error)
+	// Confirm allows a call of the file, env or shell functions, which
+	// read the files and the environment of the user or run a command,
+	// the calls are refused when nil
+	Confirm func(function, argument string) bool
+}
+
+// IsTemplated reports whether the prompt declares variables or uses
+// template actions.
+func (p *Prompt) IsTemplated() bool {
+	return len(p.Settings.Variables) > 0 ||
+		strings.Contains(p.Settings.SystemPrompt, "{{") ||
+		(p.Settings.PrePrompt != nil && strings.Contains(*p.Settings.PrePrompt, "{{"))
+}
+
+// Render returns a copy of the prompt with its system prompt and pre-prompt
+// executed as Go templates.
+func (p *Prompt) Render(opts RenderOptions) (*Prompt, error) {
+	rendered := *p
+	if !p.IsTemplated() {
+		return &rendered, nil
+	}
+
+	data, err := p.resolveVariables(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := templateFuncs(opts.Confirm)
+
+	rendered.Settings.SystemPrompt, err = renderTemplate(
+		"system_prompt",
+		p.Settings.SystemPrompt,
+		funcs,
+		data,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Settings.PrePrompt != nil {
+		prePrompt, err := renderTemplate(
+			"pre_prompt",
+			*p.Settings.PrePrompt,
+			funcs,
+			data,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rendered.Settings.PrePrompt = &prePrompt
+	}
+
+	return &rendered, nil
+}
+
+// resolveVariables returns the value of every variable: the given one,
+// the answer to Ask for the required ones, or the default.
+func (p *Prompt) resolveVariables(opts RenderOptions) (map[string]string, error) {
+	data := make(map[string]string, len(opts.Vars)+len(p.Settings.Variables))
+	for k, v := range opts.Vars {
+		data[k] = v
+	}
+
+	for _, v := range p.Settings.Variables {
+		if _, ok := data[v.Name]; ok {
+			continue
+		}
+
+		if !v.Required {
+			data[v.Name] = v.Default
+			continue
+		}
+
+		if opts.Ask == nil {
+			return nil, fmt.Errorf("missing variable %q, set it with -v %s=<value>", v.Name, v.Name)
+		}
+
+		value, err := opts.Ask(v)
+		if err != nil {
+			return nil, fmt.Errorf("error reading variable %q: %w", v.Name, err)
+		}
+		data[v.Name] = value
+	}
+
+	return data, nil
+}
+
+func renderTemplate(
+	name, text string,
+	funcs template.FuncMap,
+	data map[string]string,
+) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).
+		Funcs(funcs).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s: %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error executing %s: %w", name, err)
+	}
+
+	return sb.String(), nil
+}
+
+// validateTemplates checks the variables and the syntax of the templates.
+func (p *Prompt) validateTemplates() error {
+	seen := make(map[string]bool, len(p.Settings.Variables))
+	for _, v := range p.Settings.Variables {
+		if !variableName.MatchString(v.Name) {
+			return fmt.Errorf("Prompt variable name %q is invalid", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("Prompt variable %q is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+	}
+
+	texts := map[string]string{"system_prompt": p.Settings.SystemPrompt}
+	if p.Settings.PrePrompt != nil {
+		texts["pre_prompt"] = *p.Settings.PrePrompt
+	}
+	for name, text := range texts {
+		_, err := template.New(name).Funcs(templateFuncs(nil)).Parse(text)
+		if err != nil {
+			return fmt.Errorf("Prompt %s is not a valid template: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// templateFuncs are the functions available to the templates:
+//
+//	{{ file "notes.md" }}     content of a file, ~ is the home directory
+//	{{ env "USER" }}          value of an environment variable
+//	{{ date }}                current date, or {{ date "15:04" }}
+//	{{ shell "git status" }}  output of a command
+//
+// The file, env and shell calls run after confirmation only.
+func templateFuncs(confirm func(function, argument string) bool) template.FuncMap {
+	allowed := func(function, argument string) error {
+		if confirm == nil || !confirm(function, argument) {
+			return fmt.Errorf("%s %q was not allowed", function, argument)
+		}
+		return nil
+	}
+
+	return template.FuncMap{
+		"file": func(path string) (string, error) {
+			if err := allowed("file", path); err != nil {
+				return "", err
+			}
+			return includeFile(path)
+		},
+		"env": func(name string) (string, error) {
+			if err := allowed("env", name); err != nil {
+				return "", err
+			}
+			return os.Getenv(name), nil
+		},
+		"date": func(layout ...string) string {
+			if len(layout) == 0 {
+				return time.Now().Format(time.DateOnly)
+			}
+			return time.Now().Format(layout[0])
+		},
+		"shell": func(command string) (string, error) {
+			if err := allowed("shell", command); err != nil {
+				return "", err
+			}
+			return runShell(command)
+		},
+	}
+}
+
+func includeFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxIncludeSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", path, maxIncludeSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func runShell(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return "", fmt.Errorf("command %q failed: %w", command, err)
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
@@ -0,0 +1,153 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(file, []byte("remember the milk"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	variables := []Variable{
+		{Name: "language", Default: "English"},
+		{Name: "topic", Required: true},
+	}
+	answer := func(v Variable) (string, error) { return "asked " + v.Name, nil }
+
+	tests := []struct {
+		name     string
+		system   string
+		opts     RenderOptions
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Given and default",
+			system:   "Write in {{ .language }} about {{ .topic }}.",
+			opts:     RenderOptions{Vars: map[string]string{"topic": "Go"}},
+			expected: "Write in English about Go.",
+		},
+		{
+			name:     "Asked",
+			system:   "{{ .topic }}",
+			opts:     RenderOptions{Ask: answer},
+			expected: "asked topic",
+		},
+		{
+			name:    "Missing",
+			system:  "{{ .topic }}",
+			wantErr: true,
+		},
+		{
+			name:    "Undeclared",
+			system:  "{{ .unknown }}",
+			opts:    RenderOptions{Vars: map[string]string{"topic": "Go"}},
+			wantErr: true,
+		},
+		{
+			name:    "File refused",
+			system:  `Notes: {{ file "` + filepath.ToSlash(file) + `" }}`,
+			opts:    RenderOptions{Vars: map[string]string{"topic": "Go"}},
+			wantErr: true,
+		},
+		{
+			name:   "File allowed",
+			system: `Notes: {{ file "` + filepath.ToSlash(file) + `" }}`,
+			opts: RenderOptions{
+				Vars:    map[string]string{"topic": "Go"},
+				Confirm: func(string, string) bool { return true },
+			},
+			expected: "Notes: remember the milk",
+		},
+		{
+			name:    "Env refused",
+			system:  `{{ env "HOME" }}`,
+			opts:    RenderOptions{Vars: map[string]string{"topic": "Go"}},
+			wantErr: true,
+		},
+		{
+			name:     "Date",
+			system:   `{{ date "2006" }}`,
+			opts:     RenderOptions{Vars: map[string]string{"topic": "Go"}},
+			expected: time.Now().Format("2006"),
+		},
+		{
+			name:    "Shell refused",
+			system:  `{{ shell "echo hi" }}`,
+			opts:    RenderOptions{Vars: map[string]string{"topic": "Go"}},
+			wantErr: true,
+		},
+		{
+			name:   "Shell allowed",
+			system: `{{ shell "echo hi" }}`,
+			opts: RenderOptions{
+				Vars:    map[string]string{"topic": "Go"},
+				Confirm: func(string, string) bool { return true },
+			},
+			expected: "hi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.name == "Shell allowed" && runtime.GOOS == "windows" {
+				t.Skip("sh is not available")
+			}
+
+			p := &Prompt{Settings: Settings{SystemPrompt: tt.system, Variables: variables}}
+			rendered, err := p.Render(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && rendered.Settings.SystemPrompt != tt.expected {
+				t.Errorf("Render() = %q, want %q", rendered.Settings.SystemPrompt, tt.expected)
+			}
+		})
+	}
+
+	failing := func(Variable) (string, error) { return "", errors.New("closed") }
+	p := &Prompt{Settings: Settings{SystemPrompt: "{{ .topic }}", Variables: variables}}
+	if _, err := p.Render(RenderOptions{Ask: failing}); err == nil {
+		t.Error("Render() with a failing Ask succeeded")
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		system    string
+		variables []Variable
+		wantErr   bool
+	}{
+		{name: "Plain", system: "Be concise."},
+		{name: "Template", system: "{{ .x }} {{ env \"HOME\" }}", variables: []Variable{{Name: "x"}}},
+		{name: "Syntax", system: "{{ .x", wantErr: true},
+		{name: "Unknown function", system: "{{ nope }}", wantErr: true},
+		{name: "Invalid name", system: "x", variables: []Variable{{Name: "my-var"}}, wantErr: true},
+		{name: "Duplicate", system: "x", variables: []Variable{{Name: "a"}, {Name: "a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Prompt{Settings: Settings{SystemPrompt: tt.system, Variables: tt.variables}}
+			if err := p.validateTemplates(); (err != nil) != tt.wantErr {
+				t.Errorf("validateTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Assertion types of the test cases.
+const (
+	// AssertContains checks the answer contains the value
+	AssertContains = "contains"
+	// AssertRegex checks the answer matches the regular expression
+	AssertRegex = "regex"
+	// AssertJSONSchema checks the answer is JSON valid against the schema
+	AssertJSONSchema = "json_schema"
+	// AssertRubric asks a model to grade the answer against the rubric
+	AssertRubric = "rubric"
+)
+
+// TestCase is an input of the prompt and the assertions its answer must
+// satisfy, run with nomi prompt test.
+type TestCase struct {
+	Name  string `yaml:"name"`
+	Input string `yaml:"input"`
+	// Vars are the values of the variables of a templated prompt
+	Vars   map[string]string `yaml:"vars,omitempty"`
+	Assert []Assertion       `yaml:"assert"`
+}
+
+type Assertion struct {
+	Type  string `yaml:"type"`
+	Value string `yaml:"value,omitempty"`
+	// Schema of the json_schema assertions
+	Schema map[string]interface{} `yaml:"schema,omitempty"`
+	// Not negates the contains and regex assertions
+	Not bool `yaml:"not,omitempty"`
+}
+
+func validateTests(tests []TestCase) error {
+	names := make(map[string]bool, len(tests))
+	for i, tc := range tests {
+		if tc.Name == "" {
+			return fmt.Errorf("Prompt test %d has no name", i+1)
+		}
+		if names[tc.Name] {
+			return fmt.Errorf("Prompt test %s is duplicated", tc.Name)
+		}
+		names[tc.Name] = true
+
+		if tc.Input == "" {
+			return fmt.Errorf("Prompt test %s has no input", tc.Name)
+		}
+		if len(tc.Assert) == 0 {
+			return fmt.Errorf("Prompt test %s has no assertion", tc.Name)
+		}
+
+		for j, a := range tc.Assert {
+			if err := a.validate(); err != nil {
+				return fmt.Errorf(
+					"Prompt test %s, assertion %d: %w",
+					tc.Name,
+					j+1,
+					err,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a Assertion) validate() error {
+	switch a.Type {
+	case AssertContains, AssertRubric:
+		if a.Value == "" {
+			return fmt.Errorf("%s requires a value", a.Type)
+		}
+	case AssertRegex:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	case AssertJSONSchema:
+		if len(a.Schema) == 0 {
+			return errors.New("json_schema requires a schema")
+		}
+	case "":
+		return errors.New("type is required")
+	default:
+		return fmt.Errorf(
+			"unknown type %q, expected contains, regex, json_schema or rubric",
+			a.Type,
+		)
+	}
+
+	if a.Not && a.Type != AssertContains && a.Type != AssertRegex {
+		return errors.New("not is only supported by contains and regex")
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ type TextToJSONProvider interface {
 	GenerateCompletion(
 		ctx context.Context,
 		messages []chat.Message,
+		opts completion.RequestOptions,
 		completionCh chan<- completion.Completion,
 	) error
 
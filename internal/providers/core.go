@@ -1,6 +1,12 @@
 package providers
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	prompts "github.com/nullswan/nomi/internal/prompt"
+)
 
 type AIProvider string
 
@@ -30,3 +36,46 @@ func CheckProvider() AIProvider {
 
 	return OllamaProvider
 }
+
+// ParseProvider returns the provider of the name.
+func ParseProvider(name string) (AIProvider, error) {
+	switch p := AIProvider(strings.ToLower(name)); p {
+	case OpenAIProvider, AnthropicProvider, OpenRouterProvider, OllamaProvider:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown provider: %s", name)
+	}
+}
+
+// IsAvailable reports whether the provider can be used, its API key is set.
+// Ollama is always available, it is installed on demand.
+func IsAvailable(p AIProvider) bool {
+	switch p {
+	case OpenAIProvider:
+		return os.Getenv("OPENAI_API_KEY") != ""
+	case OpenRouterProvider:
+		return os.Getenv("OPENROUTER_API_KEY") != ""
+	case OllamaProvider:
+		return true
+	default:
+		// Anthropic is not implemented yet
+		return false
+	}
+}
+
+// SelectModel returns the first preferred model whose provider is
+// available, the default model of CheckProvider otherwise.
+func SelectModel(models []prompts.ModelPreference) (AIProvider, string) {
+	for _, m := range models {
+		if m.Provider == "" {
+			return CheckProvider(), m.Model
+		}
+
+		p, err := ParseProvider(m.Provider)
+		if err == nil && IsAvailable(p) {
+			return p, m.Model
+		}
+	}
+
+	return CheckProvider(), ""
+}
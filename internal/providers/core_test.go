@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"testing"
+
+	prompts "github.com/nullswan/nomi/internal/prompt"
+)
+
+func TestSelectModel(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("OPENROUTER_API_KEY", "key")
+
+	tests := []struct {
+		name     string
+		models   []prompts.ModelPreference
+		provider AIProvider
+		model    string
+	}{
+		{
+			name:     "No preference",
+			provider: OpenRouterProvider,
+		},
+		{
+			name: "First available",
+			models: []prompts.ModelPreference{
+				{Provider: "openai", Model: "gpt-4o"},
+				{Provider: "anthropic", Model: "claude"},
+				{Provider: "openrouter", Model: "openai/gpt-4o"},
+				{Provider: "ollama", Model: "llama3.1"},
+			},
+			provider: OpenRouterProvider,
+			model:    "openai/gpt-4o",
+		},
+		{
+			name: "Any provider",
+			models: []prompts.ModelPreference{
+				{Provider: "unknown", Model: "x"},
+				{Model: "openai/gpt-4o-mini"},
+			},
+			provider: OpenRouterProvider,
+			model:    "openai/gpt-4o-mini",
+		},
+		{
+			name: "Fallback",
+			models: []prompts.ModelPreference{
+				{Provider: "openai", Model: "gpt-4o"},
+			},
+			provider: OpenRouterProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, model := SelectModel(tt.models)
+			if provider != tt.provider || model != tt.model {
+				t.Errorf(
+					"SelectModel() = %s, %q, want %s, %q",
+					provider,
+					model,
+					tt.provider,
+					tt.model,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package ollamaprovider
+
+import (
+	"github.com/nullswan/nomi/internal/completion"
+	"github.com/ollama/ollama/api"
+)
+
+// applyRequestOptions sets the options on the request, as model options.
+func applyRequestOptions(req *api.ChatRequest, opts completion.RequestOptions) {
+	options := make(map[string]interface{})
+	if opts.Temperature != nil {
+		options["temperature"] = *opts.Temperature
+	}
+	if opts.TopP != nil {
+		options["top_p"] = *opts.TopP
+	}
+	if opts.MaxTokens > 0 {
+		options["num_predict"] = opts.MaxTokens
+	}
+	if len(opts.Stop) > 0 {
+		options["stop"] = opts.Stop
+	}
+	if opts.Seed != nil {
+		options["seed"] = *opts.Seed
+	}
+	if len(options) > 0 {
+		req.Options = options
+	}
+
+	if opts.JSON {
+		req.Format = "json"
+	}
+}
@@ -115,9 +115,10 @@ func (p TextToJSONProvider) GetModel() string {
 func (p TextToJSONProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToJSON(p.config.model, messages)
+	req := completionRequestTextToJSON(p.config.model, messages, opts)
 
 	aggCompletion := ""
 	resp := func(resp api.ChatResponse) error {
@@ -149,6 +150,7 @@ func (p TextToJSONProvider) GenerateCompletion(
 func completionRequestTextToJSON(
 	model string,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 ) api.ChatRequest {
 	stream := true
 
@@ -166,5 +168,7 @@ func completionRequestTextToJSON(
 		}
 	}
 
+	applyRequestOptions(&req, opts)
+
 	return req
 }
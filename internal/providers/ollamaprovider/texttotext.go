@@ -115,9 +115,10 @@ func (p TextToTextProvider) GetModel() string {
 func (p TextToTextProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToText(p.config.model, messages)
+	req := completionRequestTextToText(p.config.model, messages, opts)
 
 	aggCompletion := ""
 	resp := func(resp api.ChatResponse) error {
@@ -149,6 +150,7 @@ func (p TextToTextProvider) GenerateCompletion(
 func completionRequestTextToText(
 	model string,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 ) api.ChatRequest {
 	stream := true
 
@@ -165,6 +167,8 @@ func completionRequestTextToText(
 		}
 	}
 
+	applyRequestOptions(&req, opts)
+
 	return req
 }
 
@@ -63,9 +63,10 @@ func (p *TextToTextReasoningProvider) GetModel() string {
 func (p *TextToTextReasoningProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToTextReasoning(p.config.model, messages)
+	req := completionRequestTextToTextReasoning(p.config.model, messages, opts)
 
 	// Streaming is not supported YET (cf: https://platform.openai.com/docs/guides/reasoning/beta-limitations)
 	resp, err := p.client.CreateChatCompletion(ctx, req)
@@ -89,6 +90,7 @@ func (p *TextToTextReasoningProvider) GenerateCompletion(
 func completionRequestTextToTextReasoning(
 	model string,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 ) openai.ChatCompletionRequest {
 	req := openai.ChatCompletionRequest{
 		Model:    model,
@@ -108,5 +110,9 @@ func completionRequestTextToTextReasoning(
 		})
 	}
 
+	// Sampling parameters are not supported YET (cf: https://platform.openai.com/docs/guides/reasoning/beta-limitations)
+	req.MaxCompletionTokens = opts.MaxTokens
+	req.Seed = opts.Seed
+
 	return req
 }
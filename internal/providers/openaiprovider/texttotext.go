@@ -65,9 +65,10 @@ func (p TextToTextProvider) GetModel() string {
 func (p TextToTextProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToText(p.config.model, messages)
+	req := completionRequestTextToText(p.config.model, messages, opts)
 	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
 		return fmt.Errorf("error creating completion stream: %w", err)
@@ -101,6 +102,7 @@ func (p TextToTextProvider) GenerateCompletion(
 func completionRequestTextToText(
 	model string,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 ) openai.ChatCompletionRequest {
 	req := openai.ChatCompletionRequest{
 		Model:    model,
@@ -114,5 +116,7 @@ func completionRequestTextToText(
 		}
 	}
 
+	applyRequestOptions(&req, opts)
+
 	return req
 }
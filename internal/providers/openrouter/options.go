@@ -0,0 +1,37 @@
+package openrouterprovider
+
+import (
+	"math"
+
+	"github.com/nullswan/nomi/internal/completion"
+	"github.com/sashabaranov/go-openai"
+)
+
+// applyRequestOptions sets the options on the request. The client omits
+// zero values, a zero temperature or top_p is sent as the smallest float.
+func applyRequestOptions(
+	req *openai.ChatCompletionRequest,
+	opts completion.RequestOptions,
+) {
+	if opts.Temperature != nil {
+		req.Temperature = nonZero(*opts.Temperature)
+	}
+	if opts.TopP != nil {
+		req.TopP = nonZero(*opts.TopP)
+	}
+	req.MaxTokens = opts.MaxTokens
+	req.Stop = opts.Stop
+	req.Seed = opts.Seed
+	if opts.JSON {
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+}
+
+func nonZero(f float32) float32 {
+	if f == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return f
+}
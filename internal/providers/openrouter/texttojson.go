@@ -70,9 +70,10 @@ func (p TextToJSONProvider) GetModel() string {
 func (p TextToJSONProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToJSON(p.config.model, messages)
+	req := completionRequestTextToJSON(p.config.model, messages, opts)
 	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
 		return fmt.Errorf("error creating completion stream: %w", err)
@@ -106,6 +107,7 @@ func (p TextToJSONProvider) GenerateCompletion(
 func completionRequestTextToJSON(
 	model string,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 ) openai.ChatCompletionRequest {
 	req := openai.ChatCompletionRequest{
 		Model:    model,
@@ -122,5 +124,7 @@ func completionRequestTextToJSON(
 		}
 	}
 
+	applyRequestOptions(&req, opts)
+
 	return req
 }
@@ -70,9 +70,10 @@ func (p TextToTextProvider) GetModel() string {
 func (p TextToTextProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToText(p.config.model, messages)
+	req := completionRequestTextToText(p.config.model, messages, opts)
 	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
 		return fmt.Errorf("error creating completion stream: %w", err)
@@ -106,6 +107,7 @@ func (p TextToTextProvider) GenerateCompletion(
 func completionRequestTextToText(
 	model string,
 	messages []chat.Message,
+	opts completion.RequestOptions,
 ) openai.ChatCompletionRequest {
 	req := openai.ChatCompletionRequest{
 		Model:    model,
@@ -119,5 +121,7 @@ func completionRequestTextToText(
 		}
 	}
 
+	applyRequestOptions(&req, opts)
+
 	return req
 }
@@ -0,0 +1,222 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/nullswan/nomi/internal/proc"
+)
+
+type Backend string
+
+const (
+	// BackendAuto picks the strongest backend available on the platform,
+	// falling back to BackendNone.
+	BackendAuto Backend = "auto"
+	// BackendBubblewrap relies on the bwrap binary.
+	BackendBubblewrap Backend = "bwrap"
+	// BackendNamespaces unshares user, mount and network namespaces.
+	BackendNamespaces Backend = "namespaces"
+	// BackendNone only applies the working directory and resource limits.
+	BackendNone Backend = "none"
+)
+
+var ErrUnsupported = errors.New("sandbox backend not supported")
+
+// Options configures the isolation of the sandboxed processes.
+type Options struct {
+	Backend Backend
+	// Network keeps the host network reachable.
+	Network bool
+	// CPUTime limits the CPU time of the process, 0 means unlimited.
+	CPUTime time.Duration
+	// Memory limits the address space in bytes, 0 means unlimited.
+	Memory int64
+	// Output bounds the bytes of stdout and stderr kept, the head and the
+	// tail of longer outputs are kept. 0 keeps proc.DefaultOutputLimit.
+	Output int64
+}
+
+// Sandbox runs commands in a throwaway working directory with the
+// configured isolation.
+type Sandbox struct {
+	opts    Options
+	backend Backend
+}
+
+// Result of a sandboxed command.
+type Result struct {
+	Stdout    string
+	Stderr    string
+	ExitCode  int
+	Truncated bool
+}
+
+// New resolves the backend of the options and checks its availability.
+func New(opts Options) (*Sandbox, error) {
+	backend := opts.Backend
+	switch backend {
+	case "", BackendAuto:
+		backend = BackendNone
+		for _, b := range []Backend{BackendBubblewrap, BackendNamespaces} {
+			if Available(b) {
+				backend = b
+				break
+			}
+		}
+	case BackendBubblewrap, BackendNamespaces:
+		if !Available(backend) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupported, backend)
+		}
+	case BackendNone:
+	default:
+		return nil, fmt.Errorf("unknown sandbox backend: %s", backend)
+	}
+
+	return &Sandbox{opts: opts, backend: backend}, nil
+}
+
+// Backend returns the resolved backend.
+func (s *Sandbox) Backend() Backend {
+	return s.backend
+}
+
+// Run executes the command inside the sandbox and waits for it. The output
+// is also written to stdout and stderr as it is produced, when not nil.
+func (s *Sandbox) Run(
+	ctx context.Context,
+	stdout, stderr io.Writer,
+	name string,
+	args ...string,
+) (Result, error) {
+	workdir, err := os.MkdirTemp("", "nomi-sandbox-")
+	if err != nil {
+		return Result{}, fmt.Errorf("error creating working directory: %w", err)
+	}
+	defer os.RemoveAll(workdir)
+
+	cmd, err := s.command(ctx, workdir, name, args)
+	if err != nil {
+		return Result{}, err
+	}
+
+	outBuf := proc.NewOutputBuffer(int(s.opts.Output))
+	errBuf := proc.NewOutputBuffer(int(s.opts.Output))
+	cmd.Stdout = tee(outBuf, stdout)
+	cmd.Stderr = tee(errBuf, stderr)
+	cmd.Stdin = nil
+	proc.SetProcessGroup(cmd)
+	// Do not wait for orphaned children holding the pipes after a kill
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	result := Result{
+		Stdout:    outBuf.String(),
+		Stderr:    errBuf.String(),
+		Truncated: outBuf.Truncated() || errBuf.Truncated(),
+	}
+	if err != nil {
+		var exitError *exec.ExitError
+		if !errors.As(err, &exitError) {
+			return result, fmt.Errorf("failed to run command: %w", err)
+		}
+		result.ExitCode = exitError.ExitCode()
+		// Killed by a signal, e.g. SIGXCPU
+		if result.ExitCode == -1 {
+			result.ExitCode = 1
+		}
+	}
+
+	return result, nil
+}
+
+// Command returns a long-lived command running inside the sandbox, whose
+// pipes are handled by the caller. The process group is killed when the
+// context is done, cleanup removes the working directory once it exited.
+// The caller bounds the output it keeps.
+func (s *Sandbox) Command(
+	ctx context.Context,
+	name string,
+	args ...string,
+) (*exec.Cmd, func(), error) {
+	workdir, err := os.MkdirTemp("", "nomi-sandbox-")
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"error creating working directory: %w",
+			err,
+		)
+	}
+	cleanup := func() { os.RemoveAll(workdir) }
+
+	cmd, err := s.command(ctx, workdir, name, args)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	proc.SetProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+
+	return cmd, cleanup, nil
+}
+
+// limitsScript applies the resource limits before executing "$@".
+func (s *Sandbox) limitsScript() string {
+	script := ""
+	if s.opts.CPUTime > 0 {
+		seconds := int64(s.opts.CPUTime.Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		script += "ulimit -t " + strconv.FormatInt(seconds, 10) + " || exit 1\n"
+	}
+	if s.opts.Memory > 0 {
+		script += "ulimit -v " + strconv.FormatInt(s.opts.Memory/1024, 10) +
+			" || exit 1\n"
+	}
+	return script + `exec "$@"`
+}
+
+// direct runs the command in the working directory with the limits only.
+func (s *Sandbox) direct(
+	ctx context.Context,
+	workdir, name string,
+	args []string,
+) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, name, args...)
+	} else {
+		cmd = exec.CommandContext(
+			ctx,
+			"/bin/sh",
+			append([]string{"-c", s.limitsScript(), "sh", name}, args...)...,
+		)
+	}
+	cmd.Dir = workdir
+	return cmd
+}
+
+// tee copies the output to w, when set. Streaming is best effort, the
+// output is kept in the buffer.
+func tee(buf *proc.OutputBuffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, writerFunc(func(p []byte) (int, error) {
+		_, _ = w.Write(p)
+		return len(p), nil
+	}))
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
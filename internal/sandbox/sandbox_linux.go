@@ -0,0 +1,215 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// namespacesScript runs as root of a new user namespace. It hides the home
+// directory and /tmp, remounts every filesystem read-only but the working
+// directory and /tmp, then drops the mount privileges by entering a nested
+// user namespace, in which the mounts above are locked. The working
+// directory, usually under /tmp, is bound back from the current directory
+// once /tmp is hidden.
+const namespacesScript = `set -e
+W="$1"; H="$2"; L="$3"; shift 3
+mount --make-rprivate /
+cd "$W"
+if [ -d /tmp ]; then
+	mount -t tmpfs -o mode=1777 nomi /tmp
+	mkdir -p "$W"
+fi
+mount --no-canonicalize --bind /proc/self/cwd "$W"
+if [ -n "$H" ] && [ -d "$H" ]; then
+	mount -t tmpfs -o size=1m,mode=700 nomi "$H"
+fi
+mount -o remount,bind,ro /
+while read -r _ target _; do
+	case "$target" in
+	/ | "$W" | /tmp | /proc | /proc/* | /sys | /sys/* | /dev | /dev/pts) continue ;;
+	esac
+	mount -o remount,bind,ro "$target" 2>/dev/null || true
+done < /proc/self/mounts
+cd "$W"
+export HOME="$W" TMPDIR="$W"
+exec unshare --user --map-root-user --mount -- /bin/sh -c "$L" sh "$@"
+`
+
+const probeTimeout = 5 * time.Second
+
+var (
+	availableMu sync.Mutex
+	available   = make(map[Backend]bool)
+)
+
+// Available reports whether the backend can run on this host. The result
+// is probed once by running a no-op command.
+func Available(backend Backend) bool {
+	switch backend {
+	case BackendNone:
+		return true
+	case BackendBubblewrap:
+		if _, err := exec.LookPath("bwrap"); err != nil {
+			return false
+		}
+	case BackendNamespaces:
+		for _, bin := range []string{"unshare", "mount"} {
+			if _, err := exec.LookPath(bin); err != nil {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+
+	availableMu.Lock()
+	defer availableMu.Unlock()
+
+	if ok, probed := available[backend]; probed {
+		return ok
+	}
+
+	s := &Sandbox{backend: backend}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	res, err := s.Run(ctx, nil, nil, "true")
+	ok := err == nil && res.ExitCode == 0
+	available[backend] = ok
+
+	return ok
+}
+
+func (s *Sandbox) command(
+	ctx context.Context,
+	workdir, name string,
+	args []string,
+) (*exec.Cmd, error) {
+	switch s.backend {
+	case BackendBubblewrap:
+		return s.bubblewrap(ctx, workdir, name, args), nil
+	case BackendNamespaces:
+		return s.namespaces(ctx, workdir, name, args), nil
+	case BackendNone:
+		return s.direct(ctx, workdir, name, args), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, s.backend)
+	}
+}
+
+func (s *Sandbox) bubblewrap(
+	ctx context.Context,
+	workdir, name string,
+	args []string,
+) *exec.Cmd {
+	bwrapArgs := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+	if home := maskedHome(workdir); home != "" {
+		bwrapArgs = append(bwrapArgs, "--tmpfs", home)
+	}
+	bwrapArgs = append(bwrapArgs,
+		"--bind", workdir, workdir,
+		"--chdir", workdir,
+		"--setenv", "HOME", workdir,
+		"--setenv", "TMPDIR", workdir,
+		"--unshare-all",
+		"--die-with-parent",
+		"--new-session",
+	)
+	if s.opts.Network {
+		bwrapArgs = append(bwrapArgs, "--share-net")
+	}
+	bwrapArgs = append(bwrapArgs,
+		"--", "/bin/sh", "-c", s.limitsScript(), "sh", name,
+	)
+
+	cmd := exec.CommandContext(ctx, "bwrap", append(bwrapArgs, args...)...)
+	cmd.Dir = workdir
+	cmd.Env = environment(workdir)
+	return cmd
+}
+
+func (s *Sandbox) namespaces(
+	ctx context.Context,
+	workdir, name string,
+	args []string,
+) *exec.Cmd {
+	cmd := exec.CommandContext(
+		ctx,
+		"/bin/sh",
+		append(
+			[]string{
+				"-c", namespacesScript, "sh",
+				workdir, maskedHome(workdir), s.limitsScript(), name,
+			},
+			args...,
+		)...,
+	)
+	cmd.Dir = workdir
+	cmd.Env = environment(workdir)
+
+	flags := syscall.CLONE_NEWUSER |
+		syscall.CLONE_NEWNS |
+		syscall.CLONE_NEWIPC |
+		syscall.CLONE_NEWUTS
+	if !s.opts.Network {
+		flags |= syscall.CLONE_NEWNET
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: uintptr(flags),
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+		},
+		GidMappingsEnableSetgroups: false,
+	}
+
+	return cmd
+}
+
+// sharedEnvironment are the variables of the host passed to the isolated
+// processes, the others, e.g. the API keys, are not exposed.
+var sharedEnvironment = []string{"PATH", "LANG", "LC_ALL", "LC_CTYPE", "TERM", "TZ"}
+
+// environment returns the variables of an isolated process, its home and
+// temporary directory being the working directory.
+func environment(workdir string) []string {
+	env := []string{"HOME=" + workdir, "TMPDIR=" + workdir}
+	for _, name := range sharedEnvironment {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// maskedHome returns the home directory to hide, unless it contains the
+// working directory.
+func maskedHome(workdir string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "/" {
+		return ""
+	}
+
+	if strings.HasPrefix(workdir, home+string(filepath.Separator)) {
+		return ""
+	}
+
+	return home
+}
@@ -0,0 +1,230 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func isolatedBackends(t *testing.T) []Backend {
+	t.Helper()
+
+	var backends []Backend
+	for _, b := range []Backend{BackendBubblewrap, BackendNamespaces} {
+		if Available(b) {
+			backends = append(backends, b)
+		}
+	}
+
+	if len(backends) == 0 {
+		t.Skip("no sandbox backend available on this host")
+	}
+
+	return backends
+}
+
+func TestSandboxFilesystemIsolation(t *testing.T) {
+	t.Parallel()
+
+	outside := t.TempDir()
+	target := filepath.Join(outside, "escaped")
+
+	for _, backend := range isolatedBackends(t) {
+		t.Run(string(backend), func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(Options{Backend: backend})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			// Writing outside of the working directory must fail
+			res, err := s.Run(
+				context.Background(),
+				nil, nil,
+				"sh", "-c", "echo pwned > "+target+"-"+string(backend),
+			)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if res.ExitCode == 0 {
+				t.Errorf("write outside of the sandbox succeeded")
+			}
+			if _, err := os.Stat(target + "-" + string(backend)); err == nil {
+				t.Errorf("file written outside of the sandbox")
+			}
+
+			// The working directory is writable and thrown away
+			res, err = s.Run(
+				context.Background(),
+				nil, nil,
+				"sh", "-c", "echo ok > file && cat file && pwd",
+			)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if res.ExitCode != 0 || !strings.HasPrefix(res.Stdout, "ok\n") {
+				t.Fatalf("write in the working directory failed: %+v", res)
+			}
+			workdir := strings.TrimSpace(strings.TrimPrefix(res.Stdout, "ok\n"))
+			if _, err := os.Stat(workdir); !os.IsNotExist(err) {
+				t.Errorf("working directory %s was not removed", workdir)
+			}
+
+			// The temporary directory of the host is hidden
+			marker, err := os.CreateTemp("", "nomi-visible-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			marker.Close()
+			t.Cleanup(func() { os.Remove(marker.Name()) })
+
+			res, err = s.Run(context.Background(), nil, nil, "ls", "-A", os.TempDir())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if strings.Contains(res.Stdout, filepath.Base(marker.Name())) {
+				t.Errorf("host %s is visible: %q", os.TempDir(), res.Stdout)
+			}
+
+			// The home directory is hidden
+			home, err := os.UserHomeDir()
+			if err != nil {
+				t.Skip("no home directory")
+			}
+			res, err = s.Run(context.Background(), nil, nil, "ls", "-A", home)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if strings.TrimSpace(res.Stdout) != "" {
+				t.Errorf("home directory is visible: %q", res.Stdout)
+			}
+		})
+	}
+}
+
+// Not parallel, the secret is set in the environment of the test
+func TestSandboxEnvironmentIsolation(t *testing.T) {
+	backends := isolatedBackends(t)
+	t.Setenv("NOMI_TEST_SECRET", "secret")
+
+	for _, backend := range backends {
+		t.Run(string(backend), func(t *testing.T) {
+			s, err := New(Options{Backend: backend})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			res, err := s.Run(
+				context.Background(),
+				nil, nil,
+				"sh", "-c", `echo "${NOMI_TEST_SECRET-unset}" && test "$HOME" = "$PWD"`,
+			)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if res.ExitCode != 0 || strings.TrimSpace(res.Stdout) != "unset" {
+				t.Errorf("environment of the host is visible: %+v", res)
+			}
+		})
+	}
+}
+
+func TestSandboxNetworkIsolation(t *testing.T) {
+	t.Parallel()
+
+	backends := isolatedBackends(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	// Connect through bash, available wherever the interpreter runs
+	connect := "exec 3<>/dev/tcp/127.0.0.1/" + port
+
+	for _, backend := range backends {
+		t.Run(string(backend), func(t *testing.T) {
+			t.Parallel()
+
+			for _, network := range []bool{false, true} {
+				s, err := New(Options{Backend: backend, Network: network})
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+
+				res, err := s.Run(context.Background(), nil, nil, "bash", "-c", connect)
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+
+				if connected := res.ExitCode == 0; connected != network {
+					t.Errorf(
+						"network=%v: connected=%v, stderr=%q",
+						network,
+						connected,
+						res.Stderr,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestSandboxLimits(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(Options{
+		Backend: BackendNone,
+		CPUTime: time.Second,
+		Output:  1024,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := s.Run(ctx, nil, nil, "sh", "-c", "while :; do :; done")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.ExitCode == 0 || ctx.Err() != nil {
+		t.Errorf("CPU time limit not enforced: %+v", res)
+	}
+
+	// The head and the tail of the output are kept
+	res, err = s.Run(ctx, nil, nil, "sh", "-c", "yes | head -c 100000; echo end")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !res.Truncated || len(res.Stdout) > 1100 ||
+		!strings.HasPrefix(res.Stdout, "y\n") ||
+		!strings.HasSuffix(res.Stdout, "\nend\n") {
+		t.Errorf(
+			"output limit not enforced: truncated=%v, len=%d",
+			res.Truncated,
+			len(res.Stdout),
+		)
+	}
+}
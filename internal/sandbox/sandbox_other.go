@@ -0,0 +1,27 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// Available reports whether the backend can run on this host. Only the
+// limits of BackendNone are supported outside of Linux.
+func Available(backend Backend) bool {
+	return backend == BackendNone
+}
+
+func (s *Sandbox) command(
+	ctx context.Context,
+	workdir, name string,
+	args []string,
+) (*exec.Cmd, error) {
+	if s.backend != BackendNone {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, s.backend)
+	}
+
+	return s.direct(ctx, workdir, name, args), nil
+}
@@ -0,0 +1,277 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed standard five fields cron expression:
+// minute hour day-of-month month day-of-week.
+type Cron struct {
+	expr string
+
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar are set when the field was a wildcard, in which
+	// case the other day field alone decides the day.
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{
+		name: "month", min: 1, max: 12,
+		names: map[string]int{
+			"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+			"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+		},
+	},
+	{
+		name: "day of week", min: 0, max: 7,
+		names: map[string]int{
+			"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+		},
+	},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears bounds Next for expressions that never match, e.g. Feb 31.
+const maxSearchYears = 5
+
+// ParseCron parses a five fields cron expression or a descriptor such as
+// @daily.
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf(
+			"invalid cron expression %q: expected %d fields, got %d",
+			expr,
+			len(cronFields),
+			len(fields),
+		)
+	}
+
+	c := &Cron{expr: expr}
+	bits := []*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		*bits[i] = b
+	}
+
+	// Sunday can be written as 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	c.domStar = strings.HasPrefix(fields[2], "*")
+	c.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return c, nil
+}
+
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("empty %s value", f.name)
+		}
+
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rng = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, part[i+1:])
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			hi = lo
+			// "5/15" means from 5 to the end of the range
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		if lo > hi {
+			return 0, fmt.Errorf("invalid %s range %q", f.name, rng)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", f.name, s)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf(
+			"%s value %d out of range [%d-%d]",
+			f.name,
+			v,
+			f.min,
+			f.max,
+		)
+	}
+
+	return v, nil
+}
+
+// String returns the original expression.
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next returns the first activation strictly after t, in t's location.
+// It returns the zero time if the expression never matches.
+//
+// The expression matches the wall clock: a time skipped by a daylight
+// saving change runs once the clock moved forward, and a repeated time
+// runs once.
+func (c *Cron) Next(t time.Time) time.Time {
+	// Search the wall clock in UTC, where no hour is skipped or repeated
+	wall := wallClock(t)
+	limit := wall.AddDate(maxSearchYears, 0, 0)
+
+	for {
+		wall = c.nextWall(wall.Add(time.Minute), limit)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+
+		// Several wall clock times may resolve to the same instant around
+		// a daylight saving change
+		if next := resolveWall(wall, t.Location()); next.After(t) {
+			return next
+		}
+	}
+}
+
+// resolveWall returns the instant of the wall clock time in loc. A time
+// skipped by a daylight saving change resolves to the end of the gap.
+func resolveWall(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(
+		wall.Year(), wall.Month(), wall.Day(),
+		wall.Hour(), wall.Minute(), 0, 0,
+		loc,
+	)
+
+	resolved := wallClock(t)
+	if resolved.Equal(wall) {
+		return t
+	}
+
+	// The time lands on either side of the gap, move it after the gap
+	// and go back to the change
+	if resolved.Before(wall) {
+		t = t.Add(wall.Sub(resolved))
+	}
+	start, _ := t.ZoneBounds()
+	return start
+}
+
+// wallClock returns the wall clock time of t, in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC,
+	)
+}
+
+// nextWall returns the first wall clock time from t, in UTC, matching the
+// expression before limit.
+func (c *Cron) nextWall(t, limit time.Time) time.Time {
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	// When both day fields are restricted, either one may match
+	if !c.domStar && !c.dowStar {
+		return domMatch || dowMatch
+	}
+
+	return domMatch && dowMatch
+}
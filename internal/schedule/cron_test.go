@@ -0,0 +1,184 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday
+	from := time.Date(2024, 1, 10, 8, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected time.Time
+	}{
+		{
+			name:     "Every minute",
+			expr:     "* * * * *",
+			expected: time.Date(2024, 1, 10, 8, 31, 0, 0, time.UTC),
+		},
+		{
+			name:     "Daily at nine",
+			expr:     "0 9 * * *",
+			expected: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Daily at eight, tomorrow",
+			expr:     "0 8 * * *",
+			expected: time.Date(2024, 1, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Step",
+			expr:     "*/20 * * * *",
+			expected: time.Date(2024, 1, 10, 8, 40, 0, 0, time.UTC),
+		},
+		{
+			name:     "Weekdays by name",
+			expr:     "0 7 * * mon-fri",
+			expected: time.Date(2024, 1, 11, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Sunday as seven",
+			expr:     "0 0 * * 7",
+			expected: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Day of month or day of week",
+			expr:     "0 0 15 * 5",
+			expected: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Descriptor",
+			expr:     "@monthly",
+			expected: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Leap day",
+			expr:     "0 12 29 feb *",
+			expected: time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Never",
+			expr: "0 0 31 2 *",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+
+			if got := c.Next(from); !got.Equal(tt.expected) {
+				t.Errorf("Next() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCronNextDaylightSaving(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		location string
+		expr     string
+		from     string
+		expected []string
+	}{
+		{
+			name:     "Spring forward, skipped hour",
+			location: "America/New_York",
+			expr:     "0 2 * * *",
+			from:     "2026-03-07T12:00:00-05:00",
+			expected: []string{"2026-03-08T03:00:00-04:00", "2026-03-09T02:00:00-04:00"},
+		},
+		{
+			name:     "Spring forward, skipped half hour",
+			location: "America/New_York",
+			expr:     "30 2 * * *",
+			from:     "2026-03-07T12:00:00-05:00",
+			expected: []string{"2026-03-08T03:00:00-04:00", "2026-03-09T02:30:00-04:00"},
+		},
+		{
+			name:     "Spring forward at midnight",
+			location: "America/Santiago",
+			expr:     "0 0 * * *",
+			from:     "2026-09-04T12:00:00-04:00",
+			expected: []string{
+				"2026-09-05T00:00:00-04:00",
+				"2026-09-06T01:00:00-03:00",
+				"2026-09-07T00:00:00-03:00",
+			},
+		},
+		{
+			name:     "Spring forward at midnight, no day skipped",
+			location: "Asia/Beirut",
+			expr:     "0 0 * * *",
+			from:     "2026-03-28T12:00:00+02:00",
+			expected: []string{"2026-03-29T01:00:00+03:00", "2026-03-30T00:00:00+03:00"},
+		},
+		{
+			name:     "Fall back, repeated time runs once",
+			location: "America/New_York",
+			expr:     "30 1 * * *",
+			from:     "2026-10-31T12:00:00-04:00",
+			expected: []string{"2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loc, err := time.LoadLocation(tt.location)
+			if err != nil {
+				t.Skipf("time zone %s is not available: %v", tt.location, err)
+			}
+
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+
+			from, err := time.Parse(time.RFC3339, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			next := from.In(loc)
+			for _, expected := range tt.expected {
+				next = c.Next(next)
+				if got := next.Format(time.RFC3339); got != expected {
+					t.Fatalf("Next() = %s, want %s", got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/completion"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+	"github.com/nullswan/nomi/internal/tools"
+	"github.com/nullswan/nomi/internal/workflow"
+)
+
+// Daemon executes the due jobs of the repository, one at a time, and
+// stores their results in new conversations.
+type Daemon struct {
+	Repo       Repository
+	ChatRepo   chat.Repository
+	TextToText *tools.TextToTextBackend
+	// Engine runs workflow jobs, its interactive tools should be left nil.
+	Engine *workflow.Engine
+	Logger tools.Logger
+
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// Run blocks until the context is done, checking for due jobs every minute.
+func (d *Daemon) Run(ctx context.Context) error {
+	for {
+		if err := d.Tick(ctx); err != nil {
+			d.Logger.Error("Error running scheduled jobs: " + err.Error())
+		}
+
+		now := d.now()
+		wait := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Tick runs every due job once. Jobs without a next run are scheduled.
+func (d *Daemon) Tick(ctx context.Context) error {
+	jobs, err := d.Repo.LoadJobs()
+	if err != nil {
+		return fmt.Errorf("error loading jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if !job.Enabled {
+			continue
+		}
+
+		now := d.now()
+		if job.NextRunAt.IsZero() {
+			if err := job.Reschedule(now); err != nil {
+				d.Logger.Error("Error scheduling job " + job.ID + ": " + err.Error())
+				continue
+			}
+			if err := d.updateSchedule(job); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !job.Due(now) {
+			continue
+		}
+
+		d.Logger.Info("Running scheduled job " + job.label())
+		run := d.RunJob(ctx, job)
+		if run.Status == RunStatusFailure {
+			d.Logger.Error(
+				"Scheduled job " + job.label() + " failed: " + run.Error,
+			)
+		}
+
+		job.LastRunAt = run.StartedAt
+		// Missed activations are not replayed, the job resumes from now
+		if err := job.Reschedule(d.now()); err != nil {
+			d.Logger.Error("Error scheduling job " + job.ID + ": " + err.Error())
+			job.Enabled = false
+		}
+		if err := d.updateSchedule(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSchedule saves the next run of the job, unless it was removed
+// meanwhile.
+func (d *Daemon) updateSchedule(job *Job) error {
+	err := d.Repo.UpdateJobSchedule(job)
+	if errors.Is(err, ErrJobNotFound) {
+		d.Logger.Info("Scheduled job " + job.label() + " was removed")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error saving job: %w", err)
+	}
+	return nil
+}
+
+// RunJob executes the job immediately and records the run.
+func (d *Daemon) RunJob(ctx context.Context, job *Job) Run {
+	run := Run{
+		JobID:     job.ID,
+		StartedAt: d.now().UTC(),
+		Status:    RunStatusSuccess,
+	}
+
+	conversationID, err := d.execute(ctx, job)
+	run.ConversationID = conversationID
+	run.FinishedAt = d.now().UTC()
+	if err != nil {
+		run.Status = RunStatusFailure
+		run.Error = err.Error()
+	}
+
+	if err := d.Repo.SaveRun(&run); err != nil {
+		d.Logger.Error("Error saving run: " + err.Error())
+	}
+
+	return run
+}
+
+func (d *Daemon) execute(ctx context.Context, job *Job) (string, error) {
+	conversation := chat.NewStackedConversation(d.ChatRepo)
+
+	switch job.Kind {
+	case JobKindPrompt:
+		if d.TextToText == nil {
+			return "", errors.New("no text backend available")
+		}
+
+		var opts completion.RequestOptions
+		if job.Target != "" {
+			prompt, err := prompts.LoadPrompt(job.Target)
+			if err != nil {
+				return "", fmt.Errorf(
+					"error loading prompt %s: %w",
+					job.Target,
+					err,
+				)
+			}
+			// Nobody can answer, the variables come from the job and
+			// the file, env and shell calls of the prompt are refused
+			prompt, err = prompt.Render(prompts.RenderOptions{Vars: job.Vars})
+			if err != nil {
+				return "", fmt.Errorf(
+					"error rendering prompt %s: %w",
+					job.Target,
+					err,
+				)
+			}
+			conversation.WithPrompt(*prompt)
+			opts = prompt.Preferences.RequestOptions()
+		}
+
+		conversation.AddMessage(chat.NewMessage(chat.RoleUser, job.Input))
+
+		out, err := d.TextToText.WithOptions(opts).
+			DoMessages(ctx, conversation.GetMessages())
+		if err != nil {
+			return conversation.GetID(), fmt.Errorf(
+				"error generating completion: %w",
+				err,
+			)
+		}
+		conversation.AddMessage(chat.NewMessage(chat.RoleAssistant, out))
+	case JobKindWorkflow:
+		if d.Engine == nil {
+			return "", errors.New("no workflow engine available")
+		}
+
+		wf, err := workflow.LoadWorkflow(job.Target)
+		if err != nil {
+			return "", fmt.Errorf("error loading workflow: %w", err)
+		}
+
+		vars := make(map[string]interface{}, len(job.Vars)+1)
+		for k, v := range job.Vars {
+			vars[k] = v
+		}
+		if job.Input != "" {
+			vars["input"] = job.Input
+		}
+
+		conversation.AddMessage(chat.NewMessage(
+			chat.RoleUser,
+			"Scheduled workflow: "+wf.ID,
+		))
+
+		// Capture what the workflow prints as the conversation result
+		recorder := &recordingLogger{Logger: d.Engine.Logger}
+		engine := *d.Engine
+		engine.Logger = recorder
+
+		state, err := engine.Run(ctx, wf, vars)
+
+		out := strings.Join(recorder.lines, "\n")
+		if out == "" && state != nil && state[workflow.OutputVar] != nil {
+			out = fmt.Sprint(state[workflow.OutputVar])
+		}
+		if out != "" {
+			conversation.AddMessage(chat.NewMessage(chat.RoleAssistant, out))
+		}
+
+		if err != nil {
+			return conversation.GetID(), fmt.Errorf(
+				"error running workflow: %w",
+				err,
+			)
+		}
+	default:
+		return "", fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+
+	return conversation.GetID(), nil
+}
+
+func (d *Daemon) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+func (j *Job) label() string {
+	if j.Name != "" {
+		return j.Name + " (" + j.ID + ")"
+	}
+	return j.ID
+}
+
+type recordingLogger struct {
+	tools.Logger
+	lines []string
+}
+
+func (l *recordingLogger) Println(msg string) {
+	l.lines = append(l.lines, msg)
+	if l.Logger != nil {
+		l.Logger.Println(msg)
+	}
+}
+
+func (l *recordingLogger) Debug(msg string) {
+	if l.Logger != nil {
+		l.Logger.Debug(msg)
+	}
+}
+
+func (l *recordingLogger) Info(msg string) {
+	if l.Logger != nil {
+		l.Logger.Info(msg)
+	}
+}
+
+func (l *recordingLogger) Error(msg string) {
+	if l.Logger != nil {
+		l.Logger.Error(msg)
+	}
+}
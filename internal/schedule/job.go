@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrJobNotFound = errors.New("scheduled job not found")
+
+type JobKind string
+
+const (
+	// JobKindPrompt sends the input to the LLM with a stored prompt.
+	JobKindPrompt JobKind = "prompt"
+	// JobKindWorkflow runs a workflow from the workflow directory.
+	JobKindWorkflow JobKind = "workflow"
+)
+
+// Job is a prompt or a workflow executed on a cron schedule.
+type Job struct {
+	ID        string
+	CreatedAt time.Time
+	Name      string
+	Cron      string
+	Kind      JobKind
+	// Target is the prompt ID or the workflow name.
+	Target  string
+	Input   string
+	Vars    map[string]string
+	Enabled bool
+
+	NextRunAt time.Time
+	LastRunAt time.Time
+}
+
+type RunStatus string
+
+const (
+	RunStatusSuccess RunStatus = "success"
+	RunStatusFailure RunStatus = "failure"
+)
+
+// Run is an execution record of a job.
+type Run struct {
+	ID             string
+	JobID          string
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Status         RunStatus
+	ConversationID string
+	Error          string
+}
+
+func (j *Job) Validate() error {
+	if j.Cron == "" {
+		return errors.New("Job cron expression is required")
+	}
+
+	if _, err := ParseCron(j.Cron); err != nil {
+		return err
+	}
+
+	switch j.Kind {
+	case JobKindPrompt:
+		if j.Input == "" {
+			return errors.New("Job input is required for prompt jobs")
+		}
+	case JobKindWorkflow:
+		if j.Target == "" {
+			return errors.New("Job workflow is required for workflow jobs")
+		}
+	default:
+		return fmt.Errorf("unknown job kind %q", j.Kind)
+	}
+
+	return nil
+}
+
+// Reschedule computes the next activation of the job after t.
+func (j *Job) Reschedule(t time.Time) error {
+	c, err := ParseCron(j.Cron)
+	if err != nil {
+		return err
+	}
+
+	next := c.Next(t.Local())
+	if next.IsZero() {
+		return fmt.Errorf("cron expression %q never matches", j.Cron)
+	}
+	j.NextRunAt = next.UTC()
+
+	return nil
+}
+
+// Due reports whether the job should run at t.
+func (j *Job) Due(t time.Time) bool {
+	return j.Enabled && !j.NextRunAt.IsZero() && !j.NextRunAt.After(t)
+}
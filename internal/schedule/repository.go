@@ -0,0 +1,345 @@
+package schedule
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/google/uuid"
+
+	// sqlite driver
+	_ "modernc.org/sqlite"
+
+	"github.com/nullswan/nomi/internal/migrations"
+)
+
+type Repository interface {
+	SaveJob(job *Job) error
+	// UpdateJobSchedule saves the schedule of an existing job, it returns
+	// ErrJobNotFound when the job was removed.
+	UpdateJobSchedule(job *Job) error
+	LoadJob(id string) (*Job, error)
+	LoadJobs() ([]*Job, error)
+	DeleteJob(id string) error
+
+	SaveRun(run *Run) error
+	// LoadRuns returns the latest runs, of every job if jobID is empty.
+	LoadRuns(jobID string, limit int) ([]Run, error)
+
+	Close() error
+}
+
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(dbPath string) (Repository, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %w", err)
+	}
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("error creating sqlite driver: %w", err)
+	}
+
+	migrations, err := migrations.GetMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("error getting migrations: %w", err)
+	}
+
+	sourceDriver, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error creating source driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance(
+		"iofs",
+		sourceDriver,
+		"sqlite",
+		driver,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating migration instance: %w", err)
+	}
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return nil, fmt.Errorf("error running migrations: %w", err)
+	}
+
+	return &sqliteRepository{db: db}, nil
+}
+
+func (r *sqliteRepository) SaveJob(job *Job) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now().UTC()
+	}
+
+	vars, err := json.Marshal(job.Vars)
+	if err != nil {
+		return fmt.Errorf("error marshalling job vars: %w", err)
+	}
+
+	insertJob := `
+		INSERT OR REPLACE INTO scheduled_jobs (
+			id, created_at, name, cron, kind, target, input, vars, enabled,
+			next_run_at, last_run_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.Exec(
+		insertJob,
+		job.ID,
+		job.CreatedAt,
+		job.Name,
+		job.Cron,
+		job.Kind,
+		job.Target,
+		job.Input,
+		string(vars),
+		job.Enabled,
+		nullTime(job.NextRunAt),
+		nullTime(job.LastRunAt),
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteRepository) UpdateJobSchedule(job *Job) error {
+	updateJob := `
+		UPDATE scheduled_jobs
+		SET enabled = ?, next_run_at = ?, last_run_at = ?
+		WHERE id = ?
+	`
+	res, err := r.db.Exec(
+		updateJob,
+		job.Enabled,
+		nullTime(job.NextRunAt),
+		nullTime(job.LastRunAt),
+		job.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating job: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating job: %w", err)
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+const selectJob = `
+	SELECT id, created_at, name, cron, kind, target, input, vars, enabled,
+		next_run_at, last_run_at
+	FROM scheduled_jobs
+`
+
+func (r *sqliteRepository) LoadJob(id string) (*Job, error) {
+	row := r.db.QueryRow(selectJob+" WHERE id = ?", id)
+
+	job, err := scanJob(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("error querying job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *sqliteRepository) LoadJobs() ([]*Job, error) {
+	rows, err := r.db.Query(selectJob + " ORDER BY created_at ASC")
+	if err != nil {
+		return nil, fmt.Errorf("error querying jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning job: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *sqliteRepository) DeleteJob(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM scheduled_jobs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting job: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrJobNotFound
+	}
+
+	_, err = tx.Exec(`DELETE FROM scheduled_runs WHERE job_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting job runs: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteRepository) SaveRun(run *Run) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+
+	insertRun := `
+		INSERT OR REPLACE INTO scheduled_runs (
+			id, job_id, started_at, finished_at, status, conversation_id, error
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(
+		insertRun,
+		run.ID,
+		run.JobID,
+		run.StartedAt,
+		nullTime(run.FinishedAt),
+		run.Status,
+		run.ConversationID,
+		run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting run: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteRepository) LoadRuns(jobID string, limit int) ([]Run, error) {
+	query := `
+		SELECT id, job_id, started_at, finished_at, status, conversation_id, error
+		FROM scheduled_runs
+		WHERE ? = '' OR job_id = ?
+		ORDER BY started_at DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, jobID, jobID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var (
+			run        Run
+			finishedAt sql.NullTime
+		)
+		err := rows.Scan(
+			&run.ID,
+			&run.JobID,
+			&run.StartedAt,
+			&finishedAt,
+			&run.Status,
+			&run.ConversationID,
+			&run.Error,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning run: %w", err)
+		}
+		run.StartedAt = run.StartedAt.UTC()
+		run.FinishedAt = finishedAt.Time.UTC()
+
+		runs = append(runs, run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+func (r *sqliteRepository) Close() error {
+	err := r.db.Close()
+	if err != nil {
+		return fmt.Errorf("error closing database: %w", err)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row rowScanner) (*Job, error) {
+	var (
+		job       Job
+		vars      string
+		nextRunAt sql.NullTime
+		lastRunAt sql.NullTime
+	)
+	err := row.Scan(
+		&job.ID,
+		&job.CreatedAt,
+		&job.Name,
+		&job.Cron,
+		&job.Kind,
+		&job.Target,
+		&job.Input,
+		&vars,
+		&job.Enabled,
+		&nextRunAt,
+		&lastRunAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if vars != "" && vars != "null" {
+		if err := json.Unmarshal([]byte(vars), &job.Vars); err != nil {
+			return nil, fmt.Errorf("error unmarshalling job vars: %w", err)
+		}
+	}
+
+	job.CreatedAt = job.CreatedAt.UTC()
+	job.NextRunAt = nextRunAt.Time.UTC()
+	job.LastRunAt = lastRunAt.Time.UTC()
+
+	return &job, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
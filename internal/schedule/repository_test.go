@@ -0,0 +1,53 @@
+package schedule
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateJobSchedule(t *testing.T) {
+	t.Parallel()
+
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "nomi.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer repo.Close()
+
+	job := &Job{
+		Name:    "daily",
+		Cron:    "0 9 * * *",
+		Kind:    JobKindPrompt,
+		Input:   "hello",
+		Enabled: true,
+	}
+	if err := repo.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob() error = %v", err)
+	}
+
+	next := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	job.NextRunAt = next
+	if err := repo.UpdateJobSchedule(job); err != nil {
+		t.Fatalf("UpdateJobSchedule() error = %v", err)
+	}
+	saved, err := repo.LoadJob(job.ID)
+	if err != nil {
+		t.Fatalf("LoadJob() error = %v", err)
+	}
+	if !saved.NextRunAt.Equal(next) {
+		t.Errorf("NextRunAt = %v, want %v", saved.NextRunAt, next)
+	}
+
+	// A job removed while it runs is not recreated by its reschedule
+	if err := repo.DeleteJob(job.ID); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if err := repo.UpdateJobSchedule(job); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("UpdateJobSchedule() error = %v, want %v", err, ErrJobNotFound)
+	}
+	if _, err := repo.LoadJob(job.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("LoadJob() error = %v, want %v", err, ErrJobNotFound)
+	}
+}
@@ -2,11 +2,12 @@ package setup
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nullswan/nomi/internal/config"
 	prompts "github.com/nullswan/nomi/internal/prompt"
 	"github.com/nullswan/nomi/internal/term"
-	"github.com/nullswan/nomi/internal/transcription"
+	"github.com/nullswan/nomi/internal/transcription/languages"
 )
 
 // Setup handles the configuration setup
@@ -27,7 +28,7 @@ func Setup() error {
 
 	if cfg.Input.Voice.Enabled {
 		validateVoiceInput := func(value string) error {
-			_, err := transcription.LoadLangFromValue(value)
+			_, err := languages.LoadLangFromValue(value)
 			if err != nil {
 				return fmt.Errorf("invalid language code: %w", err)
 			}
@@ -49,6 +50,16 @@ func Setup() error {
 		cfg.Output.Speech.Enabled,
 	)
 
+	cfg.Interpreter.Sandbox.Enabled = term.PromptForBool(
+		"Run interpreter code in a sandbox [Recommended]",
+		cfg.Interpreter.Sandbox.Enabled,
+	)
+
+	cfg.Interpreter.Approval.Policy = term.PromptSelectString(
+		"When to ask before running interpreter code",
+		[]string{"ask-on-risky", "always-ask", "auto"},
+	)
+
 	cfg.PlaySound = term.PromptForBool(
 		"Play sound on completion",
 		false,
@@ -74,32 +85,34 @@ func Setup() error {
 	)
 
 	if doInstallDefaultPrompts {
-		installDefaultPrompts()
+		installDefaultPrompts(prompts.NewTrust(cfg.Prompts))
 	}
 
 	fmt.Println("Configuration setup completed.")
 	return nil
 }
 
-func installDefaultPrompts() {
+// installDefaultPrompts installs the prompts at the root of the registry
+// embedded in the binary, it works offline.
+func installDefaultPrompts(trust prompts.Trust) {
 	fmt.Println("Installing default prompts...")
-	urls := []string{
-		"https://raw.githubusercontent.com/nullswan/nomi/refs/heads/main/prompts/native-ask.yml",
-		"https://raw.githubusercontent.com/nullswan/nomi/refs/heads/main/prompts/native-code.yml",
-		"https://raw.githubusercontent.com/nullswan/nomi/refs/heads/main/prompts/native-commit-message.yml",
-		"https://raw.githubusercontent.com/nullswan/nomi/refs/heads/main/prompts/native-rephrase.yml",
-		"https://raw.githubusercontent.com/nullswan/nomi/refs/heads/main/prompts/native-summarize.yml",
+
+	registry, err := prompts.LoadRegistry(trust)
+	if err != nil {
+		fmt.Printf("Error loading prompt registry: %v\n", err)
+		return
 	}
 
-	for _, url := range urls {
-		fmt.Printf("Adding prompt from %s\n", url)
+	for _, prompt := range registry.Prompts {
+		if strings.Contains(prompt.ID, "/") {
+			continue
+		}
 
-		_, err := prompts.AddPromptFromURL(url)
-		if err != nil {
+		if _, err := registry.Install(prompt.ID); err != nil {
 			fmt.Printf("Error adding prompt: %v\n", err)
 			continue
 		}
 
-		fmt.Println("Prompt added successfully.")
+		fmt.Printf("Prompt %s added successfully.\n", prompt.ID)
 	}
 }
@@ -0,0 +1,14 @@
+# nomi shell integration for bash.
+# Add to ~/.bashrc: eval "$({{.Binary}} shell-init bash)"
+# Press Ctrl+G to turn the current line into a command.
+
+__nomi_suggest() {
+  local suggestion
+  suggestion="$(HISTTIMEFORMAT= builtin history {{.History}} | sed 's/^ *[0-9]* *//' | {{.Binary}} suggest --shell bash -- "$READLINE_LINE")" || return
+  if [ -n "$suggestion" ]; then
+    READLINE_LINE="$suggestion"
+    READLINE_POINT=${#READLINE_LINE}
+  fi
+}
+
+bind -x '"\C-g": __nomi_suggest'
@@ -0,0 +1,14 @@
+# nomi shell integration for fish.
+# Add to ~/.config/fish/config.fish: {{.Binary}} shell-init fish | source
+# Press Ctrl+G to turn the current line into a command.
+
+function __nomi_suggest
+    set -l buffer (commandline)
+    set -l suggestion (history --max {{.History}} | {{.Binary}} suggest --shell fish -- "$buffer" | string collect)
+    if test -n "$suggestion"
+        commandline -r -- $suggestion
+    end
+    commandline -f repaint
+end
+
+bind \cg __nomi_suggest
@@ -0,0 +1,63 @@
+package shell
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type Shell string
+
+const (
+	Bash Shell = "bash"
+	Zsh  Shell = "zsh"
+	Fish Shell = "fish"
+)
+
+// Shells lists the supported shells.
+var Shells = []Shell{Bash, Zsh, Fish}
+
+// historySize is the number of history entries sent along the buffer.
+const historySize = 20
+
+//go:embed scripts/*
+var scriptsFS embed.FS
+
+func ParseShell(name string) (Shell, error) {
+	for _, s := range Shells {
+		if string(s) == strings.ToLower(name) {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported shell: %s", name)
+}
+
+// InitScript returns the widget script binding the suggestion to a key
+// in the given shell.
+func InitScript(s Shell, binary string) (string, error) {
+	data, err := scriptsFS.ReadFile("scripts/nomi." + string(s))
+	if err != nil {
+		return "", fmt.Errorf("unsupported shell: %s", s)
+	}
+
+	tmpl, err := template.New(string(s)).Parse(string(data))
+	if err != nil {
+		return "", fmt.Errorf("error parsing script: %w", err)
+	}
+
+	var sb strings.Builder
+	err = tmpl.Execute(&sb, struct {
+		Binary  string
+		History int
+	}{
+		Binary:  binary,
+		History: historySize,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error executing script: %w", err)
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,41 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInitScript(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range Shells {
+		t.Run(string(s), func(t *testing.T) {
+			t.Parallel()
+
+			script, err := InitScript(s, "nomi-test")
+			if err != nil {
+				t.Fatalf("InitScript() error = %v", err)
+			}
+
+			if !strings.Contains(script, "nomi-test suggest --shell "+string(s)) {
+				t.Errorf("InitScript() does not call suggest:\n%s", script)
+			}
+
+			if strings.Contains(script, "{{") {
+				t.Errorf("InitScript() left a template action:\n%s", script)
+			}
+		})
+	}
+}
+
+func TestParseShell(t *testing.T) {
+	t.Parallel()
+
+	if s, err := ParseShell("ZSH"); err != nil || s != Zsh {
+		t.Errorf("ParseShell(ZSH) = %v, %v", s, err)
+	}
+
+	if _, err := ParseShell("powershell"); err == nil {
+		t.Error("ParseShell(powershell) expected an error")
+	}
+}
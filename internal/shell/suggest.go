@@ -0,0 +1,136 @@
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/environment"
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+type Risk string
+
+const (
+	RiskLow    Risk = "low"
+	RiskMedium Risk = "medium"
+	RiskHigh   Risk = "high"
+)
+
+// Request describes the shell line to turn into a command.
+type Request struct {
+	Shell   Shell
+	OS      string
+	Cwd     string
+	Buffer  string
+	History []string
+	// Environment describes the installed tools, when known
+	Environment *environment.Environment
+}
+
+// Suggestion is the command proposed by the LLM.
+type Suggestion struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+	Risk        Risk   `json:"risk"`
+}
+
+// Suggest asks the JSON backend for a command matching the request.
+func Suggest(
+	ctx context.Context,
+	backend tools.TextToJSONBackend,
+	req Request,
+) (*Suggestion, error) {
+	if strings.TrimSpace(req.Buffer) == "" {
+		return nil, errors.New("nothing to suggest, the buffer is empty")
+	}
+
+	messages := []chat.Message{
+		chat.NewMessage(chat.RoleSystem, instructionSuggest),
+		chat.NewMessage(chat.RoleUser, formatRequest(req)),
+	}
+
+	resp, err := backend.DoMessages(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("error generating completion: %w", err)
+	}
+
+	var suggestion Suggestion
+	if err := json.Unmarshal([]byte(resp), &suggestion); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	suggestion.Command = strings.TrimSpace(suggestion.Command)
+	if suggestion.Command == "" {
+		return nil, errors.New("no command suggested")
+	}
+
+	switch suggestion.Risk {
+	case RiskLow, RiskMedium, RiskHigh:
+	default:
+		// Never understate an unknown risk
+		suggestion.Risk = RiskHigh
+	}
+
+	return &suggestion, nil
+}
+
+func formatRequest(req Request) string {
+	var sb strings.Builder
+
+	sb.WriteString("Shell: " + string(req.Shell) + "\n")
+	if req.OS != "" {
+		sb.WriteString("OS: " + req.OS + "\n")
+	}
+	if req.Cwd != "" {
+		sb.WriteString("Working directory: " + req.Cwd + "\n")
+	}
+
+	if req.Environment != nil {
+		sb.WriteString("\nEnvironment:\n" + req.Environment.Summary())
+	}
+
+	if len(req.History) > 0 {
+		sb.WriteString("\nRecent history:\n")
+		for _, h := range req.History {
+			sb.WriteString(h + "\n")
+		}
+	}
+
+	sb.WriteString("\nCurrent line:\n" + req.Buffer)
+
+	return sb.String()
+}
+
+const instructionSuggest = `Turn the user's current shell line into a single command line for their shell. The current line is either a natural language request, or a partial command to complete or fix.
+
+Use the shell, the operating system, the working directory, the installed tools and the recent history to pick the most relevant command, and only rely on the tools listed in the environment when it is given. Prefer standard tools, keep the command on a single line and never wrap it in markdown.
+
+# Output Format
+
+A JSON object with the following keys:
+- "command": the command line, ready to be executed as-is in the user's shell.
+- "explanation": one short sentence explaining what the command does.
+- "risk": one of 'low', 'medium' or 'high'.
+  - 'low': read-only commands (listing, searching, printing).
+  - 'medium': commands modifying files, packages or the repository in a recoverable way.
+  - 'high': destructive or hard to revert commands (rm -rf, force push, dd, chmod -R, sudo, piping remote scripts to a shell).
+
+# Examples
+
+Current line: "find all go files modified today"
+{
+  "command": "find . -name '*.go' -mtime -1",
+  "explanation": "Lists the Go files modified in the last 24 hours.",
+  "risk": "low"
+}
+
+Current line: "git pusj --force"
+{
+  "command": "git push --force-with-lease",
+  "explanation": "Force pushes the current branch, refusing to overwrite unknown remote changes.",
+  "risk": "high"
+}`
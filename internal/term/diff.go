@@ -0,0 +1,35 @@
+package term
+
+import (
+	"strings"
+
+	"github.com/nullswan/nomi/internal/diff"
+)
+
+// FormatDiff renders the diff with added lines in green and removed lines
+// in red.
+func FormatDiff(lines []diff.Line) string {
+	var sb strings.Builder
+	for _, l := range lines {
+		switch l.Op {
+		case diff.OpInsert:
+			sb.WriteString(ColorGreen + "+" + l.Text + ColorDefault)
+		case diff.OpDelete:
+			sb.WriteString(ColorRed + "-" + l.Text + ColorDefault)
+		default:
+			sb.WriteString(" " + l.Text)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// FormatHunks renders the hunks of a diff under their grey headers.
+func FormatHunks(hunks []diff.Hunk) string {
+	var sb strings.Builder
+	for _, h := range hunks {
+		sb.WriteString(ColorGrey + h.Header() + ColorDefault + "\n")
+		sb.WriteString(FormatDiff(h.Lines))
+	}
+	return sb.String()
+}
@@ -0,0 +1,44 @@
+package term
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Edit opens the content in $EDITOR, vim by default, and returns the
+// edited content. The pattern names the temporary file, see os.CreateTemp.
+func Edit(content string, pattern string) (string, error) {
+	tempFile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.WriteString(content); err != nil {
+		tempFile.Close()
+		return "", fmt.Errorf("error writing to temp file: %w", err)
+	}
+	tempFile.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+
+	process := exec.Command(editor, tempFile.Name())
+	process.Stdin = os.Stdin
+	process.Stdout = os.Stdout
+	process.Stderr = os.Stderr
+
+	if err := process.Run(); err != nil {
+		return "", fmt.Errorf("error opening editor: %w", err)
+	}
+
+	data, err := os.ReadFile(tempFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("error reading updated file: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -61,6 +61,9 @@ const (
 	CursorReset = Esc + "[0;0f"
 
 	ColorGrey    = Esc + "[38;5;245m"
+	ColorRed     = Esc + "[31m"
+	ColorGreen   = Esc + "[32m"
+	ColorYellow  = Esc + "[33m"
 	ColorDefault = Esc + "[0m"
 
 	StartBracketedPaste = Esc + "[?2004h"
@@ -16,6 +16,7 @@ import (
 type TextToTextBackend struct {
 	backend baseprovider.TextToTextProvider
 	logger  *slog.Logger
+	options completion.RequestOptions
 }
 
 func NewTextToTextBackend(
@@ -28,16 +29,30 @@ func NewTextToTextBackend(
 	}
 }
 
+// WithOptions returns a copy of the backend sending the request options.
+func (t TextToTextBackend) WithOptions(
+	opts completion.RequestOptions,
+) TextToTextBackend {
+	t.options = opts
+	return t
+}
+
 func (t TextToTextBackend) Do(
 	ctx context.Context,
 	conversation chat.Conversation,
 ) (string, error) {
-	messages := conversation.GetMessages()
+	return t.DoMessages(ctx, conversation.GetMessages())
+}
 
+// DoMessages generates a completion from a raw list of messages.
+func (t TextToTextBackend) DoMessages(
+	ctx context.Context,
+	messages []chat.Message,
+) (string, error) {
 	outCh := make(chan completion.Completion)
 	go func() {
 		defer close(outCh)
-		if err := t.backend.GenerateCompletion(ctx, messages, outCh); err != nil {
+		if err := t.backend.GenerateCompletion(ctx, messages, t.options, outCh); err != nil {
 			if strings.Contains(err.Error(), "context canceled") {
 				return
 			}
@@ -59,6 +74,7 @@ func (t TextToTextBackend) Do(
 type TextToJSONBackend struct {
 	backend baseprovider.TextToJSONProvider
 	logger  *slog.Logger
+	options completion.RequestOptions
 }
 
 func NewTextToJSONBackend(
@@ -71,16 +87,30 @@ func NewTextToJSONBackend(
 	}
 }
 
+// WithOptions returns a copy of the backend sending the request options.
+func (t TextToJSONBackend) WithOptions(
+	opts completion.RequestOptions,
+) TextToJSONBackend {
+	t.options = opts
+	return t
+}
+
 func (t TextToJSONBackend) Do(
 	ctx context.Context,
 	conversation chat.Conversation,
 ) (string, error) {
-	messages := conversation.GetMessages()
+	return t.DoMessages(ctx, conversation.GetMessages())
+}
 
+// DoMessages generates a completion from a raw list of messages.
+func (t TextToJSONBackend) DoMessages(
+	ctx context.Context,
+	messages []chat.Message,
+) (string, error) {
 	outCh := make(chan completion.Completion)
 	go func() {
 		defer close(outCh)
-		if err := t.backend.GenerateCompletion(ctx, messages, outCh); err != nil {
+		if err := t.backend.GenerateCompletion(ctx, messages, t.options, outCh); err != nil {
 			if strings.Contains(err.Error(), "context canceled") {
 				return
 			}
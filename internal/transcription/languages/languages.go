@@ -1,4 +1,4 @@
-package transcription
+package languages
 
 import (
 	"fmt"
@@ -10,6 +10,7 @@ import (
 
 	"github.com/nullswan/nomi/internal/audio"
 	"github.com/nullswan/nomi/internal/logger"
+	"github.com/nullswan/nomi/internal/transcription/languages"
 	openai "github.com/sashabaranov/go-openai"
 )
 
@@ -166,6 +167,6 @@ func (th *TranscriptionHandler) GetMetrics() *TranscriptionHandlerMetrics {
 }
 
 // WithLanguage sets the language for the transcription handler.
-func (th *TranscriptionHandler) WithLanguage(language STTLang) {
+func (th *TranscriptionHandler) WithLanguage(language languages.STTLang) {
 	th.language = language.ToString()
 }
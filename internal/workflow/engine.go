@@ -0,0 +1,432 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/completion"
+	prompts "github.com/nullswan/nomi/internal/prompt"
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+// OutputVar is the variable holding the output of the last executed step.
+const OutputVar = "output"
+
+// Engine runs workflows against the nomi tools. Tools that are not used by
+// a workflow can be left nil.
+type Engine struct {
+	TextToText   *tools.TextToTextBackend
+	TextToJSON   *tools.TextToJSONBackend
+	Console      tools.Console
+	Selector     tools.Selector
+	InputHandler tools.InputHandler
+	Logger       tools.Logger
+
+	// LoadPrompt resolves stored prompts, defaults to prompts.LoadPrompt.
+	LoadPrompt func(id string) (*prompts.Prompt, error)
+}
+
+// Run executes the workflow steps in order and returns the final variables.
+func (e *Engine) Run(
+	ctx context.Context,
+	wf *Workflow,
+	vars map[string]interface{},
+) (map[string]interface{}, error) {
+	state := make(map[string]interface{}, len(wf.Vars)+len(vars))
+	for k, v := range wf.Vars {
+		state[k] = v
+	}
+	for k, v := range vars {
+		state[k] = v
+	}
+
+	if err := e.runSteps(ctx, wf.Steps, state); err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
+
+func (e *Engine) runSteps(
+	ctx context.Context,
+	steps []Step,
+	vars map[string]interface{},
+) error {
+	for i := range steps {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context done: %w", err)
+		}
+
+		step := &steps[i]
+		if err := e.runStep(ctx, step, vars); err != nil {
+			return fmt.Errorf("step %s: %w", step.label(i), err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) runStep(
+	ctx context.Context,
+	step *Step,
+	vars map[string]interface{},
+) error {
+	if step.If != "" {
+		cond, err := render(step.If, vars)
+		if err != nil {
+			return err
+		}
+		if !truthy(cond) {
+			e.debug("Skipping step " + step.Name)
+			// The output of a skipped step is empty, not undefined
+			if step.ID != "" {
+				vars[step.ID] = ""
+			}
+			return nil
+		}
+	}
+
+	switch {
+	case step.ForEach != "":
+		value, err := render(step.ForEach, vars)
+		if err != nil {
+			return err
+		}
+
+		loopVar := step.loopVar()
+		outputs := make([]interface{}, 0)
+		for _, item := range items(value) {
+			vars[loopVar] = item
+
+			out, err := e.execute(ctx, step, vars)
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, out)
+		}
+		delete(vars, loopVar)
+
+		e.store(step, vars, outputs)
+		return nil
+	case step.While != "":
+		maxIterations := step.MaxIterations
+		if maxIterations <= 0 {
+			maxIterations = defaultMaxIterations
+		}
+
+		loopVar := step.loopVar()
+		outputs := make([]interface{}, 0)
+		for i := 0; ; i++ {
+			cond, err := render(step.While, vars)
+			if err != nil {
+				return err
+			}
+			if !truthy(cond) {
+				break
+			}
+			if i >= maxIterations {
+				return fmt.Errorf(
+					"while loop exceeded %d iterations",
+					maxIterations,
+				)
+			}
+
+			vars[loopVar] = i
+			out, err := e.execute(ctx, step, vars)
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, out)
+
+			// Expose the last result to the condition
+			e.store(step, vars, out)
+		}
+		delete(vars, loopVar)
+
+		e.store(step, vars, outputs)
+		return nil
+	default:
+		out, err := e.execute(ctx, step, vars)
+		if err != nil {
+			return err
+		}
+
+		e.store(step, vars, out)
+		return nil
+	}
+}
+
+// execute runs the action of the step once and returns its output.
+func (e *Engine) execute(
+	ctx context.Context,
+	step *Step,
+	vars map[string]interface{},
+) (interface{}, error) {
+	switch {
+	case step.Prompt != nil:
+		if e.TextToText == nil {
+			return nil, errors.New("no text backend available")
+		}
+		messages, opts, err := e.messages(step.Prompt, vars)
+		if err != nil {
+			return nil, err
+		}
+		out, err := e.TextToText.WithOptions(opts).DoMessages(ctx, messages)
+		if err != nil {
+			return nil, fmt.Errorf("error generating completion: %w", err)
+		}
+		return out, nil
+	case step.JSON != nil:
+		if e.TextToJSON == nil {
+			return nil, errors.New("no json backend available")
+		}
+		messages, opts, err := e.messages(step.JSON, vars)
+		if err != nil {
+			return nil, err
+		}
+		out, err := e.TextToJSON.WithOptions(opts).DoMessages(ctx, messages)
+		if err != nil {
+			return nil, fmt.Errorf("error generating completion: %w", err)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(out), &value); err != nil {
+			return nil, fmt.Errorf("error unmarshalling response: %w", err)
+		}
+		return value, nil
+	case step.Code != nil:
+		source, err := render(step.Code.Source, vars)
+		if err != nil {
+			return nil, err
+		}
+		result := code.ExecuteCodeBlock(
+			ctx,
+			code.CodeBlock{
+				Language: step.Code.Language,
+				Code:     source,
+			},
+			code.ExecuteOptions{
+				Timeout: time.Duration(step.Code.Timeout) * time.Second,
+			},
+		)
+		if result.ExitCode != 0 {
+			return nil, fmt.Errorf(
+				"code exited with %d: %s",
+				result.ExitCode,
+				strings.TrimSpace(result.Stderr),
+			)
+		}
+		return strings.TrimSpace(result.Stdout), nil
+	case step.Shell != nil:
+		return e.shell(ctx, step.Shell, vars)
+	case step.Ask != nil:
+		return e.ask(ctx, step.Ask, vars)
+	case step.Set != nil:
+		values := make(map[string]interface{}, len(step.Set))
+		for k, v := range step.Set {
+			out, err := render(v, vars)
+			if err != nil {
+				return nil, err
+			}
+			vars[k] = out
+			values[k] = out
+		}
+		return values, nil
+	case step.Print != "":
+		out, err := render(step.Print, vars)
+		if err != nil {
+			return nil, err
+		}
+		if e.Logger != nil {
+			e.Logger.Println(out)
+		}
+		return out, nil
+	case len(step.Steps) > 0:
+		if err := e.runSteps(ctx, step.Steps, vars); err != nil {
+			return nil, err
+		}
+		return vars[OutputVar], nil
+	default:
+		return nil, errors.New("step has no action")
+	}
+}
+
+func (e *Engine) messages(
+	step *PromptStep,
+	vars map[string]interface{},
+) ([]chat.Message, completion.RequestOptions, error) {
+	var (
+		messages []chat.Message
+		opts     completion.RequestOptions
+	)
+
+	if step.Prompt != "" {
+		loadPrompt := e.LoadPrompt
+		if loadPrompt == nil {
+			loadPrompt = prompts.LoadPrompt
+		}
+
+		prompt, err := loadPrompt(step.Prompt)
+		if err != nil {
+			return nil, opts, fmt.Errorf(
+				"error loading prompt %s: %w",
+				step.Prompt,
+				err,
+			)
+		}
+
+		// Without Confirm, the file, env and shell calls of the prompt
+		// are refused
+		prompt, err = prompt.Render(
+			prompts.RenderOptions{Vars: promptVars(vars)},
+		)
+		if err != nil {
+			return nil, opts, fmt.Errorf(
+				"error rendering prompt %s: %w",
+				step.Prompt,
+				err,
+			)
+		}
+
+		opts = prompt.Preferences.RequestOptions()
+		messages = append(messages, chat.PromptMessages(*prompt)...)
+	}
+
+	if step.System != "" {
+		system, err := render(step.System, vars)
+		if err != nil {
+			return nil, opts, err
+		}
+		messages = append(messages, chat.NewMessage(chat.RoleSystem, system))
+	}
+
+	input, err := render(step.Input, vars)
+	if err != nil {
+		return nil, opts, err
+	}
+	messages = append(messages, chat.NewMessage(chat.RoleUser, input))
+
+	return messages, opts, nil
+}
+
+func (e *Engine) shell(
+	ctx context.Context,
+	step *ShellStep,
+	vars map[string]interface{},
+) (interface{}, error) {
+	if e.Console == nil {
+		return nil, errors.New("no console available")
+	}
+
+	command, err := render(step.Command, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, len(step.Args))
+	for i, arg := range step.Args {
+		args[i], err = render(arg, vars)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	input, err := render(step.Input, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := tools.NewCommand(command, args...).WithInput(input)
+	result, err := e.Console.Exec(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error executing command: %w", err)
+	}
+
+	if !result.Success() && !step.AllowFailure {
+		return nil, fmt.Errorf(
+			"command exited with %d: %s",
+			result.ExitCode,
+			strings.TrimSpace(result.Error),
+		)
+	}
+
+	return strings.TrimSpace(result.Output), nil
+}
+
+func (e *Engine) ask(
+	ctx context.Context,
+	step *AskStep,
+	vars map[string]interface{},
+) (interface{}, error) {
+	question, err := render(step.Question, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch step.Type {
+	case AskTypeBool:
+		if e.Selector == nil {
+			return nil, errors.New("no selector available")
+		}
+		return e.Selector.SelectBool(question, step.Default), nil
+	case AskTypeChoice:
+		if e.Selector == nil {
+			return nil, errors.New("no selector available")
+		}
+		return e.Selector.SelectString(question, step.Choices), nil
+	case AskTypeText:
+		fallthrough
+	default:
+		if e.InputHandler == nil {
+			return nil, errors.New("no input handler available")
+		}
+		if e.Logger != nil {
+			e.Logger.Println(question)
+		}
+		answer, err := e.InputHandler.Read(ctx, ">>> ")
+		if err != nil {
+			return nil, fmt.Errorf("error reading input: %w", err)
+		}
+		return answer, nil
+	}
+}
+
+func (e *Engine) store(
+	step *Step,
+	vars map[string]interface{},
+	value interface{},
+) {
+	if step.ID != "" {
+		vars[step.ID] = value
+	}
+	vars[OutputVar] = value
+}
+
+func (e *Engine) debug(msg string) {
+	if e.Logger != nil {
+		e.Logger.Debug(msg)
+	}
+}
+
+func (s *Step) label(index int) string {
+	switch {
+	case s.ID != "":
+		return s.ID
+	case s.Name != "":
+		return s.Name
+	default:
+		return fmt.Sprintf("#%d", index+1)
+	}
+}
+
+func (s *Step) loopVar() string {
+	if s.As != "" {
+		return s.As
+	}
+	return "item"
+}
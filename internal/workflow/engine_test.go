@@ -0,0 +1,176 @@
+package workflow
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+type mockLogger struct {
+	lines []string
+}
+
+func (m *mockLogger) Debug(_ string)     {}
+func (m *mockLogger) Info(_ string)      {}
+func (m *mockLogger) Error(_ string)     {}
+func (m *mockLogger) Println(msg string) { m.lines = append(m.lines, msg) }
+
+type mockConsole struct {
+	result tools.ExecResult
+}
+
+func (m *mockConsole) Exec(
+	_ context.Context,
+	cmd *tools.Command,
+) (tools.ExecResult, error) {
+	r := m.result
+	if r.Output == "" {
+		r.Output = cmd.String()
+	}
+	return r, nil
+}
+
+func TestEngineRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		workflow Workflow
+		vars     map[string]interface{}
+		console  tools.ExecResult
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "Set and print",
+			workflow: Workflow{
+				Vars: map[string]string{"name": "nomi"},
+				Steps: []Step{
+					{Set: map[string]string{"greeting": "hello {{.name}}"}},
+					{Print: "{{.greeting}}"},
+				},
+			},
+			expected: []string{"hello nomi"},
+		},
+		{
+			name: "Override vars",
+			workflow: Workflow{
+				Vars:  map[string]string{"name": "nomi"},
+				Steps: []Step{{Print: "{{.name}}"}},
+			},
+			vars:     map[string]interface{}{"name": "world"},
+			expected: []string{"world"},
+		},
+		{
+			name: "Conditional",
+			workflow: Workflow{
+				Vars: map[string]string{"enabled": "false"},
+				Steps: []Step{
+					{If: "{{.enabled}}", Print: "skipped"},
+					{If: "{{not (eq .enabled \"true\")}}", Print: "ran"},
+				},
+			},
+			expected: []string{"ran"},
+		},
+		{
+			name: "Skipped step output",
+			workflow: Workflow{
+				Steps: []Step{
+					{ID: "skipped", If: "false", Print: "skipped"},
+					{Print: "[{{.skipped}}] <no value>"},
+				},
+			},
+			expected: []string{"[] <no value>"},
+		},
+		{
+			name: "Undefined variable",
+			workflow: Workflow{
+				Steps: []Step{{Print: "{{.undefined}}"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Foreach over JSON",
+			workflow: Workflow{
+				Steps: []Step{
+					{
+						ForEach: `["a", "b"]`,
+						As:      "letter",
+						Print:   "{{.letter}}",
+					},
+				},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "Foreach over lines with nested steps",
+			workflow: Workflow{
+				Steps: []Step{
+					{
+						ID:      "loop",
+						ForEach: "x\ny\n",
+						Steps: []Step{
+							{Set: map[string]string{"upper": "{{upper .item}}"}},
+							{Print: "{{.upper}}"},
+						},
+					},
+					{Print: "{{len .loop}}"},
+				},
+			},
+			expected: []string{"X", "Y", "2"},
+		},
+		{
+			name: "Shell output",
+			workflow: Workflow{
+				Steps: []Step{
+					{ID: "cmd", Shell: &ShellStep{Command: "echo", Args: []string{"{{.v}}"}}},
+					{Print: "{{.cmd}}"},
+				},
+			},
+			vars:     map[string]interface{}{"v": "hi"},
+			expected: []string{"echo hi"},
+		},
+		{
+			name: "Shell failure",
+			workflow: Workflow{
+				Steps: []Step{{Shell: &ShellStep{Command: "false"}}},
+			},
+			console: tools.ExecResult{ExitCode: 1, Output: "-"},
+			wantErr: true,
+		},
+		{
+			name: "While bounded",
+			workflow: Workflow{
+				Steps: []Step{
+					{While: "true", MaxIterations: 2, Print: "loop"},
+				},
+			},
+			expected: []string{"loop", "loop"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := &mockLogger{}
+			engine := &Engine{
+				Console: &mockConsole{result: tt.console},
+				Logger:  logger,
+			}
+
+			_, err := engine.Run(context.Background(), &tt.workflow, tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(tt.expected) > 0 &&
+				!reflect.DeepEqual(logger.lines, tt.expected) {
+				t.Errorf("Run() printed %v, want %v", logger.lines, tt.expected)
+			}
+		})
+	}
+}
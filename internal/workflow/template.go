@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling value: %w", err)
+		}
+		return string(data), nil
+	},
+	"fromJSON": func(s string) (interface{}, error) {
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("error unmarshalling value: %w", err)
+		}
+		return v, nil
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"trim":     strings.TrimSpace,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"contains": strings.Contains,
+	"split":    strings.Split,
+	"join": func(sep string, items []interface{}) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+}
+
+// render executes a text/template against the workflow variables, an
+// undefined variable is an error.
+func render(text string, vars map[string]interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("").
+		Funcs(templateFuncs).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("error executing template: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// truthy evaluates a rendered condition.
+func truthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no", "n", "off", "null", "nil":
+		return false
+	default:
+		return true
+	}
+}
+
+// items splits a rendered foreach value into loop items. JSON arrays are
+// decoded, anything else is split by non-empty lines.
+func items(value string) []interface{} {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(value, "[") {
+		var list []interface{}
+		if err := json.Unmarshal([]byte(value), &list); err == nil {
+			return list
+		}
+	}
+
+	var list []interface{}
+	for _, line := range strings.Split(value, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		list = append(list, line)
+	}
+
+	return list
+}
+
+// promptVars converts the workflow variables for the prompt templates.
+func promptVars(vars map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(vars))
+	for k, v := range vars {
+		if s, ok := v.(string); ok {
+			out[k] = s
+			continue
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			out[k] = fmt.Sprint(v)
+			continue
+		}
+		out[k] = string(data)
+	}
+	return out
+}
@@ -0,0 +1,254 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/nullswan/nomi/internal/config"
+)
+
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
+// Workflow is a declarative pipeline of steps loaded from YAML.
+type Workflow struct {
+	ID          string            `yaml:"id"`
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Vars        map[string]string `yaml:"vars,omitempty"`
+	Steps       []Step            `yaml:"steps"`
+}
+
+// Step is a single unit of work. Exactly one action (prompt, json, code,
+// shell, ask, set, print) must be set, or nested steps for grouping.
+// The output of the step is stored in the variable named after its ID.
+type Step struct {
+	ID   string `yaml:"id,omitempty"`
+	Name string `yaml:"name,omitempty"`
+
+	// If is a template, the step is skipped when it renders to a falsy value
+	// and its output is empty.
+	If string `yaml:"if,omitempty"`
+	// ForEach is a template rendering to a JSON array or to lines, the step
+	// runs once per item, exposed under the As variable (default: item).
+	ForEach string `yaml:"foreach,omitempty"`
+	// While is a template, the step repeats while it renders truthy.
+	While         string `yaml:"while,omitempty"`
+	MaxIterations int    `yaml:"max_iterations,omitempty"`
+	As            string `yaml:"as,omitempty"`
+
+	Prompt *PromptStep       `yaml:"prompt,omitempty"`
+	JSON   *PromptStep       `yaml:"json,omitempty"`
+	Code   *CodeStep         `yaml:"code,omitempty"`
+	Shell  *ShellStep        `yaml:"shell,omitempty"`
+	Ask    *AskStep          `yaml:"ask,omitempty"`
+	Set    map[string]string `yaml:"set,omitempty"`
+	Print  string            `yaml:"print,omitempty"`
+	Steps  []Step            `yaml:"steps,omitempty"`
+}
+
+// PromptStep calls the LLM, optionally with a stored prompt as system.
+type PromptStep struct {
+	Prompt string `yaml:"prompt,omitempty"`
+	System string `yaml:"system,omitempty"`
+	Input  string `yaml:"input"`
+}
+
+type CodeStep struct {
+	Language string `yaml:"language"`
+	Source   string `yaml:"source"`
+	// Timeout in seconds, defaults to the timeout of the language.
+	Timeout int `yaml:"timeout,omitempty"`
+}
+
+type ShellStep struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+	Input   string   `yaml:"input,omitempty"`
+	// AllowFailure keeps the workflow running on a non-zero exit code.
+	AllowFailure bool `yaml:"allow_failure,omitempty"`
+}
+
+type AskType string
+
+const (
+	AskTypeText   AskType = "text"
+	AskTypeBool   AskType = "bool"
+	AskTypeChoice AskType = "choice"
+)
+
+type AskStep struct {
+	Question string   `yaml:"question"`
+	Type     AskType  `yaml:"type,omitempty"`
+	Choices  []string `yaml:"choices,omitempty"`
+	Default  bool     `yaml:"default,omitempty"`
+}
+
+const defaultMaxIterations = 10
+
+func (w *Workflow) Validate() error {
+	if w.ID == "" {
+		return errors.New("Workflow ID is required")
+	}
+
+	if len(w.Steps) == 0 {
+		return errors.New("Workflow must have at least one step")
+	}
+
+	return validateSteps(w.Steps, "steps")
+}
+
+func validateSteps(steps []Step, path string) error {
+	for i, step := range steps {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		if step.ID != "" {
+			stepPath += " (" + step.ID + ")"
+		}
+
+		if err := step.validate(stepPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) validate(path string) error {
+	actions := 0
+	if s.Prompt != nil {
+		actions++
+	}
+	if s.JSON != nil {
+		actions++
+	}
+	if s.Code != nil {
+		actions++
+	}
+	if s.Shell != nil {
+		actions++
+	}
+	if s.Ask != nil {
+		actions++
+	}
+	if s.Set != nil {
+		actions++
+	}
+	if s.Print != "" {
+		actions++
+	}
+	if len(s.Steps) > 0 {
+		actions++
+	}
+
+	if actions != 1 {
+		return fmt.Errorf("%s: exactly one action is required", path)
+	}
+
+	if s.ForEach != "" && s.While != "" {
+		return fmt.Errorf("%s: foreach and while are exclusive", path)
+	}
+
+	if s.Code != nil && s.Code.Language == "" {
+		return fmt.Errorf("%s: code language is required", path)
+	}
+
+	if s.Shell != nil && s.Shell.Command == "" {
+		return fmt.Errorf("%s: shell command is required", path)
+	}
+
+	if s.Ask != nil {
+		switch s.Ask.Type {
+		case "", AskTypeText, AskTypeBool:
+		case AskTypeChoice:
+			if len(s.Ask.Choices) == 0 {
+				return fmt.Errorf("%s: ask choices are required", path)
+			}
+		default:
+			return fmt.Errorf("%s: unknown ask type %q", path, s.Ask.Type)
+		}
+	}
+
+	return validateSteps(s.Steps, path+".steps")
+}
+
+// LoadWorkflow loads a workflow by name from the workflow directory.
+func LoadWorkflow(name string) (*Workflow, error) {
+	dir := config.GetWorkflowDirectory()
+
+	candidates := []string{name}
+	if filepath.Ext(name) == "" {
+		candidates = []string{name + ".yml", name + ".yaml"}
+	}
+
+	for _, candidate := range candidates {
+		fp := filepath.Join(dir, candidate)
+		if _, err := os.Stat(fp); os.IsNotExist(err) {
+			continue
+		}
+
+		return LoadWorkflowFile(fp)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, name)
+}
+
+// LoadWorkflowFile loads and validates a workflow from a YAML file.
+func LoadWorkflowFile(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow file: %w", err)
+	}
+
+	var wf Workflow
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("error unmarshalling workflow file: %w", err)
+	}
+
+	if wf.ID == "" {
+		wf.ID = strings.TrimSuffix(
+			filepath.Base(path),
+			filepath.Ext(path),
+		)
+	}
+
+	if err := wf.Validate(); err != nil {
+		return nil, fmt.Errorf("error validating workflow: %w", err)
+	}
+
+	return &wf, nil
+}
+
+// ListWorkflows returns all the workflows of the workflow directory.
+func ListWorkflows() ([]Workflow, error) {
+	files, err := os.ReadDir(config.GetWorkflowDirectory())
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow directory: %w", err)
+	}
+
+	var workflows []Workflow
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+
+		wf, err := LoadWorkflowFile(
+			filepath.Join(config.GetWorkflowDirectory(), file.Name()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error loading workflow %s: %w",
+				file.Name(),
+				err,
+			)
+		}
+
+		workflows = append(workflows, *wf)
+	}
+
+	return workflows, nil
+}
@@ -0,0 +1,11 @@
+// Package prompts embeds the prompts of the repository, the registry
+// available offline.
+package prompts
+
+import "embed"
+
+// FS holds the prompts listed by index.yml, a prompt under a directory is
+// namespaced by it.
+//
+//go:embed *.yml */*.yml
+var FS embed.FS
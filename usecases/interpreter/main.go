@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 
+	"github.com/nullswan/nomi/internal/approval"
 	"github.com/nullswan/nomi/internal/chat"
 	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/config"
+	"github.com/nullswan/nomi/internal/environment"
 	"github.com/nullswan/nomi/internal/tools"
 )
 
@@ -22,15 +26,32 @@ func OnStart(
 	textToJSON tools.TextToJSONBackend,
 	inputHandler tools.InputHandler,
 	conversation chat.Conversation,
+	approver *approval.Approver,
+	snippets code.Repository,
+	notebookCfg config.NotebookConfig,
 ) error {
 	logger.Info("Starting console usecase")
 
+	if notebookCfg.AutoExport {
+		defer exportNotebook(conversation, notebookCfg.Directory, logger)
+	}
+
 	systemPrompt, err := getConsoleInstruction(
 		runtime.GOOS,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to get console instruction: %w", err)
 	}
+	systemPrompt += getLanguagesInstruction(code.InstalledLanguages())
+	systemPrompt += instructionSessions
+	systemPrompt += instructionExplanation
+
+	env, err := environment.Load(ctx, config.GetEnvironmentCachePath())
+	if err != nil {
+		logger.Error("Failed to probe the environment: " + err.Error())
+	} else {
+		systemPrompt += getEnvironmentInstruction(env)
+	}
 
 	conversation.AddMessage(
 		chat.NewMessage(
@@ -39,9 +60,12 @@ func OnStart(
 		),
 	)
 
-	req, err := inputHandler.Read(ctx, ">>> ")
+	sessions := code.NewSessions()
+	defer sessions.Close()
+
+	req, err := readInput(ctx, inputHandler, sessions)
 	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
+		return err
 	}
 
 	conversation.AddMessage(
@@ -60,9 +84,9 @@ func OnStart(
 			// Handle too many errors
 			if errorRetries > executionErrorLimit {
 				fmt.Println("Too many errors, how can I help you?")
-				resp, err := inputHandler.Read(ctx, ">>> ")
+				resp, err := readInput(ctx, inputHandler, sessions)
 				if err != nil {
-					return fmt.Errorf("failed to read input: %w", err)
+					return err
 				}
 
 				conversation.AddMessage(
@@ -100,36 +124,83 @@ func OnStart(
 			switch consoleResp.Action {
 			case consoleActionCode:
 				logger.Debug("Received code block " + consoleResp.Code)
-				// Sanitize code, add code block if necessary
-				if consoleResp.Language != "" && consoleResp.Code != "" &&
-					!strings.HasPrefix(consoleResp.Code, "```") {
-					consoleResp.Code = "```" + consoleResp.Language + "\n" + consoleResp.Code + "\n```"
-				}
 
-				result := code.InterpretCodeBlocks(consoleResp.Code)
+				if consoleResp.Explanation != "" {
+					fmt.Println(consoleResp.Explanation)
+				}
 
-				if len(result) == 0 {
+				// Review what runs, each block in its own language, the
+				// code can hold blocks in other languages than declared
+				blocks := code.ParseCodeBlocks(consoleResp.markdown())
+				if len(blocks) == 0 {
 					logger.Info("No code blocks found")
 					continue
 				}
 
-				containsError := true
-				for _, r := range result {
-					// TODO(nullswan): save the block
+				approved := true
+				for i, block := range blocks {
+					review := approver.Review(block.Language, block.Code)
+					if !review.Approved {
+						approved = false
+						break
+					}
+					blocks[i].Code = review.Code
+				}
 
-					fmt.Printf(
-						"Received (%d): %s\n%s\n",
-						r.ExitCode,
-						r.Stdout,
-						r.Stderr,
+				if !approved {
+					conversation.AddMessage(
+						chat.NewMessage(
+							chat.RoleUser,
+							"I denied the execution of this code.",
+						),
 					)
 
-					if r.ExitCode == 0 {
-						containsError = false
-						break
+					fmt.Println("Execution denied, how can I help you?")
+					req, err := readInput(ctx, inputHandler, sessions)
+					if err != nil {
+						return err
+					}
+
+					conversation.AddMessage(
+						chat.NewMessage(
+							chat.RoleUser,
+							req,
+						),
+					)
+					continue
+				}
+
+				result := code.ExecuteCodeBlocks(
+					ctx,
+					blocks,
+					code.ExecuteOptions{
+						OnOutput:     streamOutput,
+						Sessions:     sessions,
+						AllLanguages: true,
+					},
+				)
+
+				containsError := false
+				for _, r := range result {
+					// Output was already streamed to the terminal
+					fmt.Printf("Exited with code %d\n", r.ExitCode)
+
+					if r.ExitCode != 0 {
+						containsError = true
 					}
 				}
 
+				if snippets != nil {
+					saveSnippets(
+						ctx,
+						textToJSON,
+						snippets,
+						logger,
+						lastUserRequest(conversation),
+						result,
+					)
+				}
+
 				formattedResult := code.FormatExecutionResultForLLM(result)
 				conversation.AddMessage(
 					chat.NewMessage(
@@ -152,9 +223,9 @@ func OnStart(
 						return nil
 					}
 
-					req, err := inputHandler.Read(ctx, ">>> ")
+					req, err := readInput(ctx, inputHandler, sessions)
 					if err != nil {
-						return fmt.Errorf("failed to read input: %w", err)
+						return err
 					}
 
 					conversation.AddMessage(
@@ -166,12 +237,9 @@ func OnStart(
 				}
 			case consoleActionAsk:
 				fmt.Println(consoleResp.Question)
-				req, err := inputHandler.Read(
-					ctx,
-					">>> ",
-				)
+				req, err := readInput(ctx, inputHandler, sessions)
 				if err != nil {
-					return fmt.Errorf("failed to read input: %w", err)
+					return err
 				}
 
 				conversation.AddMessage(
@@ -189,10 +257,21 @@ func OnStart(
 }
 
 type consoleResponse struct {
-	Action   consoleAction `json:"action"`
-	Question string        `json:"question"`
-	Language string        `json:"language"`
-	Code     string        `json:"code"`
+	Action      consoleAction `json:"action"`
+	Question    string        `json:"question"`
+	Language    string        `json:"language"`
+	Code        string        `json:"code"`
+	Explanation string        `json:"explanation"`
+}
+
+// markdown returns the code as fenced code blocks, adding the fences when
+// the code is not already in blocks.
+func (r consoleResponse) markdown() string {
+	if r.Language != "" && r.Code != "" &&
+		!strings.HasPrefix(r.Code, "```") {
+		return "```" + r.Language + "\n" + r.Code + "\n```"
+	}
+	return r.Code
 }
 
 type consoleAction string
@@ -201,3 +280,77 @@ const (
 	consoleActionCode consoleAction = "code"
 	consoleActionAsk  consoleAction = "ask"
 )
+
+// streamOutput shows the output of the running code in the terminal.
+func streamOutput(stream code.Stream, data []byte) {
+	if stream == code.StreamStderr {
+		_, _ = os.Stderr.Write(data)
+		return
+	}
+	_, _ = os.Stdout.Write(data)
+}
+
+// readInput reads the next request of the user, handling the session
+// commands on the way.
+func readInput(
+	ctx context.Context,
+	inputHandler tools.InputHandler,
+	sessions *code.Sessions,
+) (string, error) {
+	for {
+		input, err := inputHandler.Read(ctx, ">>> ")
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+
+		if !handleSessionCommand(ctx, sessions, input) {
+			return input, nil
+		}
+	}
+}
+
+// handleSessionCommand runs the /reset and /inspect commands, optionally
+// followed by a language, and reports whether the input was one of them.
+func handleSessionCommand(
+	ctx context.Context,
+	sessions *code.Sessions,
+	input string,
+) bool {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || len(fields) > 2 {
+		return false
+	}
+
+	language := ""
+	if len(fields) == 2 {
+		language = code.NormalizeLanguage(fields[1])
+	}
+
+	switch fields[0] {
+	case "/reset":
+		sessions.Reset(language)
+		fmt.Println("Session reset, the next execution starts from scratch.")
+		return true
+	case "/inspect":
+		found := false
+		for _, l := range sessions.Languages() {
+			if language != "" && l != language {
+				continue
+			}
+			found = true
+
+			session, _ := sessions.Get(l)
+			r := session.Inspect(ctx)
+			fmt.Printf("--- %s session ---\n%s", l, r.Stdout)
+			if r.Stderr != "" {
+				fmt.Print(r.Stderr)
+			}
+		}
+		if !found {
+			fmt.Println("No active session.")
+		}
+		return true
+	default:
+		return false
+	}
+}
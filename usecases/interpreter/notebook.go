@@ -0,0 +1,106 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/notebook"
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+// Notebook converts an interpreter conversation to a notebook: the requests
+// and the explanations become markdown cells, and the code blocks become
+// code cells with the outputs of their execution.
+func Notebook(conversation chat.Conversation) *notebook.Notebook {
+	nb := notebook.New()
+
+	// Code cells waiting for their execution results
+	var pending []int
+	for _, msg := range conversation.GetMessages() {
+		switch msg.Role {
+		case chat.RoleUser:
+			if msg.IsFile {
+				continue
+			}
+			nb.AddMarkdown(quote(msg.Content))
+			pending = nil
+		case chat.RoleAssistant:
+			if results, ok := code.ParseExecutionResults(msg.Content); ok {
+				for i, index := range pending {
+					if i < len(results) {
+						nb.SetOutputs(index, outputs(results[i])...)
+					}
+				}
+				pending = nil
+				continue
+			}
+
+			var resp consoleResponse
+			if err := json.Unmarshal([]byte(msg.Content), &resp); err != nil ||
+				resp.Action == "" {
+				nb.AddMarkdown(msg.Content)
+				continue
+			}
+
+			switch resp.Action {
+			case consoleActionAsk:
+				nb.AddMarkdown(resp.Question)
+			case consoleActionCode:
+				if resp.Explanation != "" {
+					nb.AddMarkdown(resp.Explanation)
+				}
+				pending = nil
+				for _, block := range code.ParseCodeBlocks(resp.markdown()) {
+					pending = append(
+						pending,
+						nb.AddCode(code.NormalizeLanguage(block.Language), block.Code),
+					)
+				}
+			}
+		}
+	}
+
+	return nb
+}
+
+func outputs(r code.ExecutionResult) []notebook.Output {
+	var outputs []notebook.Output
+	if r.Stdout != "" {
+		outputs = append(outputs, notebook.StreamOutput("stdout", r.Stdout))
+	}
+	if r.Stderr != "" {
+		outputs = append(outputs, notebook.StreamOutput("stderr", r.Stderr))
+	}
+	if r.ExitCode != 0 {
+		outputs = append(
+			outputs,
+			notebook.ErrorOutput("ExitCode", fmt.Sprintf("exited with code %d", r.ExitCode)),
+		)
+	}
+	return outputs
+}
+
+// quote renders the request of the user as a Markdown quote.
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n> ")
+}
+
+// exportNotebook saves the conversation as a notebook in dir, the working
+// directory when empty.
+func exportNotebook(
+	conversation chat.Conversation,
+	dir string,
+	logger tools.Logger,
+) {
+	path := filepath.Join(dir, "interpreter-"+conversation.GetID()+".ipynb")
+	if err := Notebook(conversation).WriteFile(path); err != nil {
+		logger.Error("Failed to export notebook: " + err.Error())
+		return
+	}
+
+	fmt.Println("Notebook saved to " + path)
+}
@@ -1,6 +1,11 @@
 package interpreter
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/environment"
+)
 
 const instructionConsoleWindows = `Understand the user's goal and determine whether clarification is needed before generating executable code in a JSON format that is either for PowerShell or Bash.
 
@@ -200,3 +205,37 @@ func getConsoleInstruction(osName string) (string, error) {
 		return "", fmt.Errorf("unsupported OS: %s", osName)
 	}
 }
+
+const instructionSessions = `
+
+# Sessions
+
+Bash and Python code runs in persistent sessions: variables, imports, functions and the working directory are kept between executions. Build on the previous steps instead of repeating them.`
+
+const instructionExplanation = `
+
+# Explanations
+
+For action='code', add an "explanation" key with a short Markdown explanation of what the code does and why, in one or two sentences. It is shown to the user before the code runs.`
+
+// getEnvironmentInstruction describes the machine the code runs on.
+func getEnvironmentInstruction(env *environment.Environment) string {
+	return "\n\n# Environment\n\n" +
+		"The code runs on the following machine. Rely on the installed interpreters, tools and packages, " +
+		"and do not assume others are available.\n\n" +
+		env.Summary()
+}
+
+// getLanguagesInstruction lists the languages the code can be written in,
+// as detected on the machine.
+func getLanguagesInstruction(languages []string) string {
+	if len(languages) == 0 {
+		return ""
+	}
+
+	return "\n\n# Available Languages\n\n" +
+		"The following languages are installed and can be used as 'language': " +
+		strings.Join(languages, ", ") + ".\n" +
+		"'sql' runs against a SQLite database and prints the rows as tables. " +
+		"Prefer the languages listed in the instructions above, unless another one is clearly more suited."
+}
@@ -0,0 +1,110 @@
+package interpreter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nullswan/nomi/internal/chat"
+	"github.com/nullswan/nomi/internal/code"
+	"github.com/nullswan/nomi/internal/tools"
+)
+
+const instructionDescribeSnippet = `You describe code snippets so they can be found and re-run later from a snippet library.
+
+Given the request of the user and the code that fulfilled it, write a short description of what the code does, in one sentence of at most 15 words, starting with a verb, without mentioning the language.
+
+Respond with a JSON object with a single "description" key.
+
+Example:
+{
+  "description": "List the 10 largest files of the current directory"
+}`
+
+// saveSnippets saves the successful blocks to the snippet library, with a
+// description generated from the request of the user.
+func saveSnippets(
+	ctx context.Context,
+	textToJSON tools.TextToJSONBackend,
+	repo code.Repository,
+	logger tools.Logger,
+	request string,
+	results []code.ExecutionResult,
+) {
+	for _, r := range results {
+		block := r.Block
+		block.Language = code.NormalizeLanguage(block.Language)
+		if r.ExitCode != 0 || strings.TrimSpace(block.Code) == "" {
+			continue
+		}
+
+		exists, err := repo.HasCodeBlock(block.Language, block.Code)
+		if err != nil {
+			logger.Error("Failed to look up snippet: " + err.Error())
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		block.Description, err = describeSnippet(ctx, textToJSON, request, block)
+		if err != nil {
+			logger.Debug("Failed to describe snippet: " + err.Error())
+			block.Description = request
+		}
+
+		if err := repo.SaveCodeBlock(block); err != nil {
+			logger.Error("Failed to save snippet: " + err.Error())
+			continue
+		}
+		logger.Debug("Saved snippet: " + block.Description)
+	}
+}
+
+func describeSnippet(
+	ctx context.Context,
+	textToJSON tools.TextToJSONBackend,
+	request string,
+	block code.CodeBlock,
+) (string, error) {
+	resp, err := textToJSON.DoMessages(ctx, []chat.Message{
+		chat.NewMessage(chat.RoleSystem, instructionDescribeSnippet),
+		chat.NewMessage(
+			chat.RoleUser,
+			fmt.Sprintf(
+				"Request: %s\n\nCode (%s):\n%s",
+				request,
+				block.Language,
+				block.Code,
+			),
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error generating completion: %w", err)
+	}
+
+	var description struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(resp), &description); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if strings.TrimSpace(description.Description) == "" {
+		return "", fmt.Errorf("empty description")
+	}
+
+	return strings.TrimSpace(description.Description), nil
+}
+
+// lastUserRequest returns the latest message of the user.
+func lastUserRequest(conversation chat.Conversation) string {
+	messages := conversation.GetMessages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == chat.RoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}